@@ -0,0 +1,436 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/zchee/go-openrpc/internal/jsonschema"
+)
+
+// Bundle returns the self-contained copy of the document s, whose external references are replaced with the
+// references to the Components.
+//
+// The objects referred by the external references are loaded by the Resolver configured by opts, and hoisted into the
+// Components of the kind of the reference under the names derived from their locations, such as "Header" for
+// "schemas/block.json#/definitions/Header". The references in the hoisted objects are bundled in the same way, and the
+// "$id"s of the hoisted schemas are removed as they no longer identify the documents.
+//
+// The names are unique in the Components, so the name which collides with the existing component is prefixed with the
+// base name of the document, and suffixed with the sequence number if it still collides. The objects are hoisted in the
+// order of the references in the document, so the same documents always produce the same bundle and the same JSON encoding.
+//
+// The local references of s are left as is. Bundle returns the ReferenceError located in the bundle if any external
+// reference cannot be resolved.
+func (s *Schema) Bundle(opts ...ResolverOption) (*Schema, error) {
+	bs := new(Schema)
+	if err := cloneJSON(bs, s); err != nil {
+		return nil, err
+	}
+
+	b := &bundler{
+		resolver: NewResolver(s, opts...),
+		schema:   bs,
+		names:    make(map[string]string),
+	}
+	root := b.resolver
+
+	for i, m := range bs.Methods {
+		if m == nil {
+			continue
+		}
+		path := []string{"methods", strconv.Itoa(i)}
+		for j, t := range m.Tags {
+			if t != nil && t.IsReference() {
+				if err := b.reference(root, t.Reference, "tags", appendPath(path, "tags", strconv.Itoa(j))); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for j, p := range m.Params {
+			if err := b.contentDescriptorOrReference(root, p, appendPath(path, "params", strconv.Itoa(j))); err != nil {
+				return nil, err
+			}
+		}
+		if err := b.contentDescriptorOrReference(root, m.Result, appendPath(path, "result")); err != nil {
+			return nil, err
+		}
+		for j, e := range m.Errors {
+			if e != nil && e.IsReference() {
+				if err := b.reference(root, e.Reference, "errors", appendPath(path, "errors", strconv.Itoa(j))); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for j, l := range m.Links {
+			if l != nil && l.IsReference() {
+				if err := b.reference(root, l.Reference, "links", appendPath(path, "links", strconv.Itoa(j))); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for j, e := range m.Examples {
+			examplePath := appendPath(path, "examples", strconv.Itoa(j))
+			var err error
+			switch {
+			case e == nil:
+			case e.IsReference():
+				err = b.reference(root, e.Reference, "examplePairingObjects", examplePath)
+			case e.IsExamplePairing():
+				err = b.examplePairing(root, e.ExamplePairing, examplePath)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if comps := bs.Components; comps != nil {
+		// the hoisted components are bundled when they are hoisted, so only the components of s are bundled here
+		cds, schemas, pairings := sortedMapKeys(comps.ContentDescriptors), sortedMapKeys(comps.Schemas), sortedMapKeys(comps.ExamplePairingObjects)
+		for _, name := range cds {
+			if err := b.contentDescriptor(root, comps.ContentDescriptors[name], []string{"components", "contentDescriptors", name}); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range schemas {
+			if err := b.jsonSchema(root, comps.Schemas[name], []string{"components", "schemas", name}); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range pairings {
+			if err := b.examplePairing(root, comps.ExamplePairingObjects[name], []string{"components", "examplePairingObjects", name}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return bs, nil
+}
+
+// cloneJSON copies src to dst through the JSON encoding.
+func cloneJSON(dst, src interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// bundler bundles the external references into the Components of the schema.
+type bundler struct {
+	resolver *Resolver
+	schema   *Schema
+
+	// names maps the keys of the hoisted objects, which are their kinds and locations, to their names in the Components.
+	names map[string]string
+}
+
+// reference bundles the ref of the kind used at the path in the scope.
+func (b *bundler) reference(scope *Resolver, ref *Reference, kind string, path []string) error {
+	bundled, err := b.bundleRef(scope, ref.Ref, kind)
+	if err != nil {
+		var rerr *ReferenceError
+		if errors.As(err, &rerr) {
+			return err
+		}
+		return &ReferenceError{Ref: ref.Ref, Pointer: formatPointer(path), Err: err}
+	}
+	ref.Ref = bundled
+	return nil
+}
+
+// bundleRef returns the bundled reference of the ref of the kind used in the scope.
+//
+// The reference to the root document is the local reference, and the reference to the other document is the reference
+// to the hoisted object in the Components.
+func (b *bundler) bundleRef(scope *Resolver, ref, kind string) (string, error) {
+	st := b.resolver.state
+	seen := make(map[string]bool)
+	for next := ref; ; {
+		doc, fragment, err := scope.locate(next)
+		if err != nil {
+			return "", chainError(ref, next, err)
+		}
+		if doc == nil {
+			if strings.HasPrefix(next, "#") {
+				return next, nil
+			}
+			return "#" + fragment, nil
+		}
+
+		tokens, err := pointerTokens(fragment)
+		if err != nil {
+			return "", chainError(ref, next, err)
+		}
+		key := kind + " " + doc.uri + "#" + formatPointer(tokens)
+		if name, ok := b.names[key]; ok {
+			return componentRef(kind, name), nil
+		}
+
+		// the Reference Objects are followed except the "$ref" schemas which are hoisted as is
+		if kind != "schemas" {
+			if seen[key] {
+				return "", fmt.Errorf("circular reference through %q", next)
+			}
+			seen[key] = true
+
+			v, err := st.decode(doc, fragment, reflect.TypeOf(Reference{}))
+			if err != nil {
+				return "", chainError(ref, next, err)
+			}
+			if rv := v.(*Reference); rv.Ref != "" {
+				scope, next = &Resolver{state: st, scope: &origin{doc: doc, base: doc.baseOf(tokens)}}, rv.Ref
+				continue
+			}
+		}
+
+		name, err := b.hoist(doc, fragment, tokens, kind, key)
+		if err != nil {
+			return "", chainError(ref, next, err)
+		}
+		return componentRef(kind, name), nil
+	}
+}
+
+// componentRef returns the local reference to the component of the kind.
+func componentRef(kind, name string) string {
+	return "#" + formatPointer([]string{"components", kind, name})
+}
+
+// hoist hoists the object of the kind at the raw fragment in the loaded document doc into the Components, and bundles
+// the references in it. It returns the name of the hoisted object.
+func (b *bundler) hoist(doc *loadedDocument, fragment string, tokens []string, kind, key string) (string, error) {
+	typ, ok := componentTypes[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown kind of components %q", kind)
+	}
+	v, err := b.resolver.state.decode(doc, fragment, typ)
+	if err != nil {
+		return "", err
+	}
+	c := reflect.New(typ)
+	if err := cloneJSON(c.Interface(), v); err != nil {
+		return "", err
+	}
+
+	comps := b.components()
+	m := reflect.ValueOf(comps).Elem().FieldByName(componentFields[kind])
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	name := b.name(m, doc.uri, tokens, c.Interface())
+	// the name is taken before bundling the references in the object, which may refer the object itself
+	m.SetMapIndex(reflect.ValueOf(name), c)
+	b.names[key] = name
+
+	scope := &Resolver{state: b.resolver.state, scope: &origin{doc: doc, base: doc.baseOf(tokens)}}
+	path := []string{"components", kind, name}
+	switch c := c.Interface().(type) {
+	case *ContentDescriptor:
+		err = b.contentDescriptor(scope, c, path)
+	case *JSONSchema:
+		err = b.jsonSchema(scope, c, path)
+	case *ExamplePairing:
+		err = b.examplePairing(scope, c, path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// componentTypes maps the kinds of the Components to the types of the objects.
+var componentTypes = map[string]reflect.Type{
+	"contentDescriptors":    reflect.TypeOf(ContentDescriptor{}),
+	"schemas":               reflect.TypeOf(JSONSchema{}),
+	"examples":              reflect.TypeOf(Example{}),
+	"links":                 reflect.TypeOf(Link{}),
+	"errors":                reflect.TypeOf(Error{}),
+	"examplePairingObjects": reflect.TypeOf(ExamplePairing{}),
+	"tags":                  reflect.TypeOf(Tag{}),
+}
+
+// componentFields maps the kinds of the Components to the names of the fields of the Components.
+var componentFields = map[string]string{
+	"contentDescriptors":    "ContentDescriptors",
+	"schemas":               "Schemas",
+	"examples":              "Examples",
+	"links":                 "Links",
+	"errors":                "Errors",
+	"examplePairingObjects": "ExamplePairingObjects",
+	"tags":                  "Tags",
+}
+
+func (b *bundler) components() *Components {
+	if b.schema.Components == nil {
+		b.schema.Components = new(Components)
+	}
+	return b.schema.Components
+}
+
+// name returns the unique name in the map m of the Components for the object v at the JSON Pointer reference tokens in
+// the document at the uri.
+//
+// The name is the last reference token which is not the array index, the name or the title of v, or the base name of
+// the document, in order of preference.
+func (b *bundler) name(m reflect.Value, uri string, tokens []string, v interface{}) string {
+	docName := uri
+	if u, err := url.Parse(uri); err == nil {
+		docName = u.Path
+	}
+	docName = path.Base(docName)
+	docName = sanitizeComponentName(strings.TrimSuffix(docName, path.Ext(docName)))
+
+	name := ""
+	if n := len(tokens); n > 0 && !isArrayIndex(tokens[n-1]) {
+		name = sanitizeComponentName(tokens[n-1])
+	}
+	if name == "" {
+		switch v := v.(type) {
+		case *ContentDescriptor:
+			name = sanitizeComponentName(v.Name)
+		case *ExamplePairing:
+			name = sanitizeComponentName(v.Name)
+		case *Tag:
+			name = sanitizeComponentName(v.Name)
+		case *JSONSchema:
+			if v.Schema != nil {
+				name = sanitizeComponentName(v.Title)
+			}
+		}
+	}
+	if name == "" {
+		name = docName
+	}
+	if name == "" {
+		name = "component"
+	}
+
+	taken := func(name string) bool {
+		return m.MapIndex(reflect.ValueOf(name)).IsValid()
+	}
+	if !taken(name) {
+		return name
+	}
+	if docName != "" && docName != name {
+		name = docName + "_" + name
+		if !taken(name) {
+			return name
+		}
+	}
+	for i := 2; ; i++ {
+		if n := name + "_" + strconv.Itoa(i); !taken(n) {
+			return n
+		}
+	}
+}
+
+// isArrayIndex reports whether the reference token tok is the array index.
+func isArrayIndex(tok string) bool {
+	return isNumericIdentifier(tok)
+}
+
+// sanitizeComponentName returns s whose runs of the characters not allowed in the names of the Components, which match
+// "^[a-zA-Z0-9\.\-_]+$", are replaced with the underscore.
+func sanitizeComponentName(s string) string {
+	var sb strings.Builder
+	underscore := false
+	for _, r := range s {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '.', r == '-', r == '_':
+			sb.WriteRune(r)
+			underscore = false
+		case !underscore:
+			sb.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.Trim(sb.String(), "_")
+}
+
+func (b *bundler) contentDescriptorOrReference(scope *Resolver, cd *ContentDescriptorOrReference, path []string) error {
+	switch {
+	case cd == nil:
+	case cd.IsReference():
+		return b.reference(scope, cd.Reference, "contentDescriptors", path)
+	case cd.IsOneOf():
+		for i, cd := range cd.OneOf.OneOf {
+			if err := b.contentDescriptorOrReference(scope, cd, appendPath(path, "oneOf", strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case cd.IsContentDescriptor():
+		return b.contentDescriptor(scope, cd.ContentDescriptor, path)
+	}
+	return nil
+}
+
+func (b *bundler) contentDescriptor(scope *Resolver, cd *ContentDescriptor, path []string) error {
+	if cd == nil {
+		return nil
+	}
+	if err := b.jsonSchema(scope, cd.Schema, appendPath(path, "schema")); err != nil {
+		return err
+	}
+	for i, p := range cd.Examples {
+		if err := b.examplePairing(scope, p, appendPath(path, "examples", strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *bundler) examplePairing(scope *Resolver, p *ExamplePairing, path []string) error {
+	if p == nil {
+		return nil
+	}
+	for i, e := range p.Params {
+		if e != nil && e.IsReference() {
+			if err := b.reference(scope, e.Reference, "examples", appendPath(path, "params", strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	}
+	if p.Result != nil && p.Result.IsReference() {
+		return b.reference(scope, p.Result.Reference, "examples", appendPath(path, "result"))
+	}
+	return nil
+}
+
+// jsonSchema bundles the "$ref"s of the JSONSchema js and its nested subschemas in place.
+func (b *bundler) jsonSchema(scope *Resolver, js *JSONSchema, path []string) error {
+	if js == nil || js.Schema == nil {
+		return nil
+	}
+	hoisted := scope.scope.doc != nil
+	s, err := jsonschema.Rewrite(js.Schema, scope.BaseURI(), func(s *jsonschema.Schema, tokens []string, base string) (*jsonschema.Schema, error) {
+		if hoisted {
+			s.ID = ""
+		}
+		if s.Ref == nil {
+			return nil, nil
+		}
+		ref := &Reference{Ref: *s.Ref}
+		scope := &Resolver{state: scope.state, scope: &origin{doc: scope.scope.doc, base: base}}
+		if err := b.reference(scope, ref, "schemas", appendPath(path, tokens...)); err != nil {
+			return nil, err
+		}
+		s.Ref = &ref.Ref
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+	js.Schema = s
+	return nil
+}
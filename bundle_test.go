@@ -0,0 +1,265 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// bundleTestLoader is the multi-file specification bundled by the tests, whose root document is bundleTestDocument.
+var bundleTestLoader = MemoryLoader{
+	"/specs/common.json": []byte(`{
+		"components": {
+			"contentDescriptors": {"block": {"name": "block", "schema": {"$ref": "#/components/schemas/Block"}}},
+			"schemas": {
+				"Block": {"$id": "/specs/block.json", "type": "object", "properties": {"header": {"$ref": "types/header.json#/definitions/Header"}, "parent": {"$ref": "#"}}},
+				"Local": {"$ref": "openrpc.json#/components/schemas/Local"}
+			},
+			"errors": {"alias": {"$ref": "#/components/errors/notFound"}, "notFound": {"code": 404, "message": "not found"}},
+			"tags": {"chain": {"name": "chain"}},
+			"links": {"next": {"name": "next", "method": "get_block"}},
+			"examples": {"height": {"name": "height", "value": 1}}
+		}
+	}`),
+	"/specs/types/header.json": []byte(`{
+		"definitions": {
+			"Header": {"type": "object", "properties": {"hash": {"$ref": "#/definitions/Hash"}, "user": {"$ref": "../users.json#/definitions/User"}}},
+			"Hash": {"type": "string"}
+		}
+	}`),
+	"/specs/users.json":       []byte(`{"definitions": {"User": {"type": "string"}}}`),
+	"/specs/other/users.json": []byte(`{"definitions": {"User": {"type": "integer"}}}`),
+	"/specs/list.json":        []byte(`[{"title": "First Item", "type": "null"}, {"type": "boolean"}, {"name": "by name", "schema": {}}]`),
+}
+
+const bundleTestDocument = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "t", "version": "1"},
+	"methods": [{
+		"name": "get_block",
+		"tags": [{"$ref": "common.json#/components/tags/chain"}],
+		"params": [
+			{"$ref": "common.json#/components/contentDescriptors/block"},
+			{"$ref": "#/components/contentDescriptors/local"}
+		],
+		"result": {"name": "r", "schema": {"$ref": "common.json#/components/schemas/Block"}},
+		"errors": [{"$ref": "common.json#/components/errors/alias"}],
+		"links": [{"$ref": "common.json#/components/links/next"}],
+		"examples": [{"name": "e", "params": [{"$ref": "common.json#/components/examples/height"}], "result": {"name": "r", "value": 1}}]
+	}],
+	"components": {
+		"contentDescriptors": {"local": {"name": "local", "schema": {"$ref": "common.json#/components/schemas/Local"}}},
+		"schemas": {
+			"Local": {"type": "null"},
+			"User": {"type": "boolean"},
+			"users": {"$ref": "users.json#/definitions/User"},
+			"otherUsers": {"$ref": "other/users.json#/definitions/User"}
+		}
+	}
+}`
+
+func TestBundle(t *testing.T) {
+	s := resolveTestSchema(t, bundleTestDocument)
+	before := mustMarshalJSON(t, s)
+
+	bs, err := s.Bundle(WithLoader(bundleTestLoader), WithBaseURI("/specs/openrpc.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after := mustMarshalJSON(t, s); after != before {
+		t.Fatalf("Bundle changed the document:\n%s\nwant\n%s", after, before)
+	}
+
+	want := resolveTestSchema(t, `{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"methods": [{
+			"name": "get_block",
+			"tags": [{"$ref": "#/components/tags/chain"}],
+			"params": [
+				{"$ref": "#/components/contentDescriptors/block"},
+				{"$ref": "#/components/contentDescriptors/local"}
+			],
+			"result": {"name": "r", "schema": {"$ref": "#/components/schemas/Block"}},
+			"errors": [{"$ref": "#/components/errors/notFound"}],
+			"links": [{"$ref": "#/components/links/next"}],
+			"examples": [{"name": "e", "params": [{"$ref": "#/components/examples/height"}], "result": {"name": "r", "value": 1}}]
+		}],
+		"components": {
+			"contentDescriptors": {
+				"local": {"name": "local", "schema": {"$ref": "#/components/schemas/common_Local"}},
+				"block": {"name": "block", "schema": {"$ref": "#/components/schemas/Block"}}
+			},
+			"schemas": {
+				"Local": {"type": "null"},
+				"User": {"type": "boolean"},
+				"users": {"$ref": "#/components/schemas/users_User"},
+				"otherUsers": {"$ref": "#/components/schemas/users_User_2"},
+				"Block": {"type": "object", "properties": {"header": {"$ref": "#/components/schemas/Header"}, "parent": {"$ref": "#/components/schemas/Block"}}},
+				"Header": {"type": "object", "properties": {"hash": {"$ref": "#/components/schemas/Hash"}, "user": {"$ref": "#/components/schemas/users_User"}}},
+				"Hash": {"type": "string"},
+				"users_User": {"type": "string"},
+				"users_User_2": {"type": "integer"},
+				"common_Local": {"$ref": "#/components/schemas/Local"}
+			},
+			"errors": {"notFound": {"code": 404, "message": "not found"}},
+			"tags": {"chain": {"name": "chain"}},
+			"links": {"next": {"name": "next", "method": "get_block"}},
+			"examples": {"height": {"name": "height", "value": 1}}
+		}
+	}`)
+	if got, w := mustMarshalJSON(t, bs), mustMarshalJSON(t, want); got != w {
+		t.Fatalf("Bundle =\n%s\nwant\n%s", got, w)
+	}
+	if err := bs.CheckReferences(); err != nil {
+		t.Fatalf("the bundle is not self-contained: %v", err)
+	}
+}
+
+func TestBundleReproducible(t *testing.T) {
+	var first string
+	for i := 0; i < 20; i++ {
+		bs, err := resolveTestSchema(t, bundleTestDocument).Bundle(WithLoader(bundleTestLoader), WithBaseURI("/specs/openrpc.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(bs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = string(data)
+			continue
+		}
+		if string(data) != first {
+			t.Fatalf("the bundle #%d differs:\n%s\nwant\n%s", i, data, first)
+		}
+	}
+}
+
+func TestBundleNames(t *testing.T) {
+	tests := map[string]struct {
+		ref  string
+		kind string
+		// first is the reference bundled before ref, if not empty
+		first string
+		want  string
+	}{
+		"token":          {ref: "types/header.json#/definitions/Hash", want: "#/components/schemas/Hash"},
+		"title":          {ref: "list.json#/0", want: "#/components/schemas/First_Item"},
+		"document":       {ref: "list.json#/1", want: "#/components/schemas/list"},
+		"contentName":    {ref: "list.json#/2", kind: "contentDescriptors", want: "#/components/contentDescriptors/by_name"},
+		"collision":      {ref: "users.json#/definitions/User", want: "#/components/schemas/users_User"},
+		"sequence":       {ref: "other/users.json#/definitions/User", first: "users.json#/definitions/User", want: "#/components/schemas/users_User_2"},
+		"wholeDocument":  {ref: "users.json", want: "#/components/schemas/users"},
+		"local":          {ref: "#/components/schemas/User", want: "#/components/schemas/User"},
+		"rootByFilename": {ref: "openrpc.json#/components/schemas/User", want: "#/components/schemas/User"},
+		"refSchema":      {ref: "common.json#/components/schemas/Local", want: "#/components/schemas/Local"},
+		"followedAlias":  {ref: "common.json#/components/errors/alias", kind: "errors", want: "#/components/errors/notFound"},
+		"hoisted":        {ref: "./users.json#/definitions/User", first: "users.json#/definitions/User", want: "#/components/schemas/users_User"},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			kind := tt.kind
+			if kind == "" {
+				kind = "schemas"
+			}
+			s := resolveTestSchema(t, `{
+				"openrpc": "1.2.6",
+				"info": {"title": "t", "version": "1"},
+				"methods": [],
+				"components": {"schemas": {"User": {}, "users_User_0": {}}}
+			}`)
+			b := &bundler{
+				resolver: NewResolver(s, WithLoader(bundleTestLoader), WithBaseURI("/specs/openrpc.json")),
+				schema:   s,
+				names:    make(map[string]string),
+			}
+			if tt.first != "" {
+				if _, err := b.bundleRef(b.resolver, tt.first, kind); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := b.bundleRef(b.resolver, tt.ref, kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("bundleRef(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeComponentName(t *testing.T) {
+	tests := map[string]string{
+		"Header":        "Header",
+		"a.b-c_d":       "a.b-c_d",
+		"First Item":    "First_Item",
+		"a / b  ~ c":    "a_b_c",
+		" leading/":     "leading",
+		"日本":            "",
+		"x日本y":          "x_y",
+		"already_under": "already_under",
+	}
+	for s, want := range tests {
+		if got := sanitizeComponentName(s); got != want {
+			t.Errorf("sanitizeComponentName(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestBundleError(t *testing.T) {
+	loader := MemoryLoader{
+		"/specs/loop.json": []byte(`{"components": {"errors": {"a": {"$ref": "#/components/errors/b"}, "b": {"$ref": "#/components/errors/a"}}}}`),
+		"/specs/bad.json":  []byte(`{"definitions": {"S": {"$ref": "missing.json#/definitions/S"}}}`),
+	}
+	tests := map[string]struct {
+		method  string
+		pointer string
+		msg     string
+	}{
+		"missingDocument": {
+			method:  `{"name": "m", "params": [{"$ref": "missing.json#/components/contentDescriptors/p"}]}`,
+			pointer: "/methods/0/params/0",
+			msg:     "missing.json",
+		},
+		"missingTarget": {
+			method:  `{"name": "m", "params": [], "errors": [{"$ref": "loop.json#/components/errors/none"}]}`,
+			pointer: "/methods/0/errors/0",
+		},
+		"circular": {
+			method:  `{"name": "m", "params": [], "errors": [{"$ref": "loop.json#/components/errors/a"}]}`,
+			pointer: "/methods/0/errors/0",
+			msg:     "circular reference",
+		},
+		"nestedSchema": {
+			method:  `{"name": "m", "params": [{"name": "p", "schema": {"items": {"$ref": "bad.json#/definitions/S"}}}]}`,
+			pointer: "/components/schemas/S",
+			msg:     "missing.json",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			s := resolveTestSchema(t, `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": [`+tt.method+`]}`)
+			_, err := s.Bundle(WithLoader(loader), WithBaseURI("/specs/openrpc.json"))
+			var rerr *ReferenceError
+			if !errors.As(err, &rerr) {
+				t.Fatalf("Bundle = %v, want *ReferenceError", err)
+			}
+			if rerr.Pointer != tt.pointer {
+				t.Fatalf("ReferenceError at %q, want %q (%v)", rerr.Pointer, tt.pointer, err)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("Error() = %q does not contain %q", err, tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// MarshalCanonical returns the canonical JSON encoding of v in the style of the JSON Canonicalization Scheme (RFC 8785).
+//
+// The object members are sorted by the UTF-16 code units of the names, the numbers are normalized to the shortest
+// ECMAScript representation of the IEEE 754 double, and the insignificant whitespace is removed.
+// So the same document always produces the same bytes regardless of the map iteration order.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	n, err := marshalNode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendCanonical(nil, n)
+}
+
+// Digest returns the stable content hash of the document, in the form of "sha256:" followed by the hex encoded SHA-256 of the canonical JSON encoding.
+func (s *Schema) Digest() (string, error) {
+	data, err := MarshalCanonical(s)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// appendCanonical appends the canonical JSON text of n to buf.
+func appendCanonical(buf []byte, n *node) ([]byte, error) {
+	switch n.kind {
+	case nullNode:
+		return append(buf, "null"...), nil
+
+	case boolNode:
+		return append(buf, n.value...), nil
+
+	case numberNode:
+		f, err := strconv.ParseFloat(n.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("openrpc: number %s cannot be represented as IEEE 754 double: %w", n.value, err)
+		}
+		return appendES6Number(buf, f)
+
+	case stringNode:
+		return appendCanonicalString(buf, n.value), nil
+
+	case arrayNode:
+		buf = append(buf, '[')
+		for i, child := range n.children {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			var err error
+			if buf, err = appendCanonical(buf, child); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, ']'), nil
+
+	default:
+		type member struct {
+			key   string
+			units []uint16
+			value *node
+		}
+		members := make([]member, len(n.keys))
+		for i, key := range n.keys {
+			members[i] = member{key: key, units: utf16.Encode([]rune(key)), value: n.children[i]}
+		}
+		sort.SliceStable(members, func(i, j int) bool {
+			return lessUTF16(members[i].units, members[j].units)
+		})
+
+		buf = append(buf, '{')
+		for i, m := range members {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendCanonicalString(buf, m.key)
+			buf = append(buf, ':')
+			var err error
+			if buf, err = appendCanonical(buf, m.value); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, '}'), nil
+	}
+}
+
+// lessUTF16 reports whether the UTF-16 code units a sorts before b.
+func lessUTF16(a, b []uint16) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// appendES6Number appends the ECMAScript Number.prototype.toString representation of f to buf.
+func appendES6Number(buf []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("openrpc: number %v cannot be represented in JSON", f)
+	}
+	if f == 0 {
+		return append(buf, '0'), nil // also -0
+	}
+
+	format := byte('e')
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		format = 'f'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// ECMAScript does not pad the exponent, "1e-07" is "1e-7"
+		if n := len(s); n >= 4 && s[n-4] == 'e' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+
+	return append(buf, s...), nil
+}
+
+// appendCanonicalString appends the JSON string literal of s to buf which escapes only the characters required by RFC 8785.
+func appendCanonicalString(buf []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+
+	buf = append(buf, '"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			if c < 0x20 {
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+				continue
+			}
+			buf = append(buf, c)
+		}
+	}
+
+	return append(buf, '"')
+}
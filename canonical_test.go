@@ -0,0 +1,128 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	tests := map[string]struct {
+		json, want string
+	}{
+		// the examples of RFC 8785
+		"sorting": {
+			json: `{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh", "1": "One", "\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control", "\u00f6": "Latin Small Letter O With Diaeresis"}`,
+			want: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		"strings": {
+			json: `["\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "\u0007\b\f\t"]`,
+			want: "[\"\u20ac$\\u000f\\nA'B\\\"\\\\\\\\\\\"/\",\"\\u0007\\b\\f\\t\"]",
+		},
+		"literals": {
+			json: `{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001], "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "literals": [null, true, false]}`,
+			want: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"` + "\u20ac" + `$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		"nested": {
+			json: "{ \"b\" : [ {\"d\": 1, \"c\": 2} ],\n \"a\" : {} }",
+			want: `{"a":{},"b":[{"c":2,"d":1}]}`,
+		},
+	}
+	for name, tt := range tests {
+		got, err := MarshalCanonical(json.RawMessage(tt.json))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: MarshalCanonical = %s, want %s", name, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalNumber(t *testing.T) {
+	tests := map[float64]string{
+		0:                      "0",
+		math.Copysign(0, -1):   "0",
+		1:                      "1",
+		-1.5:                   "-1.5",
+		1e21:                   "1e+21",
+		1e20:                   "100000000000000000000",
+		1e-6:                   "0.000001",
+		1e-7:                   "1e-7",
+		5e-324:                 "5e-324",
+		math.MaxFloat64:        "1.7976931348623157e+308",
+		-math.MaxFloat64:       "-1.7976931348623157e+308",
+		9007199254740992:       "9007199254740992",
+		295147905179352830000:  "295147905179352830000",
+		123456789012345680000:  "123456789012345680000",
+		0.30000000000000004:    "0.30000000000000004",
+		1.2345e-100:            "1.2345e-100",
+		333333333.3333333:      "333333333.3333333",
+		-0.0000033333333333333: "-0.0000033333333333333",
+	}
+	for f, want := range tests {
+		got, err := appendES6Number(nil, f)
+		if err != nil || string(got) != want {
+			t.Errorf("appendES6Number(%v) = %s, %v, want %s", f, got, err, want)
+		}
+	}
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := appendES6Number(nil, f); err == nil {
+			t.Errorf("appendES6Number(%v) succeeded", f)
+		}
+	}
+	if _, err := MarshalCanonical(json.RawMessage(`[1e400]`)); err == nil || !strings.Contains(err.Error(), "IEEE 754") {
+		t.Errorf("MarshalCanonical of the out of range number = %v", err)
+	}
+	if _, err := MarshalCanonical(math.NaN()); err == nil {
+		t.Error("MarshalCanonical of NaN succeeded")
+	}
+}
+
+func TestDigest(t *testing.T) {
+	const doc = `{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"servers": [{"url": "https://{a}.example.com/{b}", "variables": {"a": {"default": "x"}, "b": {"default": "y"}}}],
+		"methods": [{"name": "m", "params": [], "result": {"name": "r", "schema": {"properties": {"p": {}, "q": {}, "r": {}}}}}],
+		"components": {"schemas": {"a": {}, "b": {}, "c": {}, "d": {}}, "errors": {"e": {"code": 1, "message": "x"}, "f": {"code": 2, "message": "y"}}}
+	}`
+	s := resolveTestSchema(t, doc)
+
+	digest, err := s.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^sha256:[0-9a-f]{64}$`).MatchString(digest) {
+		t.Fatalf("Digest() = %q", digest)
+	}
+
+	// the map iteration order does not change the digest
+	for i := 0; i < 20; i++ {
+		if d, err := resolveTestSchema(t, doc).Digest(); err != nil || d != digest {
+			t.Fatalf("Digest() = %q, %v, want %q", d, err, digest)
+		}
+	}
+
+	// the insignificant whitespace, and the member order do not change the digest
+	reordered := resolveTestSchema(t, `{"methods": [{"result": {"schema": {"properties": {"r": {}, "q": {}, "p": {}}}, "name": "r"}, "params": [], "name": "m"}],
+		"components": {"errors": {"f": {"message": "y", "code": 2}, "e": {"code":   1, "message": "x"}}, "schemas": {"d": {}, "c": {}, "b": {}, "a": {}}},
+		"servers": [{"variables": {"b": {"default": "y"}, "a": {"default": "x"}}, "url": "https://{a}.example.com/{b}"}],
+		"info": {"version": "1", "title": "t"}, "openrpc": "1.2.6"}`)
+	if d, err := reordered.Digest(); err != nil || d != digest {
+		t.Fatalf("Digest() of the reordered document = %q, %v, want %q", d, err, digest)
+	}
+
+	s.Info.Title = "changed"
+	if d, err := s.Digest(); err != nil || d == digest {
+		t.Fatalf("Digest() of the changed document = %q, %v", d, err)
+	}
+}
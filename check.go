@@ -0,0 +1,245 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The rules of the semantic checks reported as ValidationError.Rule.
+const (
+	// RuleUniqueMethodName is the rule that the method names MUST be unique within the methods array.
+	RuleUniqueMethodName = "unique-method-name"
+
+	// RuleUniqueParamName is the rule that the params of the method MUST be unique by the name.
+	RuleUniqueParamName = "unique-param-name"
+
+	// RuleRequiredParamOrder is the rule that all the optional params MUST be positioned after all the required params.
+	RuleRequiredParamOrder = "required-param-order"
+
+	// RuleUniqueErrorCode is the rule that the errors of the method MUST have the unique error codes.
+	RuleUniqueErrorCode = "unique-error-code"
+
+	// RuleLinkMethod is the rule that the Link.Method MUST resolve to the method of the document.
+	RuleLinkMethod = "link-method"
+
+	// RuleServerVariableDefault is the rule that the ServerVariables.Default MUST be a member of the Enum if the Enum is set.
+	RuleServerVariableDefault = "server-variable-default"
+)
+
+// Check checks the semantic rules of the document which the meta-schema cannot express, and returns every violation
+// as ValidationErrors, or nil if the document has no violations.
+//
+// The references to the Components of the document are followed. The references which cannot be resolved are not checked.
+func (s *Schema) Check() error {
+	c := &checker{schema: s}
+	c.check()
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+// checker checks the semantic rules of the document.
+type checker struct {
+	schema *Schema
+	errs   ValidationErrors
+
+	// validators caches the compiled schemas of the content descriptors.
+	validators map[*JSONSchema]*SchemaValidator
+}
+
+func (c *checker) report(rule string, path []string, format string, args ...interface{}) {
+	c.errs = append(c.errs, &ValidationError{
+		Pointer: formatPointer(path),
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) check() {
+	methods := make(map[string]int, len(c.schema.Methods))
+	for i, m := range c.schema.Methods {
+		if m == nil {
+			continue
+		}
+		path := []string{"methods", strconv.Itoa(i)}
+		if j, ok := methods[m.Name]; ok {
+			c.report(RuleUniqueMethodName, appendPath(path, "name"), "duplicate method name %q, already used by /methods/%d", m.Name, j)
+		} else {
+			methods[m.Name] = i
+		}
+		c.checkMethod(m, path)
+	}
+
+	c.checkServers(c.schema.Servers, []string{"servers"})
+
+	if comps := c.schema.Components; comps != nil {
+		names := make([]string, 0, len(comps.Links))
+		for name := range comps.Links {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			c.checkLink(comps.Links[name], []string{"components", "links", name})
+		}
+	}
+}
+
+func (c *checker) checkMethod(m *Method, path []string) {
+	params := make(map[string]int, len(m.Params))
+	optional := -1
+	for i, p := range m.Params {
+		cd := c.contentDescriptor(p)
+		if cd == nil {
+			continue
+		}
+		paramPath := appendPath(path, "params", strconv.Itoa(i))
+
+		if j, ok := params[cd.Name]; ok {
+			c.report(RuleUniqueParamName, appendPath(paramPath, "name"), "duplicate param name %q, already used by %s", cd.Name, formatPointer(appendPath(path, "params", strconv.Itoa(j))))
+		} else {
+			params[cd.Name] = i
+		}
+
+		switch {
+		case !cd.Required && optional < 0:
+			optional = i
+		case cd.Required && optional >= 0:
+			c.report(RuleRequiredParamOrder, paramPath, "required param %q must be positioned before the optional param %q", cd.Name, c.contentDescriptor(m.Params[optional]).Name)
+		}
+	}
+
+	codes := make(map[ErrorCode]int, len(m.Errors))
+	for i, e := range m.Errors {
+		rpcErr := c.error(e)
+		if rpcErr == nil {
+			continue
+		}
+		if j, ok := codes[rpcErr.Code]; ok {
+			c.report(RuleUniqueErrorCode, appendPath(path, "errors", strconv.Itoa(i), "code"), "duplicate error code %d, already used by %s", rpcErr.Code, formatPointer(appendPath(path, "errors", strconv.Itoa(j))))
+		} else {
+			codes[rpcErr.Code] = i
+		}
+	}
+
+	for i, l := range m.Links {
+		if l == nil || !l.IsLink() {
+			continue // the referenced links are checked in the Components
+		}
+		c.checkLink(l.Link, appendPath(path, "links", strconv.Itoa(i)))
+	}
+
+	c.checkServers(m.Servers, appendPath(path, "servers"))
+}
+
+func (c *checker) checkLink(l *Link, path []string) {
+	if l == nil {
+		return
+	}
+	if l.Method != "" && c.schema.method(l.Method) == nil {
+		c.report(RuleLinkMethod, appendPath(path, "method"), "link method %q does not resolve to a method", l.Method)
+	}
+	if l.Server != nil {
+		c.checkServer(l.Server, appendPath(path, "server"))
+	}
+}
+
+func (c *checker) checkServers(servers []*Server, path []string) {
+	for i, srv := range servers {
+		c.checkServer(srv, appendPath(path, strconv.Itoa(i)))
+	}
+}
+
+func (c *checker) checkServer(srv *Server, path []string) {
+	if srv == nil {
+		return
+	}
+
+	names := make([]string, 0, len(srv.Variables))
+	for name := range srv.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v := srv.Variables[name]
+		if v == nil || len(v.Enum) == 0 {
+			continue
+		}
+		found := false
+		for _, e := range v.Enum {
+			if e == v.Default {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.report(RuleServerVariableDefault, appendPath(path, "variables", name, "default"), "default value %q is not a member of the enum %q", v.Default, v.Enum)
+		}
+	}
+}
+
+// contentDescriptor returns the content descriptor of cd which follows the reference to the Components, or nil if it cannot be resolved.
+func (c *checker) contentDescriptor(cd *ContentDescriptorOrReference) *ContentDescriptor {
+	switch {
+	case cd == nil:
+		return nil
+	case cd.IsContentDescriptor():
+		return cd.ContentDescriptor
+	case cd.IsReference() && c.schema.Components != nil:
+		if name, ok := componentName(cd.Reference.Ref, "contentDescriptors"); ok {
+			return c.schema.Components.ContentDescriptors[name]
+		}
+	}
+	return nil
+}
+
+// error returns the error of e which follows the reference to the Components, or nil if it cannot be resolved.
+func (c *checker) error(e *ErrorOrReference) *Error {
+	switch {
+	case e == nil:
+		return nil
+	case e.IsError():
+		return e.Error
+	case e.IsReference() && c.schema.Components != nil:
+		if name, ok := componentName(e.Reference.Ref, "errors"); ok {
+			return c.schema.Components.Errors[name]
+		}
+	}
+	return nil
+}
+
+// method returns the method whose name is name, or nil if the document does not have it.
+func (s *Schema) method(name string) *Method {
+	for _, m := range s.Methods {
+		if m != nil && m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// componentName returns the name of the component referred by the local reference "#/components/<kind>/<name>".
+//
+// The name is percent-decoded and unescaped from the JSON Pointer reference token, as Resolver does.
+func componentName(ref, kind string) (string, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return "", false
+	}
+	name, rest, err := parseComponentPointer(ref[1:], kind)
+	if err != nil || rest != "" {
+		return "", false
+	}
+	return name, true
+}
+
+// appendPath returns the new JSON Pointer reference tokens of path followed by tokens.
+func appendPath(path []string, tokens ...string) []string {
+	return append(path[:len(path):len(path)], tokens...)
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	type violation struct {
+		pointer, rule string
+	}
+	tests := map[string]struct {
+		methods    string
+		servers    string
+		components string
+		want       []violation
+	}{
+		"valid": {
+			methods: `[{"name": "a", "params": [{"name": "x", "required": true, "schema": {}}, {"name": "y", "schema": {}}], "result": {"name": "r", "schema": {}}}]`,
+		},
+		"duplicateMethod": {
+			methods: `[{"name": "a", "params": [], "result": {"name": "r", "schema": {}}}, {"name": "a", "params": [], "result": {"name": "r", "schema": {}}}]`,
+			want:    []violation{{"/methods/1/name", RuleUniqueMethodName}},
+		},
+		"duplicateParam": {
+			methods:    `[{"name": "a", "params": [{"name": "x", "schema": {}}, {"$ref": "#/components/contentDescriptors/x"}], "result": {"name": "r", "schema": {}}}]`,
+			components: `{"contentDescriptors": {"x": {"name": "x", "schema": {}}}}`,
+			want:       []violation{{"/methods/0/params/1/name", RuleUniqueParamName}},
+		},
+		"requiredAfterOptional": {
+			methods: `[{"name": "a", "params": [{"name": "x", "schema": {}}, {"name": "y", "required": true, "schema": {}}], "result": {"name": "r", "schema": {}}}]`,
+			want:    []violation{{"/methods/0/params/1", RuleRequiredParamOrder}},
+		},
+		"duplicateErrorCode": {
+			methods:    `[{"name": "a", "params": [], "result": {"name": "r", "schema": {}}, "errors": [{"code": 1, "message": "x"}, {"$ref": "#/components/errors/e"}]}]`,
+			components: `{"errors": {"e": {"code": 1, "message": "y"}}}`,
+			want:       []violation{{"/methods/0/errors/1/code", RuleUniqueErrorCode}},
+		},
+		"linkMethod": {
+			methods:    `[{"name": "a", "params": [], "result": {"name": "r", "schema": {}}, "links": [{"name": "l", "method": "b"}, {"$ref": "#/components/links/l"}]}]`,
+			components: `{"links": {"l": {"name": "l", "method": "c"}, "m": {"name": "m", "method": "a"}}}`,
+			want:       []violation{{"/methods/0/links/0/method", RuleLinkMethod}, {"/components/links/l/method", RuleLinkMethod}},
+		},
+		"serverVariableDefault": {
+			methods: `[]`,
+			servers: `[{"url": "https://{h}:{p}", "variables": {"p": {"default": "80", "enum": ["443"]}, "h": {"default": "x"}}}]`,
+			want:    []violation{{"/servers/0/variables/p/default", RuleServerVariableDefault}},
+		},
+		"escapedReference": {
+			methods:    `[{"name": "a", "params": [{"name": "x/y", "schema": {}}, {"$ref": "#/components/contentDescriptors/x~1y%20z"}], "result": {"name": "r", "schema": {}}}]`,
+			components: `{"contentDescriptors": {"x/y z": {"name": "x/y", "schema": {}}}}`,
+			want:       []violation{{"/methods/0/params/1/name", RuleUniqueParamName}},
+		},
+		"unresolvedReference": {
+			methods:    `[{"name": "a", "params": [{"name": "x", "schema": {}}, {"$ref": "#/components/contentDescriptors/none"}], "result": {"name": "r", "schema": {}}}]`,
+			components: `{}`,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			doc := `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": ` + tt.methods
+			if tt.servers != "" {
+				doc += `, "servers": ` + tt.servers
+			}
+			if tt.components != "" {
+				doc += `, "components": ` + tt.components
+			}
+			doc += `}`
+			s := new(Schema)
+			if err := json.Unmarshal([]byte(doc), s); err != nil {
+				t.Fatal(err)
+			}
+
+			err := s.Check()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Check() = %v", err)
+				}
+				return
+			}
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("Check() = %v, want ValidationErrors", err)
+			}
+			got := make([]violation, len(verrs))
+			for i, verr := range verrs {
+				got[i] = violation{verr.Pointer, verr.Rule}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComponentName(t *testing.T) {
+	tests := map[string]struct {
+		ref  string
+		name string
+		ok   bool
+	}{
+		"plain":          {ref: "#/components/errors/e", name: "e", ok: true},
+		"escaped":        {ref: "#/components/errors/a~1b~0c", name: "a/b~c", ok: true},
+		"percentEncoded": {ref: "#/components/errors/a%20b%7E1", name: "a b/", ok: true},
+		"otherKind":      {ref: "#/components/schemas/e"},
+		"inComponent":    {ref: "#/components/errors/e/code"},
+		"external":       {ref: "other.json#/components/errors/e"},
+		"invalidEscape":  {ref: "#/components/errors/%zz"},
+	}
+	for name, tt := range tests {
+		gotName, ok := componentName(tt.ref, "errors")
+		if gotName != tt.name || ok != tt.ok {
+			t.Errorf("%s: componentName(%q) = %q, %t, want %q, %t", name, tt.ref, gotName, ok, tt.name, tt.ok)
+		}
+	}
+}
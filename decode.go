@@ -0,0 +1,453 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DecodeError represents a failure of decoding the OpenRPC document with the location of the offending node.
+type DecodeError struct {
+	// Pointer is the JSON Pointer (RFC 6901) of the offending node, for example, "/methods/0/params/1".
+	Pointer string
+
+	// Line and Column are the 1-based position of the offending node. Zero if unknown.
+	Line, Column int
+
+	// Offset is the byte offset of the offending node in the JSON text, or -1 if unknown.
+	Offset int64
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *DecodeError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("openrpc: ")
+	if e.Pointer != "" {
+		sb.WriteString(e.Pointer)
+		sb.WriteString(": ")
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, "line %d, column %d: ", e.Line, e.Column)
+	}
+	sb.WriteString(strings.TrimPrefix(e.Err.Error(), "openrpc: "))
+
+	return sb.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// extensible is implemented by the OpenRPC objects which allow the specification extensions.
+type extensible interface {
+	// extensions returns the pointer to the Extensions field.
+	extensions() *[]*Extension
+}
+
+// union is implemented by the types which hold one of the alternative objects.
+type union interface {
+	// variant resets the union and returns the pointer to the alternative object to decode into.
+	variant(isRef, isOneOf bool) interface{}
+}
+
+var (
+	extensibleType      = reflect.TypeOf((*extensible)(nil)).Elem()
+	unionType           = reflect.TypeOf((*union)(nil)).Elem()
+	unmarshalerType     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decoder decodes the node tree into the OpenRPC objects.
+type decoder struct {
+	// src is the JSON text of the node tree for computing the position of the offending node.
+	src []byte
+
+	// strict rejects the unknown non-"x-" fields.
+	strict bool
+
+	// path holds the reference tokens of the current node.
+	path []string
+}
+
+// unmarshalJSON decodes the JSON text data into v with the default decoder.
+func unmarshalJSON(data []byte, v interface{}) error {
+	n, err := parseNode(data)
+	if err != nil {
+		return err
+	}
+
+	d := &decoder{src: data}
+	return d.decode(n, reflect.ValueOf(v))
+}
+
+// errorf returns the *DecodeError located at the n.
+func (d *decoder) errorf(n *node, format string, args ...interface{}) error {
+	return d.wrap(n, fmt.Errorf(format, args...))
+}
+
+// wrap returns the err wrapped by *DecodeError located at the n.
+func (d *decoder) wrap(n *node, err error) error {
+	var derr *DecodeError
+	if errors.As(err, &derr) {
+		// the nested error is located relative to the n
+		nested := *derr
+		nested.Pointer = formatPointer(d.path) + derr.Pointer
+		if n.raw != nil && d.src != nil && derr.Offset >= 0 {
+			nested.Offset += int64(n.offset)
+			nested.Line, nested.Column = lineColumn(d.src, int(nested.Offset))
+		} else {
+			nested.Line, nested.Column, nested.Offset = n.line, n.column, -1
+		}
+		return &nested
+	}
+
+	derr = &DecodeError{
+		Pointer: formatPointer(d.path),
+		Line:    n.line,
+		Column:  n.column,
+		Offset:  -1,
+		Err:     err,
+	}
+	if n.raw != nil && d.src != nil {
+		derr.Offset = int64(n.offset)
+		derr.Line, derr.Column = lineColumn(d.src, n.offset)
+	}
+
+	return derr
+}
+
+// decode decodes the n into the v.
+func (d *decoder) decode(n *node, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if n.kind == nullNode && v.CanSet() {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			if !v.CanSet() {
+				return d.errorf(n, "cannot decode into nil %s", v.Type())
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().Elem().Kind() == reflect.Ptr {
+			return d.decode(n, v.Elem())
+		}
+	} else if v.CanAddr() {
+		v = v.Addr()
+	}
+
+	if v.Kind() == reflect.Ptr {
+		switch t := v.Type(); {
+		case t.Implements(unionType):
+			return d.decodeUnion(n, v.Interface().(union))
+		case t.Implements(extensibleType):
+			return d.decodeStruct(n, v.Elem(), v.Interface().(extensible).extensions())
+		case t.Implements(unmarshalerType):
+			if err := v.Interface().(json.Unmarshaler).UnmarshalJSON(n.JSON()); err != nil {
+				return d.wrap(n, err)
+			}
+			return nil
+		case t.Implements(textUnmarshalerType):
+			if n.kind != stringNode {
+				return d.errorf(n, "cannot decode %s into %s", n.kind, t.Elem())
+			}
+			if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(n.value)); err != nil {
+				return d.wrap(n, err)
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if n.kind == nullNode {
+		// null is no-op like encoding/json except for the interface, map and slice
+		switch v.Kind() {
+		case reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return d.decodeStruct(n, v, nil)
+	case reflect.Slice:
+		return d.decodeSlice(n, v)
+	case reflect.Map:
+		return d.decodeMap(n, v)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return d.errorf(n, "cannot decode %s into %s", n.kind, v.Type())
+		}
+		var x interface{}
+		if err := json.Unmarshal(n.JSON(), &x); err != nil {
+			return d.wrap(n, err)
+		}
+		v.Set(reflect.ValueOf(&x).Elem())
+		return nil
+	default:
+		return d.decodeScalar(n, v)
+	}
+}
+
+// decodeScalar decodes the scalar n into the v.
+func (d *decoder) decodeScalar(n *node, v reflect.Value) error {
+	mismatch := func() error {
+		return d.errorf(n, "cannot decode %s into %s", n.kind, v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if n.kind != stringNode {
+			return mismatch()
+		}
+		v.SetString(n.value)
+
+	case reflect.Bool:
+		if n.kind != boolNode {
+			return mismatch()
+		}
+		v.SetBool(n.value == "true")
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n.kind != numberNode {
+			return mismatch()
+		}
+		i, err := strconv.ParseInt(n.value, 10, v.Type().Bits())
+		if err != nil {
+			return d.errorf(n, "cannot decode number %s into %s", n.value, v.Type())
+		}
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n.kind != numberNode {
+			return mismatch()
+		}
+		u, err := strconv.ParseUint(n.value, 10, v.Type().Bits())
+		if err != nil {
+			return d.errorf(n, "cannot decode number %s into %s", n.value, v.Type())
+		}
+		v.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		if n.kind != numberNode {
+			return mismatch()
+		}
+		f, err := strconv.ParseFloat(n.value, v.Type().Bits())
+		if err != nil {
+			return d.errorf(n, "cannot decode number %s into %s", n.value, v.Type())
+		}
+		v.SetFloat(f)
+
+	default:
+		return d.errorf(n, "cannot decode into unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// decodeSlice decodes the array n into the slice v.
+func (d *decoder) decodeSlice(n *node, v reflect.Value) error {
+	if n.kind != arrayNode {
+		return d.errorf(n, "cannot decode %s into %s", n.kind, v.Type())
+	}
+
+	s := reflect.MakeSlice(v.Type(), len(n.children), len(n.children))
+	for i, child := range n.children {
+		d.path = append(d.path, strconv.Itoa(i))
+		if err := d.decode(child, s.Index(i)); err != nil {
+			return err
+		}
+		d.path = d.path[:len(d.path)-1]
+	}
+	v.Set(s)
+
+	return nil
+}
+
+// decodeMap decodes the object n into the string keyed map v.
+func (d *decoder) decodeMap(n *node, v reflect.Value) error {
+	t := v.Type()
+	if n.kind != objectNode {
+		return d.errorf(n, "cannot decode %s into %s", n.kind, t)
+	}
+	if t.Key().Kind() != reflect.String {
+		return d.errorf(n, "cannot decode object into %s with non-string keys", t)
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(n.keys)))
+	}
+	for i, key := range n.keys {
+		d.path = append(d.path, key)
+		elem := reflect.New(t.Elem()).Elem()
+		if err := d.decode(n.children[i], elem); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		d.path = d.path[:len(d.path)-1]
+	}
+
+	return nil
+}
+
+// decodeStruct decodes the object n into the struct v, and collects the "x-" patterned fields into exts if not nil.
+func (d *decoder) decodeStruct(n *node, v reflect.Value, exts *[]*Extension) error {
+	if n.kind == nullNode {
+		return nil
+	}
+	if n.kind != objectNode {
+		return d.errorf(n, "cannot decode %s into %s", n.kind, v.Type())
+	}
+
+	fields := cachedFields(v.Type())
+	if exts != nil {
+		*exts = nil
+	}
+	for i, key := range n.keys {
+		if err := d.decodeMember(v, fields, key, n.children[i], exts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeMember decodes the object member value n of the key into the field of the struct v,
+// and collects the "x-" patterned field into exts if not nil.
+func (d *decoder) decodeMember(v reflect.Value, fields *structFields, key string, n *node, exts *[]*Extension) error {
+	d.path = append(d.path, key)
+
+	idx, ok := fields.lookup(key, d.strict)
+	switch {
+	case ok:
+		if err := d.decode(n, v.FieldByIndex(idx)); err != nil {
+			return err
+		}
+	case isExtension(key):
+		if exts != nil {
+			*exts = append(*exts, &Extension{Name: key, Value: json.RawMessage(n.JSON())})
+		}
+	case d.strict:
+		return d.errorf(n, "unknown field %q in %s", key, v.Type().Name())
+	}
+
+	d.path = d.path[:len(d.path)-1]
+	return nil
+}
+
+// decodeUnion decodes the object n into the alternative object of u.
+func (d *decoder) decodeUnion(n *node, u union) error {
+	if n.kind == nullNode {
+		return nil
+	}
+	if n.kind != objectNode {
+		return d.errorf(n, "cannot decode %s into %T", n.kind, u)
+	}
+
+	return d.decode(n, reflect.ValueOf(u.variant(n.member("$ref") != nil, n.member("oneOf") != nil)))
+}
+
+// structFields holds the field indexes of the struct by the JSON object key.
+type structFields struct {
+	exact  map[string][]int
+	folded map[string][]int
+}
+
+// lookup returns the field index of the JSON object key, which is matched case-insensitively unless strict.
+func (f *structFields) lookup(key string, strict bool) ([]int, bool) {
+	idx, ok := f.exact[key]
+	if !ok && !strict {
+		idx, ok = f.folded[strings.ToLower(key)]
+	}
+	return idx, ok
+}
+
+var fieldCache sync.Map // map[reflect.Type]*structFields
+
+// cachedFields returns the JSON object keys of the struct type t.
+func cachedFields(t reflect.Type) *structFields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(*structFields)
+	}
+
+	fields := &structFields{
+		exact:  make(map[string][]int),
+		folded: make(map[string][]int),
+	}
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			idx := append(append([]int(nil), index...), i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name := tag
+			if j := strings.IndexByte(tag, ','); j >= 0 {
+				name = tag[:j]
+			}
+			if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+				walk(sf.Type, idx)
+				continue
+			}
+			if sf.PkgPath != "" {
+				continue // unexported
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			if _, ok := fields.exact[name]; ok {
+				continue
+			}
+			fields.exact[name] = idx
+			if _, ok := fields.folded[strings.ToLower(name)]; !ok {
+				fields.folded[strings.ToLower(name)] = idx
+			}
+		}
+	}
+	walk(t, nil)
+
+	f, _ := fieldCache.LoadOrStore(t, fields)
+	return f.(*structFields)
+}
+
+// formatPointer returns the JSON Pointer (RFC 6901) of the reference tokens.
+func formatPointer(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, tok := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(escapePointerToken(tok))
+	}
+	return sb.String()
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapePointerToken escapes the JSON Pointer reference token.
+func escapePointerToken(tok string) string {
+	return pointerEscaper.Replace(tok)
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// unescapePointerToken unescapes the JSON Pointer reference token.
+func unescapePointerToken(tok string) string {
+	return pointerUnescaper.Replace(tok)
+}
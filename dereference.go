@@ -0,0 +1,491 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zchee/go-openrpc/internal/jsonschema"
+)
+
+// CycleMode is the representation of the recursive references in the dereferenced document.
+type CycleMode int
+
+const (
+	// CycleRef leaves the recursive references as the local references to the schemas they refer, such as
+	// "#/components/schemas/Node", so the dereferenced document can be encoded.
+	CycleRef CycleMode = iota
+
+	// CyclePointer replaces the recursive references with the pointers to the dereferenced schemas they refer, so the
+	// dereferenced document has no references but is the cyclic graph, which cannot be encoded to JSON or YAML.
+	CyclePointer
+)
+
+// String returns the name of m.
+func (m CycleMode) String() string {
+	switch m {
+	case CycleRef:
+		return "CycleRef"
+	case CyclePointer:
+		return "CyclePointer"
+	default:
+		return "CycleMode(" + strconv.Itoa(int(m)) + ")"
+	}
+}
+
+// DereferenceOption configures Dereference.
+type DereferenceOption func(*dereferencer)
+
+// WithCycleMode sets the representation of the recursive references. The default is CycleRef.
+func WithCycleMode(m CycleMode) DereferenceOption {
+	return func(d *dereferencer) {
+		d.mode = m
+	}
+}
+
+// WithResolverOptions sets the options of the Resolver which resolves the references, such as WithLoader and WithBaseURI.
+func WithResolverOptions(opts ...ResolverOption) DereferenceOption {
+	return func(d *dereferencer) {
+		d.resolverOpts = append(d.resolverOpts, opts...)
+	}
+}
+
+// Dereference returns the copy of the document s, in which the references of the methods, the components and the
+// nested JSON Schemas are replaced with the copies of the objects they refer.
+//
+// The external references are bundled into the Components first as Bundle, and the Components are kept in the
+// dereferenced document. The references to the schemas are replaced with the schemas they refer, and the other
+// keywords of the "$ref" schemas are dropped as JSON Schema Draft 7 ignores them.
+//
+// The recursive references, which refer the schemas being dereferenced such as the children of the tree node, are
+// represented as WithCycleMode sets. Dereference returns the ReferenceError if any reference cannot be resolved, or
+// the reference refers itself through the other references only.
+func (s *Schema) Dereference(opts ...DereferenceOption) (*Schema, error) {
+	d := &dereferencer{
+		active:  make(map[schemaKey]*jsonschema.Schema),
+		fixups:  make(map[*string]*jsonschema.Schema),
+		names:   make(map[*JSONSchema]string),
+		schemas: make(map[*JSONSchema]bool),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	ds, err := s.Bundle(d.resolverOpts...)
+	if err != nil {
+		return nil, err
+	}
+	d.resolver = NewResolver(ds, d.resolverOpts...)
+	if ds.Components != nil {
+		for name, js := range ds.Components.Schemas {
+			d.names[js] = name
+		}
+	}
+
+	for i, m := range ds.Methods {
+		if m == nil {
+			continue
+		}
+		if err := d.method(m, []string{"methods", strconv.Itoa(i)}); err != nil {
+			return nil, err
+		}
+	}
+	if ds.Components != nil {
+		comps, err := d.components(ds.Components)
+		if err != nil {
+			return nil, err
+		}
+		ds.Components = comps
+	}
+
+	if d.mode == CyclePointer {
+		d.replaceCycles()
+	}
+	return ds, nil
+}
+
+// dereferencer dereferences the references of the bundled document.
+type dereferencer struct {
+	mode         CycleMode
+	resolverOpts []ResolverOption
+	resolver     *Resolver
+
+	// active maps the locations of the schemas being dereferenced to their dereferenced schemas, which are filled when
+	// the dereferencing completes.
+	active map[schemaKey]*jsonschema.Schema
+
+	// fixups maps the "$ref"s of the recursive references left by CyclePointer to the dereferenced schemas they refer.
+	fixups map[*string]*jsonschema.Schema
+
+	// names maps the schemas of the Components to their names.
+	names map[*JSONSchema]string
+
+	// schemas is the set of the dereferenced JSONSchemas in the document.
+	schemas map[*JSONSchema]bool
+}
+
+// schemaKey is the location of the schema, which is the JSON Pointer in the document, or relative to the root
+// JSONSchema if the root is not nil.
+type schemaKey struct {
+	root    *JSONSchema
+	pointer string
+}
+
+// wrapReferenceError returns err located at the path, unless err is the ReferenceError already located.
+func wrapReferenceError(ref string, path []string, err error) error {
+	var rerr *ReferenceError
+	if errors.As(err, &rerr) && rerr.Pointer != "" {
+		return err
+	}
+	if rerr != nil {
+		err = rerr.Err
+	}
+	return &ReferenceError{Ref: ref, Pointer: formatPointer(path), Err: err}
+}
+
+func (d *dereferencer) method(m *Method, path []string) error {
+	for i, t := range m.Tags {
+		if t != nil && t.IsReference() {
+			tag, err := d.resolver.ResolveTag(t.Reference.Ref)
+			if err != nil {
+				return wrapReferenceError(t.Reference.Ref, appendPath(path, "tags", strconv.Itoa(i)), err)
+			}
+			c := new(Tag)
+			if err := cloneJSON(c, tag); err != nil {
+				return err
+			}
+			m.Tags[i] = &TagOrReference{Tag: c}
+		}
+	}
+	for i, p := range m.Params {
+		cd, err := d.contentDescriptorOrReference(p, appendPath(path, "params", strconv.Itoa(i)))
+		if err != nil {
+			return err
+		}
+		m.Params[i] = cd
+	}
+	result, err := d.contentDescriptorOrReference(m.Result, appendPath(path, "result"))
+	if err != nil {
+		return err
+	}
+	m.Result = result
+	for i, e := range m.Errors {
+		if e != nil && e.IsReference() {
+			v, err := d.resolver.ResolveError(e.Reference.Ref)
+			if err != nil {
+				return wrapReferenceError(e.Reference.Ref, appendPath(path, "errors", strconv.Itoa(i)), err)
+			}
+			c := new(Error)
+			if err := cloneJSON(c, v); err != nil {
+				return err
+			}
+			m.Errors[i] = &ErrorOrReference{Error: c}
+		}
+	}
+	for i, l := range m.Links {
+		if l != nil && l.IsReference() {
+			v, err := d.resolver.ResolveLink(l.Reference.Ref)
+			if err != nil {
+				return wrapReferenceError(l.Reference.Ref, appendPath(path, "links", strconv.Itoa(i)), err)
+			}
+			c := new(Link)
+			if err := cloneJSON(c, v); err != nil {
+				return err
+			}
+			m.Links[i] = &LinkOrReference{Link: c}
+		}
+	}
+	for i, e := range m.Examples {
+		examplePath := appendPath(path, "examples", strconv.Itoa(i))
+		switch {
+		case e == nil:
+		case e.IsReference():
+			v, err := d.resolver.ResolveExamplePairing(e.Reference.Ref)
+			if err != nil {
+				return wrapReferenceError(e.Reference.Ref, examplePath, err)
+			}
+			p, err := d.examplePairing(v, examplePath)
+			if err != nil {
+				return err
+			}
+			m.Examples[i] = &ExamplePairingOrReference{ExamplePairing: p}
+		case e.IsExamplePairing():
+			p, err := d.examplePairing(e.ExamplePairing, examplePath)
+			if err != nil {
+				return err
+			}
+			m.Examples[i] = &ExamplePairingOrReference{ExamplePairing: p}
+		}
+	}
+	return nil
+}
+
+// components returns the copy of comps whose objects are dereferenced.
+func (d *dereferencer) components(comps *Components) (*Components, error) {
+	c := *comps
+	if comps.ContentDescriptors != nil {
+		c.ContentDescriptors = make(map[string]*ContentDescriptor, len(comps.ContentDescriptors))
+		for _, name := range sortedMapKeys(comps.ContentDescriptors) {
+			cd, err := d.contentDescriptor(comps.ContentDescriptors[name], []string{"components", "contentDescriptors", name})
+			if err != nil {
+				return nil, err
+			}
+			c.ContentDescriptors[name] = cd
+		}
+	}
+	if comps.Schemas != nil {
+		c.Schemas = make(map[string]*JSONSchema, len(comps.Schemas))
+		for _, name := range sortedMapKeys(comps.Schemas) {
+			key := schemaKey{pointer: "#" + formatPointer([]string{"components", "schemas", name})}
+			js, err := d.jsonSchema(comps.Schemas[name], []string{"components", "schemas", name}, key)
+			if err != nil {
+				return nil, err
+			}
+			c.Schemas[name] = js
+		}
+	}
+	if comps.ExamplePairingObjects != nil {
+		c.ExamplePairingObjects = make(map[string]*ExamplePairing, len(comps.ExamplePairingObjects))
+		for _, name := range sortedMapKeys(comps.ExamplePairingObjects) {
+			p, err := d.examplePairing(comps.ExamplePairingObjects[name], []string{"components", "examplePairingObjects", name})
+			if err != nil {
+				return nil, err
+			}
+			c.ExamplePairingObjects[name] = p
+		}
+	}
+	return &c, nil
+}
+
+func (d *dereferencer) contentDescriptorOrReference(cd *ContentDescriptorOrReference, path []string) (*ContentDescriptorOrReference, error) {
+	switch {
+	case cd == nil:
+		return nil, nil
+	case cd.IsReference():
+		v, err := d.resolver.ResolveContentDescriptor(cd.Reference.Ref)
+		if err != nil {
+			return nil, wrapReferenceError(cd.Reference.Ref, path, err)
+		}
+		c, err := d.contentDescriptor(v, path)
+		if err != nil {
+			return nil, err
+		}
+		return &ContentDescriptorOrReference{ContentDescriptor: c}, nil
+	case cd.IsOneOf():
+		oneOf := make([]*ContentDescriptorOrReference, len(cd.OneOf.OneOf))
+		for i, cd := range cd.OneOf.OneOf {
+			c, err := d.contentDescriptorOrReference(cd, appendPath(path, "oneOf", strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			oneOf[i] = c
+		}
+		return &ContentDescriptorOrReference{OneOf: &OneOf{OneOf: oneOf}}, nil
+	case cd.IsContentDescriptor():
+		c, err := d.contentDescriptor(cd.ContentDescriptor, path)
+		if err != nil {
+			return nil, err
+		}
+		return &ContentDescriptorOrReference{ContentDescriptor: c}, nil
+	}
+	return cd, nil
+}
+
+// contentDescriptor returns the dereferenced copy of cd.
+func (d *dereferencer) contentDescriptor(cd *ContentDescriptor, path []string) (*ContentDescriptor, error) {
+	if cd == nil {
+		return nil, nil
+	}
+	c := *cd
+	js, err := d.jsonSchema(cd.Schema, appendPath(path, "schema"))
+	if err != nil {
+		return nil, err
+	}
+	c.Schema = js
+	if cd.Examples != nil {
+		c.Examples = make([]*ExamplePairing, len(cd.Examples))
+		for i, p := range cd.Examples {
+			if c.Examples[i], err = d.examplePairing(p, appendPath(path, "examples", strconv.Itoa(i))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &c, nil
+}
+
+// examplePairing returns the dereferenced copy of p.
+func (d *dereferencer) examplePairing(p *ExamplePairing, path []string) (*ExamplePairing, error) {
+	if p == nil {
+		return nil, nil
+	}
+	c := *p
+	example := func(e *ExampleOrReference, path []string) (*ExampleOrReference, error) {
+		if e == nil || !e.IsReference() {
+			return e, nil
+		}
+		v, err := d.resolver.ResolveExample(e.Reference.Ref)
+		if err != nil {
+			return nil, wrapReferenceError(e.Reference.Ref, path, err)
+		}
+		ex := new(Example)
+		if err := cloneJSON(ex, v); err != nil {
+			return nil, err
+		}
+		return &ExampleOrReference{Example: ex}, nil
+	}
+
+	if p.Params != nil {
+		c.Params = make([]*ExampleOrReference, len(p.Params))
+		for i, e := range p.Params {
+			var err error
+			if c.Params[i], err = example(e, appendPath(path, "params", strconv.Itoa(i))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var err error
+	if c.Result, err = example(p.Result, appendPath(path, "result")); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// jsonSchema returns the dereferenced copy of the JSONSchema js used at the path, which is identified by keys.
+func (d *dereferencer) jsonSchema(js *JSONSchema, path []string, keys ...schemaKey) (*JSONSchema, error) {
+	if js == nil || js.Schema == nil {
+		return js, nil
+	}
+
+	base := d.resolver.BaseURI()
+	var s *jsonschema.Schema
+	var err error
+	if js.Ref != nil {
+		s, err = d.ref(js, base, *js.Ref, path)
+	} else {
+		s, err = d.inline(js, js.Schema, base, path, append(keys, schemaKey{root: js, pointer: "#"}))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &JSONSchema{Schema: s, Extensions: js.Extensions}
+	d.schemas[c] = true
+	return c, nil
+}
+
+// inline returns the dereferenced copy of the schema s in the JSONSchema root used at the path, which is identified by keys.
+func (d *dereferencer) inline(root *JSONSchema, s *jsonschema.Schema, base string, path []string, keys []schemaKey) (*jsonschema.Schema, error) {
+	// the dereferenced schema is allocated first, so that the recursive references refer it
+	out := new(jsonschema.Schema)
+	for _, k := range keys {
+		d.active[k] = out
+	}
+	r, err := jsonschema.Rewrite(s, base, func(s *jsonschema.Schema, tokens []string, base string) (*jsonschema.Schema, error) {
+		if s.Ref == nil {
+			return nil, nil
+		}
+		return d.ref(root, base, *s.Ref, appendPath(path, tokens...))
+	})
+	for _, k := range keys {
+		delete(d.active, k)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	*out = *r
+	return out, nil
+}
+
+// ref returns the dereferenced copy of the schema referred by the ref used in the JSONSchema root at the path, whose
+// base URI is base.
+func (d *dereferencer) ref(root *JSONSchema, base, ref string, path []string) (*jsonschema.Schema, error) {
+	var keys []schemaKey
+	seen := make(map[schemaKey]bool)
+	for cur := ref; ; {
+		key, err := d.key(root, base, cur)
+		if err != nil {
+			return nil, wrapReferenceError(ref, path, chainError(ref, cur, err))
+		}
+		if out, ok := d.active[key]; ok {
+			return d.cycle(key, out), nil
+		}
+		if seen[key] {
+			return nil, wrapReferenceError(ref, path, fmt.Errorf("circular reference through %q", cur))
+		}
+		seen[key] = true
+		keys = append(keys, key)
+
+		target, targetRoot, targetBase, err := d.resolver.lookupSchema(root, base, cur)
+		if err != nil {
+			return nil, wrapReferenceError(ref, path, chainError(ref, cur, err))
+		}
+		if target.Schema == nil {
+			return new(jsonschema.Schema), nil
+		}
+		if target.Ref == nil {
+			return d.inline(targetRoot, target.Schema, targetBase, path, keys)
+		}
+		root, base, cur = targetRoot, targetBase, *target.Ref
+	}
+}
+
+// key returns the location of the schema referred by the ref used in the JSONSchema root, whose base URI is base.
+func (d *dereferencer) key(root *JSONSchema, base, ref string) (schemaKey, error) {
+	scope := &Resolver{state: d.resolver.state, scope: &origin{base: base}}
+	doc, fragment, err := scope.locate(ref)
+	if err != nil {
+		return schemaKey{}, err
+	}
+	tokens, err := pointerTokens(fragment)
+	if err != nil {
+		return schemaKey{}, err
+	}
+
+	pointer := "#" + formatPointer(tokens)
+	switch {
+	case doc != nil:
+		return schemaKey{pointer: doc.uri + pointer}, nil
+	case strings.HasPrefix(pointer, "#/components/schemas/"):
+		return schemaKey{pointer: pointer}, nil
+	default:
+		return schemaKey{root: root, pointer: pointer}, nil
+	}
+}
+
+// cycle returns the schema which represents the recursive reference to the schema at the key, whose dereferenced
+// schema is out.
+func (d *dereferencer) cycle(key schemaKey, out *jsonschema.Schema) *jsonschema.Schema {
+	ref := key.pointer
+	if name, ok := d.names[key.root]; ok && key.root != nil {
+		ref = "#" + formatPointer([]string{"components", "schemas", name}) + strings.TrimPrefix(ref, "#")
+	}
+
+	s := &jsonschema.Schema{Ref: &ref}
+	if d.mode == CyclePointer {
+		d.fixups[s.Ref] = out
+	}
+	return s
+}
+
+// replaceCycles replaces the recursive references left for CyclePointer with the dereferenced schemas they refer.
+func (d *dereferencer) replaceCycles() {
+	replace := func(s *jsonschema.Schema) *jsonschema.Schema {
+		if s.Ref == nil {
+			return nil
+		}
+		return d.fixups[s.Ref]
+	}
+	for js := range d.schemas {
+		if out := replace(js.Schema); out != nil {
+			js.Schema = out
+			continue
+		}
+		jsonschema.Replace(js.Schema, replace)
+	}
+}
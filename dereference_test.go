@@ -0,0 +1,248 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zchee/go-openrpc/internal/jsonschema"
+)
+
+func TestDereference(t *testing.T) {
+	s := resolveTestSchema(t, `{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"methods": [{
+			"name": "m",
+			"tags": [{"$ref": "#/components/tags/t"}],
+			"params": [
+				{"$ref": "#/components/contentDescriptors/cd"},
+				{"oneOf": [{"$ref": "#/components/contentDescriptors/cd"}, {"name": "q", "schema": {"$ref": "#/components/schemas/alias", "type": "null"}}]}
+			],
+			"result": {"name": "r", "schema": {"items": {"$ref": "external.json#/definitions/E"}}},
+			"errors": [{"$ref": "#/components/errors/e"}],
+			"links": [{"$ref": "#/components/links/l"}],
+			"examples": [{"$ref": "#/components/examplePairingObjects/p"}]
+		}],
+		"components": {
+			"contentDescriptors": {"cd": {"name": "cd", "schema": {"$ref": "#/components/schemas/A"}}},
+			"schemas": {
+				"A": {"type": "object", "properties": {"b": {"$ref": "#/components/schemas/B"}, "d": {"$ref": "#/definitions/D"}}, "definitions": {"D": {"type": "integer"}}},
+				"B": {"type": "string"},
+				"alias": {"$ref": "#/components/schemas/B"}
+			},
+			"errors": {"e": {"code": 1, "message": "x"}},
+			"tags": {"t": {"name": "t"}},
+			"links": {"l": {"name": "l"}},
+			"examples": {"ex": {"name": "ex", "value": 1}},
+			"examplePairingObjects": {"p": {"name": "p", "params": [{"$ref": "#/components/examples/ex"}], "result": {"name": "r", "value": 2}}}
+		}
+	}`)
+	before := mustMarshalJSON(t, s)
+
+	ds, err := s.Dereference(WithResolverOptions(WithLoader(MemoryLoader{
+		"/specs/external.json": []byte(`{"definitions": {"E": {"type": "boolean"}}}`),
+	}), WithBaseURI("/specs/openrpc.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after := mustMarshalJSON(t, s); after != before {
+		t.Fatalf("Dereference changed the document:\n%s\nwant\n%s", after, before)
+	}
+
+	const (
+		a  = `{"type": "object", "properties": {"b": {"type": "string"}, "d": {"type": "integer"}}, "definitions": {"D": {"type": "integer"}}}`
+		cd = `{"name": "cd", "schema": ` + a + `}`
+		p  = `{"name": "p", "params": [{"name": "ex", "value": 1}], "result": {"name": "r", "value": 2}}`
+	)
+	want := resolveTestSchema(t, `{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"methods": [{
+			"name": "m",
+			"tags": [{"name": "t"}],
+			"params": [`+cd+`, {"oneOf": [`+cd+`, {"name": "q", "schema": {"type": "string"}}]}],
+			"result": {"name": "r", "schema": {"items": {"type": "boolean"}}},
+			"errors": [{"code": 1, "message": "x"}],
+			"links": [{"name": "l"}],
+			"examples": [`+p+`]
+		}],
+		"components": {
+			"contentDescriptors": {"cd": `+cd+`},
+			"schemas": {"A": `+a+`, "B": {"type": "string"}, "alias": {"type": "string"}, "E": {"type": "boolean"}},
+			"errors": {"e": {"code": 1, "message": "x"}},
+			"tags": {"t": {"name": "t"}},
+			"links": {"l": {"name": "l"}},
+			"examples": {"ex": {"name": "ex", "value": 1}},
+			"examplePairingObjects": {"p": `+p+`}
+		}
+	}`)
+	if got, w := mustMarshalJSON(t, ds), mustMarshalJSON(t, want); got != w {
+		t.Fatalf("Dereference =\n%s\nwant\n%s", got, w)
+	}
+}
+
+// dereferenceCycleDocument has the recursive schemas: the tree Node refers itself through the Components, the List
+// refers itself by "#", and the Even and the Odd refer each other.
+const dereferenceCycleDocument = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "t", "version": "1"},
+	"methods": [{
+		"name": "m",
+		"params": [{"name": "p", "schema": {"$ref": "#/components/schemas/Node"}}],
+		"result": {"name": "r", "schema": {"$ref": "#/components/schemas/Even"}}
+	}],
+	"components": {
+		"schemas": {
+			"Node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}}}},
+			"List": {"properties": {"next": {"$ref": "#"}}},
+			"Even": {"properties": {"next": {"$ref": "#/components/schemas/Odd"}}},
+			"Odd": {"properties": {"next": {"$ref": "#/components/schemas/Even"}}}
+		}
+	}
+}`
+
+func TestDereferenceCycleRef(t *testing.T) {
+	ds, err := resolveTestSchema(t, dereferenceCycleDocument).Dereference()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		node = `{"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}}}}`
+		even = `{"properties": {"next": {"properties": {"next": {"$ref": "#/components/schemas/Even"}}}}}`
+	)
+	want := resolveTestSchema(t, `{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"methods": [{"name": "m", "params": [{"name": "p", "schema": `+node+`}], "result": {"name": "r", "schema": `+even+`}}],
+		"components": {
+			"schemas": {
+				"Node": `+node+`,
+				"List": {"properties": {"next": {"$ref": "#/components/schemas/List"}}},
+				"Even": `+even+`,
+				"Odd": {"properties": {"next": {"properties": {"next": {"$ref": "#/components/schemas/Odd"}}}}}
+			}
+		}
+	}`)
+	if got, w := mustMarshalJSON(t, ds), mustMarshalJSON(t, want); got != w {
+		t.Fatalf("Dereference =\n%s\nwant\n%s", got, w)
+	}
+}
+
+func TestDereferenceCyclePointer(t *testing.T) {
+	ds, err := resolveTestSchema(t, dereferenceCycleDocument).Dereference(WithCycleMode(CyclePointer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	param := ds.Methods[0].Params[0].ContentDescriptor.Schema.Schema
+	if param.Ref != nil || param.Properties["children"].Items.Schema != param {
+		t.Fatal("the children of the param Node do not point to the param Node")
+	}
+	node := ds.Components.Schemas["Node"].Schema
+	if node.Properties["children"].Items.Schema != node {
+		t.Fatal("the children of the component Node do not point to the component Node")
+	}
+	// the properties are the values, so the copy of the recursive schema shares its properties
+	list := ds.Components.Schemas["List"].Schema
+	if next := list.Properties["next"]; !sameProperties(&next, list) {
+		t.Fatal("the next of the List does not point to the List")
+	}
+	even := ds.Methods[0].Result.ContentDescriptor.Schema.Schema
+	if odd, next := even.Properties["next"], even.Properties["next"].Properties["next"]; odd.Ref != nil || !sameProperties(&next, even) {
+		t.Fatal("the Even and the Odd do not point to each other")
+	}
+}
+
+// sameProperties reports whether the schemas a and b share the same properties.
+func sameProperties(a, b *jsonschema.Schema) bool {
+	return a.Properties != nil && reflect.ValueOf(a.Properties).Pointer() == reflect.ValueOf(b.Properties).Pointer()
+}
+
+func TestDereferenceError(t *testing.T) {
+	tests := map[string]struct {
+		method     string
+		components string
+		pointer    string
+		msg        string
+	}{
+		"missingContentDescriptor": {
+			method:  `{"name": "m", "params": [{"name": "a", "schema": {}}, {"$ref": "#/components/contentDescriptors/none"}]}`,
+			pointer: "/methods/0/params/1",
+		},
+		"missingSchema": {
+			method:  `{"name": "m", "params": [], "result": {"name": "r", "schema": {"properties": {"a": {"$ref": "#/components/schemas/none"}}}}}`,
+			pointer: "/methods/0/result/schema/properties/a",
+		},
+		"missingError": {
+			method:  `{"name": "m", "params": [], "errors": [{"$ref": "#/components/errors/none"}]}`,
+			pointer: "/methods/0/errors/0",
+		},
+		"missingExample": {
+			method:  `{"name": "m", "params": [], "examples": [{"name": "e", "params": [{"$ref": "#/components/examples/none"}], "result": {"name": "r", "value": 1}}]}`,
+			pointer: "/methods/0/examples/0/params/0",
+		},
+		"missingDocument": {
+			method:  `{"name": "m", "params": [{"name": "a", "schema": {"$ref": "missing.json"}}]}`,
+			pointer: "/methods/0/params/0/schema",
+			msg:     "missing.json",
+		},
+		"circularAlias": {
+			method:     `{"name": "m", "params": [{"name": "a", "schema": {"$ref": "#/components/schemas/loop1"}}]}`,
+			components: `{"schemas": {"loop1": {"$ref": "#/components/schemas/loop2"}, "loop2": {"$ref": "#/components/schemas/loop1"}}}`,
+			pointer:    "/methods/0/params/0/schema",
+			msg:        "circular reference",
+		},
+		"selfAlias": {
+			method:     `{"name": "m", "params": []}`,
+			components: `{"schemas": {"self": {"$ref": "#/components/schemas/self"}}}`,
+			pointer:    "/components/schemas/self",
+			msg:        "circular reference",
+		},
+		"nestedInComponent": {
+			method:     `{"name": "m", "params": []}`,
+			components: `{"schemas": {"a": {"items": [{}, {"$ref": "#/definitions/none"}]}}}`,
+			pointer:    "/components/schemas/a/items/1",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			components := tt.components
+			if components == "" {
+				components = `{}`
+			}
+			s := resolveTestSchema(t, `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": [`+tt.method+`], "components": `+components+`}`)
+			_, err := s.Dereference(WithResolverOptions(WithLoader(MemoryLoader{}), WithBaseURI("/specs/openrpc.json")))
+			var rerr *ReferenceError
+			if !errors.As(err, &rerr) {
+				t.Fatalf("Dereference = %v, want *ReferenceError", err)
+			}
+			if rerr.Pointer != tt.pointer {
+				t.Fatalf("ReferenceError at %q, want %q (%v)", rerr.Pointer, tt.pointer, err)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("Error() = %q does not contain %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+func TestCycleModeString(t *testing.T) {
+	tests := map[CycleMode]string{
+		CycleRef:     "CycleRef",
+		CyclePointer: "CyclePointer",
+		CycleMode(9): "CycleMode(9)",
+	}
+	for m, want := range tests {
+		if got := m.String(); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	}
+}
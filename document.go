@@ -0,0 +1,594 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the OpenRPC document which supports the lossless round-trip editing.
+//
+// The Schema is the typed model of the document. Encode applies the programmatic edits of the Schema to the original text,
+// so the original key order of the objects including the Components maps and the "x-" extensions is kept,
+// the unchanged subtrees are re-emitted byte for byte, and the fields which the Schema does not model are kept as is.
+type Document struct {
+	// Schema is the typed model of the document.
+	Schema *Schema
+
+	data []byte
+	src  *source
+
+	// base is the JSON encoding of the Schema at parse time.
+	base *node
+}
+
+// ParseDocument parses the OpenRPC document data encoded in JSON or YAML for the lossless round-trip editing.
+func ParseDocument(data []byte, opts ...ParseOption) (*Document, error) {
+	o := newParseOptions(opts)
+
+	src, err := parseSource(data, o.format)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := src.decode(o)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := marshalNode(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Schema: schema,
+		data:   data,
+		src:    src,
+		base:   base,
+	}, nil
+}
+
+// Format returns the encoding format of the document.
+func (d *Document) Format() Format {
+	return d.src.format
+}
+
+// Position returns the 1-based line and column of the value at the JSON Pointer in the original text of the document,
+// or false if the original text does not have the value.
+func (d *Document) Position(pointer string) (line, column int, ok bool) {
+	if pointer != "" && pointer[0] != '/' {
+		return 0, 0, false
+	}
+
+	var tokens []string
+	if pointer != "" {
+		tokens = strings.Split(pointer[1:], "/")
+		for i, tok := range tokens {
+			tokens[i] = unescapePointerToken(tok)
+		}
+	}
+	n := d.src.root.lookup(tokens)
+	if n == nil {
+		return 0, 0, false
+	}
+
+	if n.offset >= 0 {
+		line, column = lineColumn(d.data, n.offset)
+		return line, column, true
+	}
+	return n.line, n.column, n.line > 0
+}
+
+// Encode returns the text of the document with the edits of the Schema applied, in the format of the original document.
+//
+// The JSON document keeps the original text of the unchanged subtrees and the original layout of the changed objects and arrays.
+// The YAML document keeps the comments, the anchors and the styles of the unchanged nodes.
+func (d *Document) Encode() ([]byte, error) {
+	cur, err := marshalNode(d.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	switch d.src.format {
+	case FormatJSON:
+		s := &jsonSplicer{
+			src:  d.data,
+			unit: detectIndentUnit(d.data, "  "),
+		}
+		root := d.src.root
+
+		var buf bytes.Buffer
+		buf.Write(d.data[:root.offset])
+		buf.Write(s.render(root, d.base, cur))
+		buf.Write(d.data[root.offset+len(root.raw):])
+		return buf.Bytes(), nil
+
+	case FormatYAML:
+		// re-parse the original text because merging edits the YAML node in place
+		_, doc, err := parseYAML(d.data)
+		if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 {
+			doc.Content = []*yaml.Node{nodeToYAML(cur)}
+		} else {
+			mergeYAML(doc.Content[0], d.base, cur)
+		}
+
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(len(detectIndentUnit(d.data, "    ")))
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("openrpc: encode YAML: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("openrpc: encode YAML: %w", err)
+		}
+		return buf.Bytes(), nil
+
+	default:
+		return nil, fmt.Errorf("openrpc: unknown format %s", d.src.format)
+	}
+}
+
+// marshalNode returns the node tree of the JSON encoding of v.
+func marshalNode(v interface{}) (*node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return parseNode(data)
+}
+
+// nodeEqual reports whether the a and b are the same JSON value.
+//
+// The object members are compared regardless of the order, and the numbers are compared by the value.
+func nodeEqual(a, b *node) bool {
+	if a.kind != b.kind {
+		return false
+	}
+
+	switch a.kind {
+	case nullNode:
+		return true
+
+	case boolNode, stringNode:
+		return a.value == b.value
+
+	case numberNode:
+		if a.value == b.value {
+			return true
+		}
+		fa, errA := strconv.ParseFloat(a.value, 64)
+		fb, errB := strconv.ParseFloat(b.value, 64)
+		return errA == nil && errB == nil && fa == fb
+
+	case arrayNode:
+		if len(a.children) != len(b.children) {
+			return false
+		}
+		for i := range a.children {
+			if !nodeEqual(a.children[i], b.children[i]) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		if len(a.keys) != len(b.keys) {
+			return false
+		}
+		for i, key := range a.keys {
+			bv := b.member(key)
+			if bv == nil || !nodeEqual(a.children[i], bv) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// matchElements returns the index of the element of the base array b for each element of the current array c, or -1 for the new element.
+//
+// The named elements, the objects which have the unique "name" member in the array, are matched by the name.
+// The other elements are matched by the index with the unnamed element of b.
+func matchElements(b, c *node) []int {
+	bNames, cNames := elementNames(b), elementNames(c)
+
+	idx := make([]int, len(c.children))
+	for i, child := range c.children {
+		idx[i] = -1
+		if name, ok := elementName(child); ok && cNames[name] >= 0 {
+			if j, ok := bNames[name]; ok && j >= 0 {
+				idx[i] = j
+			}
+			continue
+		}
+		if i < len(b.children) {
+			if name, ok := elementName(b.children[i]); !ok || bNames[name] < 0 {
+				idx[i] = i
+			}
+		}
+	}
+	return idx
+}
+
+// elementNames returns the index of the named elements of the array n by the "name" member.
+// The index of the name which is shared by the multiple elements is -1.
+func elementNames(n *node) map[string]int {
+	names := make(map[string]int, len(n.children))
+	for i, child := range n.children {
+		name, ok := elementName(child)
+		if !ok {
+			continue
+		}
+		if _, dup := names[name]; dup {
+			i = -1
+		}
+		names[name] = i
+	}
+	return names
+}
+
+// elementName returns the "name" member of the array element n, and reports whether n is the object which has the string name.
+func elementName(n *node) (string, bool) {
+	if n.kind != objectNode {
+		return "", false
+	}
+	name := n.member("name")
+	if name == nil || name.kind != stringNode {
+		return "", false
+	}
+	return name.value, true
+}
+
+// foldedKey returns the key of the member of the base object b or the current object c which the original key is decoded into.
+//
+// The non-strict decoding matches the object keys to the fields case-insensitively, so the original key such as "Summary"
+// is modeled as "summary" unless the original object has the exact key, which is reported by exact.
+func foldedKey(key string, exact func(string) bool, b, c *node) string {
+	if b.member(key) != nil || c.member(key) != nil {
+		return key
+	}
+
+	lower := strings.ToLower(key)
+	for _, n := range []*node{b, c} {
+		for _, k := range n.keys {
+			if strings.ToLower(k) == lower && !exact(k) {
+				return k
+			}
+		}
+	}
+	return key
+}
+
+// detectIndentUnit returns the smallest indentation of the lines in data, or def if no line is indented.
+func detectIndentUnit(data []byte, def string) string {
+	unit := ""
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		i := 0
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i == 0 || i == len(line) || line[i] == '#' {
+			continue
+		}
+		if unit == "" || i < len(unit) {
+			unit = string(line[:i])
+		}
+	}
+
+	if unit == "" {
+		return def
+	}
+	return unit
+}
+
+// jsonSplicer renders the edited JSON document by splicing the original text.
+type jsonSplicer struct {
+	src  []byte
+	unit string
+}
+
+// render returns the JSON text of the current value c which is edited from the base value b of the original node o.
+//
+// The b is nil if the original value is not modeled by the Schema.
+func (s *jsonSplicer) render(o, b, c *node) []byte {
+	if b != nil && nodeEqual(b, c) {
+		return o.raw
+	}
+
+	if b != nil && o.kind == c.kind && b.kind == c.kind {
+		switch c.kind {
+		case objectNode:
+			return s.renderObject(o, b, c)
+		case arrayNode:
+			if len(o.children) == len(b.children) {
+				return s.renderArray(o, b, c)
+			}
+		}
+	}
+
+	return s.format(c, s.lineIndent(o.offset), bytes.IndexByte(o.raw, '\n') >= 0)
+}
+
+// format returns the JSON text of the new value c indented by indent if multiline.
+func (s *jsonSplicer) format(c *node, indent string, multiline bool) []byte {
+	compact := c.JSON()
+	if !multiline || (c.kind != objectNode && c.kind != arrayNode) {
+		return compact
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, compact, indent, s.unit); err != nil {
+		return compact
+	}
+	return buf.Bytes()
+}
+
+// lineIndent returns the indentation of the line containing the offset.
+func (s *jsonSplicer) lineIndent(offset int) string {
+	start := offset
+	for start > 0 && s.src[start-1] != '\n' {
+		start--
+	}
+	end := start
+	for end < len(s.src) && (s.src[end] == ' ' || s.src[end] == '\t') {
+		end++
+	}
+	return string(s.src[start:end])
+}
+
+// containerLayout is the whitespace and separators of the original object or array.
+type containerLayout struct {
+	lead, between, trail []byte
+	indent               string
+	multiline            bool
+}
+
+// layout returns the layout of the original container o whose members or elements begin at starts.
+func (s *jsonSplicer) layout(o *node, starts []int) containerLayout {
+	if len(starts) == 0 {
+		outer := s.lineIndent(o.offset)
+		if bytes.IndexByte(s.src, '\n') < 0 {
+			return containerLayout{between: []byte(","), indent: outer + s.unit}
+		}
+		indent := outer + s.unit
+		return containerLayout{
+			lead:      []byte("\n" + indent),
+			between:   []byte(",\n" + indent),
+			trail:     []byte("\n" + outer),
+			indent:    indent,
+			multiline: true,
+		}
+	}
+
+	first, last := o.children[0], o.children[len(o.children)-1]
+	l := containerLayout{
+		lead:   s.src[o.offset+1 : starts[0]],
+		trail:  s.src[last.offset+len(last.raw) : o.offset+len(o.raw)-1],
+		indent: s.lineIndent(starts[0]),
+	}
+	l.multiline = bytes.IndexByte(l.lead, '\n') >= 0
+	if len(starts) > 1 {
+		l.between = s.src[first.offset+len(first.raw) : starts[1]]
+	} else {
+		l.between = append([]byte{','}, l.lead...)
+	}
+
+	return l
+}
+
+// join returns the container text of the parts with the layout l.
+func (l *containerLayout) join(open, close byte, parts [][]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(open)
+	if len(parts) > 0 {
+		buf.Write(l.lead)
+		for i, part := range parts {
+			if i > 0 {
+				buf.Write(l.between)
+			}
+			buf.Write(part)
+		}
+		buf.Write(l.trail)
+	}
+	buf.WriteByte(close)
+
+	return buf.Bytes()
+}
+
+func (s *jsonSplicer) renderObject(o, b, c *node) []byte {
+	l := s.layout(o, o.keyOffsets)
+
+	colon := []byte(":")
+	if l.multiline {
+		colon = []byte(": ")
+	}
+	if len(o.keys) > 0 {
+		keyEnd := stringEnd(s.src, o.keyOffsets[0])
+		colon = s.src[keyEnd:o.children[0].offset]
+	}
+
+	exact := func(key string) bool { return o.member(key) != nil }
+	modeled := make(map[string]bool, len(o.keys))
+
+	var parts [][]byte
+	for i, key := range o.keys {
+		ov := o.children[i]
+		key = foldedKey(key, exact, b, c)
+		modeled[key] = true
+		bv, cv := b.member(key), c.member(key)
+
+		var value []byte
+		switch {
+		case bv == nil && cv == nil:
+			value = ov.raw // not modeled by the Schema
+		case cv == nil:
+			continue // removed
+		case bv == nil:
+			value = s.format(cv, l.indent, l.multiline)
+		default:
+			value = s.render(ov, bv, cv)
+		}
+
+		part := append([]byte(nil), s.src[o.keyOffsets[i]:ov.offset]...)
+		parts = append(parts, append(part, value...))
+	}
+
+	for i, key := range c.keys {
+		if modeled[key] {
+			continue
+		}
+		cv := c.children[i]
+		if bv := b.member(key); bv != nil && nodeEqual(bv, cv) {
+			continue // not in the original text, but emitted by the encoding
+		}
+
+		part := appendString(nil, key)
+		part = append(part, colon...)
+		parts = append(parts, append(part, s.format(cv, l.indent, l.multiline)...))
+	}
+
+	return l.join('{', '}', parts)
+}
+
+func (s *jsonSplicer) renderArray(o, b, c *node) []byte {
+	starts := make([]int, len(o.children))
+	for i, child := range o.children {
+		starts[i] = child.offset
+	}
+	l := s.layout(o, starts)
+
+	parts := make([][]byte, len(c.children))
+	for i, j := range matchElements(b, c) {
+		if j < 0 {
+			parts[i] = s.format(c.children[i], l.indent, l.multiline)
+			continue
+		}
+		parts[i] = s.render(o.children[j], b.children[j], c.children[i])
+	}
+
+	return l.join('[', ']', parts)
+}
+
+// stringEnd returns the offset just after the JSON string literal which begins at offset in data.
+func stringEnd(data []byte, offset int) int {
+	for i := offset + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// mergeYAML applies the edit from the base value b to the current value c onto the original YAML node y in place.
+//
+// The b is nil if the original value is not modeled by the Schema.
+func mergeYAML(y *yaml.Node, b, c *node) {
+	if b != nil && nodeEqual(b, c) {
+		return
+	}
+
+	switch {
+	case y.Kind == yaml.AliasNode:
+		// never edit the anchored node which is shared by the other aliases
+		replaceYAML(y, c)
+	case b != nil && y.Kind == yaml.MappingNode && b.kind == objectNode && c.kind == objectNode:
+		mergeYAMLMapping(y, b, c)
+	case b != nil && y.Kind == yaml.SequenceNode && b.kind == arrayNode && c.kind == arrayNode && len(y.Content) == len(b.children):
+		content := make([]*yaml.Node, len(c.children))
+		for i, j := range matchElements(b, c) {
+			if j < 0 {
+				content[i] = nodeToYAML(c.children[i])
+				continue
+			}
+			mergeYAML(y.Content[j], b.children[j], c.children[i])
+			content[i] = y.Content[j]
+		}
+		y.Content = content
+	default:
+		replaceYAML(y, c)
+	}
+}
+
+func mergeYAMLMapping(y *yaml.Node, b, c *node) {
+	keyName := func(key *yaml.Node) string {
+		if key.Kind == yaml.AliasNode {
+			return key.Alias.Value
+		}
+		return key.Value
+	}
+	exact := func(name string) bool {
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			if keyName(y.Content[i]) == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	explicit := make(map[string]bool)
+	content := make([]*yaml.Node, 0, len(y.Content))
+	for i := 0; i+1 < len(y.Content); i += 2 {
+		key, value := y.Content[i], y.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			content = append(content, key, value)
+			continue
+		}
+
+		name := foldedKey(keyName(key), exact, b, c)
+		explicit[name] = true
+
+		bv, cv := b.member(name), c.member(name)
+		switch {
+		case bv == nil && cv == nil:
+			// not modeled by the Schema
+		case cv == nil:
+			continue // removed
+		case bv == nil:
+			replaceYAML(value, cv)
+		default:
+			mergeYAML(value, bv, cv)
+		}
+		content = append(content, key, value)
+	}
+
+	for i, key := range c.keys {
+		if explicit[key] {
+			continue
+		}
+		cv := c.children[i]
+		if bv := b.member(key); bv != nil && nodeEqual(bv, cv) {
+			continue // merged by the merge key, or emitted by the encoding
+		}
+		content = append(content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			nodeToYAML(cv),
+		)
+	}
+
+	y.Content = content
+}
+
+// replaceYAML replaces the YAML node y by the current value c with keeping the comments and the scalar style of y.
+func replaceYAML(y *yaml.Node, c *node) {
+	n := nodeToYAML(c)
+	n.HeadComment, n.LineComment, n.FootComment = y.HeadComment, y.LineComment, y.FootComment
+	if y.Kind == yaml.ScalarNode && n.Kind == yaml.ScalarNode && n.Style == 0 && n.Tag == y.ShortTag() {
+		n.Style = y.Style
+	}
+	*y = *n
+}
@@ -0,0 +1,240 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+const documentTestJSON = `{
+  "openrpc": "1.2.6",
+  "x-first": {"b": 1, "a": 2},
+  "info": {
+    "version": "1.0.0",
+    "title": "t",
+    "x-unknown-order": true
+  },
+  "methods": [
+    {
+      "name": "b",
+      "params": [],
+      "result": {"name": "r", "schema": {"type": "string"}}
+    },
+    {
+      "name": "a",
+      "params": [{"$ref": "#/components/contentDescriptors/p"}, {"name": "q", "schema": {}}],
+      "result": {"name": "r", "schema": {}}
+    }
+  ],
+  "components": {
+    "schemas": {"z": {}, "y": {"type": "integer"}}
+  }
+}
+`
+
+const documentTestYAML = `# the document
+openrpc: 1.2.6
+info:
+  version: 1.0.0 # the version
+  title: t
+methods:
+  - name: b
+    params: []
+    result: &r
+      name: r
+      schema: {}
+  - name: a
+    params: []
+    result: *r
+`
+
+func TestDocumentEncodeUnchanged(t *testing.T) {
+	for _, data := range []string{documentTestJSON, documentTestYAML} {
+		d, err := ParseDocument([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := d.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != data {
+			t.Fatalf("Encode() = %s, want %s", got, data)
+		}
+	}
+}
+
+func TestDocumentEncodeJSON(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		opts []ParseOption
+		edit func(s *Schema)
+		want string
+	}{
+		"scalar": {
+			data: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": []}`,
+			edit: func(s *Schema) { s.Info.Title = "u" },
+			want: `{"openrpc": "1.2.6", "info": {"title": "u", "version": "1"}, "methods": []}`,
+		},
+		"keepUnknown": {
+			data: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1", "unknown": [1, 2]}, "methods": []}`,
+			edit: func(s *Schema) { s.Info.Version = "2" },
+			want: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "2", "unknown": [1, 2]}, "methods": []}`,
+		},
+		"addField": {
+			data: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": []}`,
+			edit: func(s *Schema) { s.Info.Description = "d" },
+			want: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1", "description": "d"}, "methods": []}`,
+		},
+		"removeField": {
+			data: `{"openrpc": "1.2.6", "info": {"title": "t", "description": "d", "version": "1"}, "methods": []}`,
+			edit: func(s *Schema) { s.Info.Description = "" },
+			want: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": []}`,
+		},
+		"foldedKey": {
+			data: `{"openrpc": "1.2.6", "info": {"Title": "t", "version": "1"}, "methods": []}`,
+			edit: func(s *Schema) { s.Info.Title = "u" },
+			want: `{"openrpc": "1.2.6", "info": {"Title": "u", "version": "1"}, "methods": []}`,
+		},
+		"foldedKeyRemoved": {
+			data: `{"openrpc": "1.2.6", "info": {"title": "t", "Description": "d", "version": "1"}, "methods": []}`,
+			edit: func(s *Schema) { s.Info.Description = "" },
+			want: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": []}`,
+		},
+		"reorderNamed": {
+			data: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": [{"name": "a", "x-a": 1, "params": [], "result": {"name": "r", "schema": {}}}, {"name": "b", "params": [], "result": {"name": "r", "schema": {}}}]}`,
+			edit: func(s *Schema) { s.Methods[0], s.Methods[1] = s.Methods[1], s.Methods[0] },
+			want: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": [{"name": "b", "params": [], "result": {"name": "r", "schema": {}}}, {"name": "a", "x-a": 1, "params": [], "result": {"name": "r", "schema": {}}}]}`,
+		},
+		"unnamedElement": {
+			data: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": [{"name": "a", "params": [{"$ref": "#/x",  "note": 1}, {"name": "p", "schema": {}}], "result": {"name": "r", "schema": {}}}]}`,
+			edit: func(s *Schema) { s.Methods[0].Params[1].ContentDescriptor.Summary = "s" },
+			want: `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": [{"name": "a", "params": [{"$ref": "#/x",  "note": 1}, {"name": "p", "schema": {}, "summary": "s"}], "result": {"name": "r", "schema": {}}}]}`,
+		},
+		"multiline": {
+			data: "{\n  \"openrpc\": \"1.2.6\",\n  \"info\": {\n    \"title\": \"t\",\n    \"version\": \"1\"\n  },\n  \"methods\": []\n}\n",
+			edit: func(s *Schema) { s.Info.License = &License{Name: "MIT"} },
+			want: "{\n  \"openrpc\": \"1.2.6\",\n  \"info\": {\n    \"title\": \"t\",\n    \"version\": \"1\",\n    \"license\": {\n      \"name\": \"MIT\"\n    }\n  },\n  \"methods\": []\n}\n",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			d, err := ParseDocument([]byte(tt.data), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(d.Schema)
+			got, err := d.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("Encode() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentEncodeYAML(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		edit func(s *Schema)
+		want string
+	}{
+		"comments": {
+			data: documentTestYAML,
+			edit: func(s *Schema) { s.Info.Version = "2.0.0" },
+			want: "# the document\nopenrpc: 1.2.6\ninfo:\n  version: 2.0.0 # the version\n  title: t\nmethods:\n  - name: b\n    params: []\n    result: &r\n      name: r\n      schema: {}\n  - name: a\n    params: []\n    result: *r\n",
+		},
+		"foldedKey": {
+			data: "openrpc: 1.2.6\ninfo:\n  Title: t\n  version: \"1\"\nmethods: []\n",
+			edit: func(s *Schema) { s.Info.Title = "u" },
+			want: "openrpc: 1.2.6\ninfo:\n  Title: u\n  version: \"1\"\nmethods: []\n",
+		},
+		"alias": {
+			data: documentTestYAML,
+			edit: func(s *Schema) {
+				s.Methods[1].Result.ContentDescriptor = &ContentDescriptor{Name: "s", Schema: NewBoolJSONSchema(true)}
+			},
+			want: "# the document\nopenrpc: 1.2.6\ninfo:\n  version: 1.0.0 # the version\n  title: t\nmethods:\n  - name: b\n    params: []\n    result: &r\n      name: r\n      schema: {}\n  - name: a\n    params: []\n    result:\n      name: s\n      schema: true\n",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			d, err := ParseDocument([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Format() != FormatYAML {
+				t.Fatalf("Format() = %v, want %v", d.Format(), FormatYAML)
+			}
+			tt.edit(d.Schema)
+			got, err := d.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("Encode() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchElements(t *testing.T) {
+	tests := map[string]struct {
+		b, c string
+		want []int
+	}{
+		"index":         {b: `[1, 2]`, c: `[1, 2, 3]`, want: []int{0, 1, -1}},
+		"named":         {b: `[{"name": "a"}, {"name": "b"}]`, c: `[{"name": "b"}, {"name": "c"}, {"name": "a"}]`, want: []int{1, -1, 0}},
+		"unnamed":       {b: `[{"$ref": "#/a"}, {"name": "b"}]`, c: `[{"$ref": "#/a"}, {"name": "b"}]`, want: []int{0, 1}},
+		"unnamedMoved":  {b: `[{"name": "b"}, {"$ref": "#/a"}]`, c: `[{"$ref": "#/a"}, {"name": "b"}]`, want: []int{-1, 0}},
+		"duplicateName": {b: `[{"name": "a"}, {"name": "a"}]`, c: `[{"name": "a"}, {"name": "a"}]`, want: []int{0, 1}},
+		"notObject":     {b: `[{"name": "a"}, "a"]`, c: `["a", {"name": "a"}]`, want: []int{-1, 0}},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			b, err := parseNode([]byte(tt.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := parseNode([]byte(tt.c))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := matchElements(b, c); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("matchElements(%s, %s) = %v, want %v", tt.b, tt.c, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentPosition(t *testing.T) {
+	d, err := ParseDocument([]byte(documentTestJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		line, column int
+		ok           bool
+	}{
+		"":                         {line: 1, column: 1, ok: true},
+		"/info/title":              {line: 6, column: 14, ok: true},
+		"/methods/1/params/0/$ref": {line: 17, column: 27, ok: true},
+		"/methods/2":               {},
+		"info":                     {},
+	}
+	for pointer, tt := range tests {
+		line, column, ok := d.Position(pointer)
+		if line != tt.line || column != tt.column || ok != tt.ok {
+			t.Errorf("Position(%q) = %d, %d, %t, want %d, %d, %t", pointer, line, column, ok, tt.line, tt.column, tt.ok)
+		}
+	}
+}
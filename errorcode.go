@@ -0,0 +1,207 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"sort"
+	"strconv"
+)
+
+// IsPredefined reports whether the c is one of the error codes pre-defined by the JSON-RPC 2.0 specification.
+func (c ErrorCode) IsPredefined() bool {
+	switch c {
+	case ParseError, InvalidRequest, MethodNotFound, InvalidParams, InternalError:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsReserved reports whether the c is in the range from and including ReservedErrorStart to ReservedErrorEnd,
+// which is reserved for the pre-defined errors and the implementation-defined server-errors.
+func (c ErrorCode) IsReserved() bool {
+	return ReservedErrorStart <= c && c <= ReservedErrorEnd
+}
+
+// IsServerError reports whether the c is in the range from and including ServerErrorStart to ServerErrorEnd,
+// which is reserved for the implementation-defined server-errors.
+func (c ErrorCode) IsServerError() bool {
+	return ServerErrorStart <= c && c <= ServerErrorEnd
+}
+
+// IsApplicationError reports whether the c is not reserved, so that it is available for the application defined errors.
+func (c ErrorCode) IsApplicationError() bool {
+	return !c.IsReserved()
+}
+
+// The rules of the error code checks reported as ValidationError.Rule.
+const (
+	// RuleReservedErrorCode is the rule that the errors MUST NOT use the error codes reserved for the pre-defined errors,
+	// except the pre-defined ones. The implementation-defined server-errors are reserved too.
+	RuleReservedErrorCode = "reserved-error-code"
+
+	// RuleErrorCodeMessage is the rule that the same error code MUST have the same message across the document.
+	RuleErrorCodeMessage = "error-code-message"
+)
+
+// ErrorCodeEntry is the entry of the error code registry of the document.
+type ErrorCodeEntry struct {
+	// Code is the error code.
+	Code ErrorCode `json:"code"`
+
+	// Messages lists the distinct messages of the errors with the Code, in the order of their first definition.
+	Messages []string `json:"messages"`
+
+	// Methods lists the names of the methods which may return the errors with the Code, in the order of the methods.
+	Methods []string `json:"methods,omitempty"`
+
+	// Pointers lists the JSON Pointers to the errors with the Code, which are defined in the methods or the Components.
+	Pointers []string `json:"pointers"`
+}
+
+// ErrorCodes returns the error code registry of the document, which lists every error code defined in the methods and
+// the Components.Errors in ascending order of the code.
+//
+// The references to the Components.Errors are followed, so the error referred from many methods is listed once.
+func (s *Schema) ErrorCodes() []*ErrorCodeEntry {
+	entries := make(map[ErrorCode]*ErrorCodeEntry)
+	for _, def := range (&checker{schema: s}).errorDefinitions() {
+		e, ok := entries[def.err.Code]
+		if !ok {
+			e = &ErrorCodeEntry{Code: def.err.Code}
+			entries[def.err.Code] = e
+		}
+		if !containsString(e.Messages, def.err.Message) {
+			e.Messages = append(e.Messages, def.err.Message)
+		}
+		for _, name := range def.methods {
+			if !containsString(e.Methods, name) {
+				e.Methods = append(e.Methods, name)
+			}
+		}
+		e.Pointers = append(e.Pointers, formatPointer(def.path))
+	}
+
+	registry := make([]*ErrorCodeEntry, 0, len(entries))
+	for _, e := range entries {
+		registry = append(registry, e)
+	}
+	sort.Slice(registry, func(i, j int) bool {
+		return registry[i].Code < registry[j].Code
+	})
+	return registry
+}
+
+// CheckErrorCodes checks the error codes defined in the methods and the Components.Errors, and returns every violation
+// as ValidationErrors, or nil if the error codes have no violations.
+//
+// The errors MUST NOT use the codes reserved for the pre-defined errors, from and including ReservedErrorStart to
+// ReservedErrorEnd, except the pre-defined codes themselves. The range includes the implementation-defined
+// server-errors, which are not available for the errors of the document either.
+// The same code MUST NOT be used with the different messages.
+func (s *Schema) CheckErrorCodes() error {
+	c := &checker{schema: s}
+
+	messages := make(map[ErrorCode]*errorDefinition)
+	for _, def := range c.errorDefinitions() {
+		code := def.err.Code
+		if code.IsReserved() && !code.IsPredefined() {
+			c.report(RuleReservedErrorCode, appendPath(def.path, "code"), "error code %d is reserved for the pre-defined errors", code)
+		}
+
+		first, ok := messages[code]
+		if !ok {
+			messages[code] = def
+			continue
+		}
+		if def.err.Message != first.err.Message {
+			c.report(RuleErrorCodeMessage, appendPath(def.path, "message"), "error code %d has the message %q, but %q at %s", code, def.err.Message, first.err.Message, formatPointer(first.path))
+		}
+	}
+
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+// errorDefinition is the error defined in the document.
+type errorDefinition struct {
+	err  *Error
+	path []string
+
+	// methods lists the names of the methods which may return the err.
+	methods []string
+}
+
+// errorDefinitions returns the errors defined in the methods, followed by the errors in the Components which are not
+// referred from the methods in the sorted order of the names.
+func (c *checker) errorDefinitions() []*errorDefinition {
+	var defs []*errorDefinition
+	seen := make(map[string]*errorDefinition)
+	add := func(err *Error, path []string, method *Method) {
+		key := formatPointer(path)
+		def, ok := seen[key]
+		if !ok {
+			def = &errorDefinition{err: err, path: path}
+			seen[key] = def
+			defs = append(defs, def)
+		}
+		if method != nil {
+			def.methods = append(def.methods, method.Name)
+		}
+	}
+
+	for i, m := range c.schema.Methods {
+		if m == nil {
+			continue
+		}
+		for j, e := range m.Errors {
+			if err, path := c.errorAt(e, []string{"methods", strconv.Itoa(i), "errors", strconv.Itoa(j)}); err != nil {
+				add(err, path, m)
+			}
+		}
+	}
+
+	if comps := c.schema.Components; comps != nil {
+		names := make([]string, 0, len(comps.Errors))
+		for name := range comps.Errors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := comps.Errors[name]; err != nil {
+				add(err, []string{"components", "errors", name}, nil)
+			}
+		}
+	}
+
+	return defs
+}
+
+// errorAt returns the error of e and its location, which follows the reference to the Components, or nil if it cannot be resolved.
+func (c *checker) errorAt(e *ErrorOrReference, path []string) (*Error, []string) {
+	switch {
+	case e == nil:
+		return nil, nil
+	case e.IsError():
+		return e.Error, path
+	case e.IsReference() && c.schema.Components != nil:
+		if name, ok := componentName(e.Reference.Ref, "errors"); ok {
+			return c.schema.Components.Errors[name], []string{"components", "errors", name}
+		}
+	}
+	return nil, nil
+}
+
+// containsString reports whether the ss contains the s.
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestErrorCodeRanges(t *testing.T) {
+	tests := map[ErrorCode]struct {
+		predefined, reserved, server, application bool
+	}{
+		-32769:         {application: true},
+		-32768:         {reserved: true},
+		ParseError:     {predefined: true, reserved: true},
+		InvalidRequest: {predefined: true, reserved: true},
+		InternalError:  {predefined: true, reserved: true},
+		-32604:         {reserved: true},
+		-32100:         {reserved: true},
+		-32099:         {reserved: true, server: true},
+		-32000:         {reserved: true, server: true},
+		-31999:         {application: true},
+		0:              {application: true},
+	}
+	for code, tt := range tests {
+		if got := code.IsPredefined(); got != tt.predefined {
+			t.Errorf("%d.IsPredefined() = %t", code, got)
+		}
+		if got := code.IsReserved(); got != tt.reserved {
+			t.Errorf("%d.IsReserved() = %t", code, got)
+		}
+		if got := code.IsServerError(); got != tt.server {
+			t.Errorf("%d.IsServerError() = %t", code, got)
+		}
+		if got := code.IsApplicationError(); got != tt.application {
+			t.Errorf("%d.IsApplicationError() = %t", code, got)
+		}
+	}
+}
+
+// errorCodeTestSchema returns the document whose methods "a" and "b" return the errors.
+func errorCodeTestSchema(t *testing.T, errorsA, errorsB, components string) *Schema {
+	t.Helper()
+
+	doc := `{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"methods": [
+			{"name": "a", "params": [], "result": {"name": "r", "schema": {}}, "errors": ` + errorsA + `},
+			{"name": "b", "params": [], "result": {"name": "r", "schema": {}}, "errors": ` + errorsB + `}
+		],
+		"components": {"errors": ` + components + `}
+	}`
+	s := new(Schema)
+	if err := json.Unmarshal([]byte(doc), s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCheckErrorCodes(t *testing.T) {
+	type violation struct {
+		pointer, rule string
+	}
+	tests := map[string]struct {
+		errorsA, errorsB, components string
+		want                         []violation
+	}{
+		"valid": {
+			errorsA:    `[{"code": 1, "message": "x"}, {"$ref": "#/components/errors/e"}]`,
+			errorsB:    `[{"code": 1, "message": "x"}, {"code": -32602, "message": "Invalid params"}]`,
+			components: `{"e": {"code": 2, "message": "y"}}`,
+		},
+		"reserved": {
+			errorsA:    `[{"code": -32001, "message": "x"}]`,
+			errorsB:    `[{"code": -32700, "message": "Parse error"}]`,
+			components: `{"e": {"code": -32768, "message": "y"}}`,
+			want:       []violation{{"/methods/0/errors/0/code", RuleReservedErrorCode}, {"/components/errors/e/code", RuleReservedErrorCode}},
+		},
+		"serverError": {
+			errorsA:    `[{"code": -32000, "message": "x"}]`,
+			errorsB:    `[]`,
+			components: `{}`,
+			want:       []violation{{"/methods/0/errors/0/code", RuleReservedErrorCode}},
+		},
+		"message": {
+			errorsA:    `[{"code": 1, "message": "x"}]`,
+			errorsB:    `[{"code": 1, "message": "y"}]`,
+			components: `{"e": {"code": 1, "message": "z"}}`,
+			want:       []violation{{"/methods/1/errors/0/message", RuleErrorCodeMessage}, {"/components/errors/e/message", RuleErrorCodeMessage}},
+		},
+		"referredOnce": {
+			errorsA:    `[{"$ref": "#/components/errors/e"}]`,
+			errorsB:    `[{"$ref": "#/components/errors/e"}]`,
+			components: `{"e": {"code": -32050, "message": "y"}}`,
+			want:       []violation{{"/components/errors/e/code", RuleReservedErrorCode}},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			err := errorCodeTestSchema(t, tt.errorsA, tt.errorsB, tt.components).CheckErrorCodes()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("CheckErrorCodes() = %v", err)
+				}
+				return
+			}
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("CheckErrorCodes() = %v, want ValidationErrors", err)
+			}
+			got := make([]violation, len(verrs))
+			for i, verr := range verrs {
+				got[i] = violation{verr.Pointer, verr.Rule}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("CheckErrorCodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	s := errorCodeTestSchema(t,
+		`[{"code": 2, "message": "x"}, {"$ref": "#/components/errors/e"}]`,
+		`[{"$ref": "#/components/errors/e"}, {"code": 2, "message": "y"}]`,
+		`{"e": {"code": 1, "message": "z"}, "unused": {"code": 3, "message": "w"}}`,
+	)
+
+	want := []*ErrorCodeEntry{
+		{Code: 1, Messages: []string{"z"}, Methods: []string{"a", "b"}, Pointers: []string{"/components/errors/e"}},
+		{Code: 2, Messages: []string{"x", "y"}, Methods: []string{"a", "b"}, Pointers: []string{"/methods/0/errors/0", "/methods/1/errors/1"}},
+		{Code: 3, Messages: []string{"w"}, Pointers: []string{"/components/errors/unused"}},
+	}
+	if got := s.ErrorCodes(); !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		t.Fatalf("ErrorCodes() = %s, want %s", gotJSON, wantJSON)
+	}
+}
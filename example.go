@@ -0,0 +1,204 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"strconv"
+)
+
+// The rules of the example checks reported as ValidationError.Rule, in addition to the JSON Schema keywords of the
+// failing example values.
+const (
+	// RuleExampleMissingParam is the rule that the example pairing MUST have all the required params of the method.
+	RuleExampleMissingParam = "example-missing-param"
+
+	// RuleExampleExtraParam is the rule that the example pairing MUST NOT have the params which the method does not have.
+	RuleExampleExtraParam = "example-extra-param"
+
+	// RuleInvalidSchema is the rule that the schema of the content descriptor MUST be compiled to validate the examples.
+	RuleInvalidSchema = "invalid-schema"
+)
+
+// CheckExamples checks the example pairings of the methods against the params and the result of the methods,
+// and returns every violation as ValidationErrors, or nil if all the examples match.
+//
+// The example params are paired with the params of the method by position or by name, depending on Method.ParamStructure.
+// The "either" method, including the method whose ParamStructure is unset, pairs them by name if every example param
+// is named after the param of the method, and by position otherwise.
+// Each Example.Value is validated against the schema of the paired content descriptor.
+// The examples without the value, such as the ones with ExternalValue, are not validated.
+//
+// The violations of the example pairings and the examples referred from the Components are located in the Components.
+func (s *Schema) CheckExamples() error {
+	c := &checker{schema: s}
+	for i, m := range s.Methods {
+		if m == nil {
+			continue
+		}
+		for j, e := range m.Examples {
+			c.checkExamplePairing(m, e, []string{"methods", strconv.Itoa(i), "examples", strconv.Itoa(j)})
+		}
+	}
+
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+func (c *checker) checkExamplePairing(m *Method, e *ExamplePairingOrReference, path []string) {
+	pairing, path := c.examplePairing(e, path)
+	if pairing == nil {
+		return
+	}
+
+	params := make([]*ContentDescriptor, len(m.Params))
+	for i, p := range m.Params {
+		params[i] = c.contentDescriptor(p)
+	}
+	examples := make([]*Example, len(pairing.Params))
+	examplePaths := make([][]string, len(pairing.Params))
+	for i, p := range pairing.Params {
+		examples[i], examplePaths[i] = c.example(p, appendPath(path, "params", strconv.Itoa(i)))
+	}
+
+	byName := false
+	switch m.ParamStructure.OrDefault() {
+	case ByName:
+		byName = true
+	case Either:
+		byName = namedAfterParams(examples, params)
+	}
+
+	paired := make([]bool, len(params))
+	for i, ex := range examples {
+		if ex == nil {
+			continue
+		}
+
+		k := -1
+		if byName {
+			for j, cd := range params {
+				if cd != nil && cd.Name == ex.Name {
+					k = j
+					break
+				}
+			}
+		} else if i < len(params) {
+			k = i
+		}
+		if k < 0 {
+			if byName {
+				c.report(RuleExampleExtraParam, examplePaths[i], "example param %q is not a param of the method %q", ex.Name, m.Name)
+			} else {
+				c.report(RuleExampleExtraParam, examplePaths[i], "example param %d is out of the %d params of the method %q", i, len(params), m.Name)
+			}
+			continue
+		}
+
+		paired[k] = true
+		c.checkExample(ex, params[k], "param", examplePaths[i])
+	}
+
+	for i, cd := range params {
+		if cd != nil && cd.Required && !paired[i] {
+			c.report(RuleExampleMissingParam, appendPath(path, "params"), "example pairing %q is missing the required param %q", pairing.Name, cd.Name)
+		}
+	}
+
+	if pairing.Result != nil && m.Result != nil {
+		ex, exPath := c.example(pairing.Result, appendPath(path, "result"))
+		if ex != nil {
+			c.checkExample(ex, c.contentDescriptor(m.Result), "result", exPath)
+		}
+	}
+}
+
+// namedAfterParams reports whether every example is named after the param.
+func namedAfterParams(examples []*Example, params []*ContentDescriptor) bool {
+	names := make(map[string]bool, len(params))
+	for _, cd := range params {
+		if cd != nil {
+			names[cd.Name] = true
+		}
+	}
+	for _, ex := range examples {
+		if ex != nil && !names[ex.Name] {
+			return false
+		}
+	}
+	return len(examples) > 0
+}
+
+// checkExample validates the value of the example ex against the schema of the content descriptor cd of the kind, "param" or "result".
+func (c *checker) checkExample(ex *Example, cd *ContentDescriptor, kind string, path []string) {
+	if cd == nil || cd.Schema == nil || ex.Value == nil {
+		return
+	}
+
+	v, err := c.validator(cd.Schema)
+	if err != nil {
+		c.report(RuleInvalidSchema, path, "cannot validate the example against the schema of the %s %q: %v", kind, cd.Name, err)
+		return
+	}
+
+	valuePath := formatPointer(appendPath(path, "value"))
+	if verrs, ok := v.Validate(ex.Value).(ValidationErrors); ok {
+		for _, err := range verrs {
+			err.Pointer = valuePath + err.Pointer
+			err.Message = kind + " " + strconv.Quote(cd.Name) + ": " + err.Message
+			c.errs = append(c.errs, err)
+		}
+	}
+}
+
+// validator returns the compiled schema s, which resolves the references to the Components of the document.
+func (c *checker) validator(s *JSONSchema) (*SchemaValidator, error) {
+	if v, ok := c.validators[s]; ok {
+		return v, nil
+	}
+
+	v, err := CompileSchema(s, WithComponents(c.schema.Components))
+	if err != nil {
+		return nil, err
+	}
+	if c.validators == nil {
+		c.validators = make(map[*JSONSchema]*SchemaValidator)
+	}
+	c.validators[s] = v
+
+	return v, nil
+}
+
+// examplePairing returns the example pairing of e and its location, which follows the reference to the Components,
+// or nil if it cannot be resolved.
+func (c *checker) examplePairing(e *ExamplePairingOrReference, path []string) (*ExamplePairing, []string) {
+	switch {
+	case e == nil:
+		return nil, nil
+	case e.IsExamplePairing():
+		return e.ExamplePairing, path
+	case e.IsReference() && c.schema.Components != nil:
+		if name, ok := componentName(e.Reference.Ref, "examplePairingObjects"); ok {
+			return c.schema.Components.ExamplePairingObjects[name], []string{"components", "examplePairingObjects", name}
+		}
+	}
+	return nil, nil
+}
+
+// example returns the example of e and its location, which follows the reference to the Components, or nil if it cannot be resolved.
+func (c *checker) example(e *ExampleOrReference, path []string) (*Example, []string) {
+	switch {
+	case e == nil:
+		return nil, nil
+	case e.IsExample():
+		return e.Example, path
+	case e.IsReference() && c.schema.Components != nil:
+		if name, ok := componentName(e.Reference.Ref, "examples"); ok {
+			return c.schema.Components.Examples[name], []string{"components", "examples", name}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// exampleTestSchema returns the document whose method "m" takes the required integer "a" and the optional string "b",
+// and has the example pairing of the params.
+func exampleTestSchema(t *testing.T, structure, params string) *Schema {
+	t.Helper()
+
+	doc := `{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"methods": [{
+			"name": "m",
+			"params": [
+				{"name": "a", "required": true, "schema": {"type": "integer"}},
+				{"$ref": "#/components/contentDescriptors/b"}
+			],
+			"result": {"name": "r", "schema": {"type": "boolean"}},`
+	if structure != "" {
+		doc += `"paramStructure": "` + structure + `",`
+	}
+	doc += `
+			"examples": [{"name": "e", "params": ` + params + `, "result": {"name": "r", "value": true}}]
+		}],
+		"components": {
+			"contentDescriptors": {"b": {"name": "b", "schema": {"type": "string"}}},
+			"examples": {"a1": {"name": "a", "value": 1}, "bad": {"name": "b", "value": 1}}
+		}
+	}`
+
+	s := new(Schema)
+	if err := json.Unmarshal([]byte(doc), s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCheckExamples(t *testing.T) {
+	type violation struct {
+		pointer, rule string
+	}
+	tests := map[string]struct {
+		structure string
+		params    string
+		want      []violation
+	}{
+		"unsetByName":         {params: `[{"name": "b", "value": "x"}, {"name": "a", "value": 1}]`},
+		"unsetByPosition":     {params: `[{"name": "x", "value": 1}, {"name": "y", "value": "x"}]`},
+		"unsetMismatch":       {params: `[{"name": "b", "value": "x"}, {"name": "z", "value": 1}]`, want: []violation{{"/methods/0/examples/0/params/0/value", "type"}, {"/methods/0/examples/0/params/1/value", "type"}}},
+		"eitherByName":        {structure: "either", params: `[{"name": "b", "value": "x"}, {"name": "a", "value": 1}]`},
+		"byNameExtra":         {structure: "by-name", params: `[{"name": "a", "value": 1}, {"name": "c", "value": 1}]`, want: []violation{{"/methods/0/examples/0/params/1", RuleExampleExtraParam}}},
+		"byNameMissing":       {structure: "by-name", params: `[{"name": "b", "value": "x"}]`, want: []violation{{"/methods/0/examples/0/params", RuleExampleMissingParam}}},
+		"byPositionExtra":     {structure: "by-position", params: `[{"name": "a", "value": 1}, {"name": "b", "value": "x"}, {"name": "c", "value": 1}]`, want: []violation{{"/methods/0/examples/0/params/2", RuleExampleExtraParam}}},
+		"byPositionMissing":   {structure: "by-position", params: `[]`, want: []violation{{"/methods/0/examples/0/params", RuleExampleMissingParam}}},
+		"byPositionMismatch":  {structure: "by-position", params: `[{"name": "b", "value": "x"}]`, want: []violation{{"/methods/0/examples/0/params/0/value", "type"}}},
+		"reference":           {params: `[{"$ref": "#/components/examples/a1"}]`},
+		"invalidReference":    {params: `[{"$ref": "#/components/examples/a1"}, {"$ref": "#/components/examples/bad"}]`, want: []violation{{"/components/examples/bad/value", "type"}}},
+		"unresolvedReference": {params: `[{"$ref": "#/components/examples/none"}, {"name": "a", "value": 1}]`},
+		"externalValue":       {params: `[{"name": "a", "externalValue": "https://example.com/a.json"}]`},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			err := exampleTestSchema(t, tt.structure, tt.params).CheckExamples()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("CheckExamples() = %v", err)
+				}
+				return
+			}
+
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("CheckExamples() = %v, want ValidationErrors", err)
+			}
+			got := make([]violation, len(verrs))
+			for i, verr := range verrs {
+				got[i] = violation{verr.Pointer, verr.Rule}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("CheckExamples() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckExamplesResult(t *testing.T) {
+	s := exampleTestSchema(t, "", `[{"name": "a", "value": 1}]`)
+	s.Methods[0].Examples[0].ExamplePairing.Result.Example.Value = "true"
+
+	var verrs ValidationErrors
+	if err := s.CheckExamples(); !errors.As(err, &verrs) || len(verrs) != 1 {
+		t.Fatalf("CheckExamples() = %v, want the result violation", err)
+	}
+	if got, want := verrs[0].Pointer, "/methods/0/examples/0/result/value"; got != want {
+		t.Fatalf("Pointer = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// extensionPrefix is the prefix of the specification extensions patterned field names.
+const extensionPrefix = "x-"
+
+// isExtension reports whether the name is a specification extensions patterned field name.
+func isExtension(name string) bool {
+	return strings.HasPrefix(name, extensionPrefix)
+}
+
+// NewExtension returns the new Extension with the name and the JSON encoding of v.
+func NewExtension(name string, v interface{}) (*Extension, error) {
+	if !isExtension(name) {
+		return nil, fmt.Errorf("openrpc: extension name %q must begin with %q", name, extensionPrefix)
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("openrpc: marshal %s extension: %w", name, err)
+	}
+
+	return &Extension{Name: name, Value: value}, nil
+}
+
+// Decode decodes the extension value into v.
+func (e *Extension) Decode(v interface{}) error {
+	return json.Unmarshal(e.Value, v)
+}
+
+// LookupExtension returns the extension whose name is name, or nil if exts does not contain it.
+func LookupExtension(exts []*Extension, name string) *Extension {
+	for _, ext := range exts {
+		if ext != nil && ext.Name == name {
+			return ext
+		}
+	}
+
+	return nil
+}
+
+// marshalObject returns the JSON encoding of the struct v followed by the exts patterned fields in order.
+//
+// v must not implement json.Marshaler by itself, it is usually a method-less alias of the extensible type.
+func marshalObject(v interface{}, exts []*Extension) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(exts) == 0 {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	buf.Write(data[:len(data)-1]) // trim '}'
+	needComma := len(bytes.TrimSpace(data[1:len(data)-1])) > 0
+	for _, ext := range exts {
+		if ext == nil {
+			continue
+		}
+		if !isExtension(ext.Name) {
+			return nil, fmt.Errorf("openrpc: extension name %q must begin with %q", ext.Name, extensionPrefix)
+		}
+
+		if needComma {
+			buf.WriteByte(',')
+		}
+		needComma = true
+
+		name, err := json.Marshal(ext.Name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+
+		if len(ext.Value) == 0 {
+			buf.WriteString("null")
+			continue
+		}
+		if err := json.Compact(&buf, ext.Value); err != nil {
+			return nil, fmt.Errorf("openrpc: invalid %s extension value: %w", ext.Name, err)
+		}
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalObject decodes the JSON object data into the struct v and collects any "x-" patterned fields into exts in document order.
+//
+// v must not implement json.Unmarshaler by itself, it is usually a method-less alias of the extensible type.
+func unmarshalObject(data []byte, v interface{}, exts *[]*Extension) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	*exts = nil
+	return scanObject(data, func(key string, value json.RawMessage) error {
+		if isExtension(key) {
+			*exts = append(*exts, &Extension{Name: key, Value: value})
+		}
+		return nil
+	})
+}
+
+// scanObject calls fn for each member of the JSON object data in document order.
+func scanObject(data []byte, fn func(key string, value json.RawMessage) error) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("openrpc: expected JSON object, got %v", tok)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("openrpc: expected JSON object key, got %v", tok)
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
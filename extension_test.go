@@ -0,0 +1,137 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtensionRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		v    interface{}
+		data string
+	}{
+		"schema": {
+			v:    new(Schema),
+			data: `{"openrpc":"1.2.6","info":{"title":"t","version":"1","x-info":true},"methods":[],"x-b":{"z":1,"a":[1,2]},"x-a":null}`,
+		},
+		"method": {
+			v:    new(Method),
+			data: `{"name":"m","params":[],"result":{"name":"r","schema":{"type":"string","x-schema":"s"}},"x-method":"v"}`,
+		},
+		"tag":         {v: new(Tag), data: `{"name":"t","x-tag":[]}`},
+		"noExtension": {v: new(Tag), data: `{"name":"t"}`},
+		"onlyExtension": {
+			v:    new(Contact),
+			data: `{"x-only":{}}`,
+		},
+		"jsonSchema": {v: new(JSONSchema), data: `{"x-a":1}`},
+		"link":       {v: new(Link), data: `{"name":"l","params":{"a":"$params.a","b":1},"x-link":"l"}`},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.data), tt.v); err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.data {
+				t.Fatalf("round trip = %s, want %s", got, tt.data)
+			}
+		})
+	}
+}
+
+func TestExtensionFields(t *testing.T) {
+	s := new(Schema)
+	data := `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": [], "x-b": {"k": "v"}, "x-a": 1, "notExtension": 2}`
+	if err := json.Unmarshal([]byte(data), s); err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, len(s.Extensions))
+	for i, ext := range s.Extensions {
+		names[i] = ext.Name
+	}
+	if want := []string{"x-b", "x-a"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("extensions = %q, want %q in the document order", names, want)
+	}
+
+	var v map[string]string
+	if err := LookupExtension(s.Extensions, "x-b").Decode(&v); err != nil || v["k"] != "v" {
+		t.Fatalf("Decode = %v, %v", v, err)
+	}
+	if ext := LookupExtension(s.Extensions, "x-none"); ext != nil {
+		t.Fatalf("LookupExtension of the missing name = %v", ext)
+	}
+	if ext := LookupExtension([]*Extension{nil}, "x-a"); ext != nil {
+		t.Fatalf("LookupExtension of the nil extension = %v", ext)
+	}
+
+	// the null document keeps the extensions
+	if err := json.Unmarshal([]byte(`null`), s); err != nil || len(s.Extensions) != 2 {
+		t.Fatalf("null unmarshal = %v, %d extensions", err, len(s.Extensions))
+	}
+}
+
+func TestNewExtension(t *testing.T) {
+	ext, err := NewExtension("x-a", map[string]int{"b": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext.Name != "x-a" || string(ext.Value) != `{"b":1}` {
+		t.Fatalf("NewExtension = %s: %s", ext.Name, ext.Value)
+	}
+
+	tests := map[string]struct {
+		name  string
+		value interface{}
+		msg   string
+	}{
+		"name":  {name: "a", value: 1, msg: `extension name "a" must begin with "x-"`},
+		"value": {name: "x-a", value: func() {}, msg: "marshal x-a extension"},
+	}
+	for name, tt := range tests {
+		if _, err := NewExtension(tt.name, tt.value); err == nil || !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%s: NewExtension = %v, want the error containing %q", name, err, tt.msg)
+		}
+	}
+}
+
+func TestMarshalExtension(t *testing.T) {
+	tests := map[string]struct {
+		exts    []*Extension
+		want    string
+		wantErr string
+	}{
+		"nil":          {exts: []*Extension{nil, {Name: "x-a", Value: json.RawMessage(`1`)}}, want: `{"name":"t","x-a":1}`},
+		"emptyValue":   {exts: []*Extension{{Name: "x-a"}}, want: `{"name":"t","x-a":null}`},
+		"compacted":    {exts: []*Extension{{Name: "x-a", Value: json.RawMessage("{ \"b\" :\n 1 }")}}, want: `{"name":"t","x-a":{"b":1}}`},
+		"invalidName":  {exts: []*Extension{{Name: "a", Value: json.RawMessage(`1`)}}, wantErr: "must begin with"},
+		"invalidValue": {exts: []*Extension{{Name: "x-a", Value: json.RawMessage(`{`)}}, wantErr: "invalid x-a extension value"},
+	}
+	for name, tt := range tests {
+		got, err := json.Marshal(&Tag{Name: "t", Extensions: tt.exts})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: Marshal = %v, want the error containing %q", name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: Marshal = %s, %v, want %s", name, got, err, tt.want)
+		}
+	}
+
+	if _, err := json.Marshal(&JSONSchema{Schema: mustJSONSchema(t, `true`).Schema, Extensions: []*Extension{{Name: "x-a"}}}); err == nil {
+		t.Error("Marshal of the boolean schema with the extensions succeeded")
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/zchee/go-openrpc
 
-go 1.14
+go 1.16
+
+require gopkg.in/yaml.v3 v3.0.1
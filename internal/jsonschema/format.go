@@ -0,0 +1,178 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatChecker reports whether the string is valid in the format of the "format" keyword.
+type FormatChecker func(s string) bool
+
+// draft7Formats are the checkers of the formats defined by the JSON Schema Draft 7 validation specification.
+var draft7Formats = map[string]FormatChecker{
+	"date-time":             isDateTime,
+	"date":                  isDate,
+	"time":                  isTime,
+	"email":                 isEmail,
+	"idn-email":             isEmail,
+	"hostname":              isHostname,
+	"idn-hostname":          isIDNHostname,
+	"ipv4":                  isIPv4,
+	"ipv6":                  isIPv6,
+	"uri":                   isURI,
+	"uri-reference":         isURIReference,
+	"iri":                   isURI,
+	"iri-reference":         isURIReference,
+	"uri-template":          isURITemplate,
+	"json-pointer":          isJSONPointer,
+	"relative-json-pointer": isRelativeJSONPointer,
+	"regex":                 isRegex,
+}
+
+// isDateTime reports whether s is the RFC 3339 date-time.
+func isDateTime(s string) bool {
+	i := strings.IndexAny(s, "Tt")
+	return i >= 0 && isDate(s[:i]) && isTime(s[i+1:])
+}
+
+// isDate reports whether s is the RFC 3339 full-date.
+func isDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+// isTime reports whether s is the RFC 3339 full-time.
+func isTime(s string) bool {
+	_, err := time.Parse("15:04:05.999999999Z07:00", strings.ToUpper(s))
+	return err == nil
+}
+
+// isEmail reports whether s is the RFC 5322 addr-spec.
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+
+var hostnameLabel = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+
+// isHostname reports whether s is the RFC 1123 hostname.
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if !hostnameLabel.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIDNHostname reports whether s is the internationalized hostname, which allows the non-ASCII labels.
+func isIDNHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if r < 0x80 && !(r == '-' || r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isIPv4 reports whether s is the dotted-quad IPv4 address.
+func isIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && strings.Count(s, ".") == 3 && !strings.Contains(s, ":")
+}
+
+// isIPv6 reports whether s is the RFC 4291 IPv6 address.
+func isIPv6(s string) bool {
+	return net.ParseIP(s) != nil && strings.Contains(s, ":")
+}
+
+// isURI reports whether s is the absolute URI.
+func isURI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs()
+}
+
+// isURIReference reports whether s is the URI or the relative reference.
+func isURIReference(s string) bool {
+	_, err := url.Parse(s)
+	return err == nil && !strings.Contains(s, `\`)
+}
+
+// isURITemplate reports whether s is the RFC 6570 URI template with the balanced expressions.
+func isURITemplate(s string) bool {
+	open := false
+	for _, c := range s {
+		switch c {
+		case '{':
+			if open {
+				return false
+			}
+			open = true
+		case '}':
+			if !open {
+				return false
+			}
+			open = false
+		}
+	}
+	return !open
+}
+
+// isJSONPointer reports whether s is the RFC 6901 JSON Pointer.
+func isJSONPointer(s string) bool {
+	if s != "" && !strings.HasPrefix(s, "/") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '~' && (i+1 >= len(s) || (s[i+1] != '0' && s[i+1] != '1')) {
+			return false
+		}
+	}
+	return true
+}
+
+// isRelativeJSONPointer reports whether s is the relative JSON Pointer.
+func isRelativeJSONPointer(s string) bool {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 || (i > 1 && s[0] == '0') {
+		return false
+	}
+	if _, err := strconv.Atoi(s[:i]); err != nil {
+		return false
+	}
+	return s[i:] == "#" || isJSONPointer(s[i:])
+}
+
+// isRegex reports whether s is the regular expression.
+//
+// The s is checked by the RE2 syntax of the regexp package, so the ECMA-262 regular expression which RE2 does not
+// support, such as the lookaround and the backreference, is not valid.
+func isRegex(s string) bool {
+	_, err := regexp.Compile(s)
+	return err == nil
+}
@@ -0,0 +1,250 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// firstByte returns the first non-whitespace byte of data, or 0 if data is empty.
+func firstByte(data []byte) byte {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return 0
+	}
+	return data[0]
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	// the unset additionalItems and additionalProperties are the absence of the keywords
+	if s.AdditionalItems != nil && s.AdditionalItems.IsZero() {
+		s.AdditionalItems = nil
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.IsZero() {
+		s.AdditionalProperties = nil
+	}
+
+	type schema Schema
+	data, err := json.Marshal(schema(s))
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Bool != nil {
+		if !bytes.Equal(data, []byte("{}")) {
+			return nil, fmt.Errorf("jsonschema: boolean schema must not have keywords: %s", data)
+		}
+		return json.Marshal(*s.Bool)
+	}
+
+	return data, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// The Draft 4 forms of "id" and the boolean "exclusiveMaximum" and "exclusiveMinimum" are converted to the Draft 7 keywords.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch firstByte(data) {
+	case 'n':
+		return nil
+	case 't', 'f':
+		var b bool
+		if err := json.Unmarshal(data, &b); err != nil {
+			return err
+		}
+		*s = Schema{Bool: &b}
+		return nil
+	case '{':
+		// nothing to do
+	default:
+		return fmt.Errorf("jsonschema: schema must be a JSON object or boolean, got %s", data)
+	}
+
+	type schema Schema
+	var v struct {
+		schema
+		LegacyID         string          `json:"id,omitempty"`
+		ExclusiveMaximum json.RawMessage `json:"exclusiveMaximum,omitempty"`
+		ExclusiveMinimum json.RawMessage `json:"exclusiveMinimum,omitempty"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = Schema(v.schema)
+
+	if s.ID == "" {
+		s.ID = v.LegacyID
+	}
+	var err error
+	if s.Maximum, s.ExclusiveMaximum, err = exclusiveBound("exclusiveMaximum", v.ExclusiveMaximum, s.Maximum); err != nil {
+		return err
+	}
+	if s.Minimum, s.ExclusiveMinimum, err = exclusiveBound("exclusiveMinimum", v.ExclusiveMinimum, s.Minimum); err != nil {
+		return err
+	}
+
+	// keep the explicit null value of the "const" and "default" keywords
+	if s.Const == nil || s.Default == nil {
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return err
+		}
+		if _, ok := keys["const"]; ok && s.Const == nil {
+			s.Const = new(JSON)
+		}
+		if _, ok := keys["default"]; ok && s.Default == nil {
+			s.Default = new(JSON)
+		}
+	}
+
+	return nil
+}
+
+// exclusiveBound returns the inclusive and exclusive bound from the raw exclusive keyword value.
+//
+// The numeric raw value is the Draft 7 form. The boolean raw value is the Draft 4 form which makes the inclusive bound exclusive.
+func exclusiveBound(keyword string, raw json.RawMessage, bound *float64) (inclusive, exclusive *float64, err error) {
+	switch firstByte(raw) {
+	case 0, 'n':
+		return bound, nil, nil
+	case 't', 'f':
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, nil, err
+		}
+		if b {
+			return nil, bound, nil
+		}
+		return bound, nil, nil
+	default:
+		exclusive = new(float64)
+		if err := json.Unmarshal(raw, exclusive); err != nil {
+			return nil, nil, fmt.Errorf("jsonschema: invalid %s value: %w", keyword, err)
+		}
+		return bound, exclusive, nil
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Types) UnmarshalJSON(data []byte) error {
+	switch firstByte(data) {
+	case 'n':
+		return nil
+	case '[':
+		return json.Unmarshal(data, (*[]string)(t))
+	default:
+		var typ string
+		if err := json.Unmarshal(data, &typ); err != nil {
+			return err
+		}
+		*t = Types{typ}
+		return nil
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p PropsOrArray) MarshalJSON() ([]byte, error) {
+	if p.Schema != nil {
+		return json.Marshal(p.Schema)
+	}
+	if p.JSONSchemas == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p.JSONSchemas)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *PropsOrArray) UnmarshalJSON(data []byte) error {
+	switch firstByte(data) {
+	case 'n':
+		return nil
+	case '[':
+		p.Schema = nil
+		return json.Unmarshal(data, &p.JSONSchemas)
+	default:
+		p.JSONSchemas = nil
+		p.Schema = new(Schema)
+		return json.Unmarshal(data, p.Schema)
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// The zero value is encoded as `true` which is the default.
+func (p PropsOrBool) MarshalJSON() ([]byte, error) {
+	if p.Schema != nil {
+		return json.Marshal(p.Schema)
+	}
+	return []byte("true"), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *PropsOrBool) UnmarshalJSON(data []byte) error {
+	switch firstByte(data) {
+	case 'n':
+		return nil
+	case 't', 'f':
+		var allows bool
+		if err := json.Unmarshal(data, &allows); err != nil {
+			return err
+		}
+		*p = *NewPropsOrBool(allows)
+		return nil
+	default:
+		p.Allows = true
+		p.Schema = new(Schema)
+		return json.Unmarshal(data, p.Schema)
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p PropsOrStringArray) MarshalJSON() ([]byte, error) {
+	if p.Schema != nil {
+		return json.Marshal(p.Schema)
+	}
+	if p.Property == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p.Property)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *PropsOrStringArray) UnmarshalJSON(data []byte) error {
+	switch firstByte(data) {
+	case 'n':
+		return nil
+	case '[':
+		p.Schema = nil
+		return json.Unmarshal(data, &p.Property)
+	default:
+		p.Property = nil
+		p.Schema = new(Schema)
+		return json.Unmarshal(data, p.Schema)
+	}
+}
+
+var (
+	_ json.Marshaler   = Types{}
+	_ json.Unmarshaler = (*Types)(nil)
+	_ json.Marshaler   = Schema{}
+	_ json.Unmarshaler = (*Schema)(nil)
+	_ json.Marshaler   = PropsOrArray{}
+	_ json.Unmarshaler = (*PropsOrArray)(nil)
+	_ json.Marshaler   = PropsOrBool{}
+	_ json.Unmarshaler = (*PropsOrBool)(nil)
+	_ json.Marshaler   = PropsOrStringArray{}
+	_ json.Unmarshaler = (*PropsOrStringArray)(nil)
+)
@@ -0,0 +1,285 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// jsonEqual reports whether the JSON texts a and b encode the same value.
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("unmarshal %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("unmarshal %s: %v", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func TestSchemaJSONKeywords(t *testing.T) {
+	s := &Schema{
+		Schema:    "http://json-schema.org/draft-07/schema#",
+		Type:      Types{TypeObject},
+		Required:  []string{"a"},
+		MaxLength: new(int64),
+		Properties: map[string]Schema{
+			"a": {Type: Types{TypeString, TypeNull}},
+		},
+		AdditionalProperties: &PropsOrBool{Allows: true, Schema: &Schema{Type: Types{TypeInteger}}},
+		Items:                &PropsOrArray{Schema: &Schema{Type: Types{TypeNumber}}},
+		Dependencies:         Dependencies{"a": {Property: []string{"b"}}},
+	}
+	got, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"required": ["a"],
+		"maxLength": 0,
+		"properties": {"a": {"type": ["string", "null"]}},
+		"additionalProperties": {"type": "integer"},
+		"items": {"type": "number"},
+		"dependencies": {"a": ["b"]}
+	}`
+	if !jsonEqual(t, got, []byte(want)) {
+		t.Fatalf("Marshal = %s, want %s", got, want)
+	}
+}
+
+func TestSchemaJSONRoundTrip(t *testing.T) {
+	tests := map[string]string{
+		"empty":        `{}`,
+		"ref":          `{"$ref":"#/definitions/a","definitions":{"a":{"type":"string"}}}`,
+		"itemsSchema":  `{"items":{"type":"string"}}`,
+		"itemsArray":   `{"items":[{"type":"string"},{"type":"integer"}],"additionalItems":{"type":"null"}}`,
+		"itemsEmpty":   `{"items":[]}`,
+		"additional":   `{"additionalProperties":{"minProperties":1}}`,
+		"dependencies": `{"dependencies":{"a":{"required":["b"]},"c":["d","e"],"f":[]}}`,
+		"types":        `{"type":["object","null"]}`,
+		"nested":       `{"allOf":[{"anyOf":[{"oneOf":[{"not":{"enum":[1,"a",null]}}]}]}]}`,
+		"patterns":     `{"patternProperties":{"^x-":{}},"pattern":"^[a-z]+$"}`,
+	}
+	for name, data := range tests {
+		s := new(Schema)
+		if err := json.Unmarshal([]byte(data), s); err != nil {
+			t.Errorf("%s: Unmarshal: %v", name, err)
+			continue
+		}
+		got, err := json.Marshal(s)
+		if err != nil {
+			t.Errorf("%s: Marshal: %v", name, err)
+			continue
+		}
+		if !jsonEqual(t, got, []byte(data)) {
+			t.Errorf("%s: round trip = %s, want %s", name, got, data)
+		}
+	}
+}
+
+func TestSchemaJSONUnions(t *testing.T) {
+	s := mustSchema(t, `{
+		"items": [{"type": "string"}],
+		"additionalItems": false,
+		"additionalProperties": true,
+		"dependencies": {"a": ["b"], "c": {"type": "object"}}
+	}`)
+	if s.Items.Schema != nil || len(s.Items.JSONSchemas) != 1 {
+		t.Errorf("items = %+v, want the array", s.Items)
+	}
+	if !s.AdditionalItems.Denies() {
+		t.Errorf("additionalItems = %+v, want false", s.AdditionalItems)
+	}
+	if !s.AdditionalProperties.Allows || s.AdditionalProperties.Schema != nil {
+		t.Errorf("additionalProperties = %+v, want true", s.AdditionalProperties)
+	}
+	if dep := s.Dependencies["a"]; dep.Schema != nil || !reflect.DeepEqual(dep.Property, []string{"b"}) {
+		t.Errorf("dependencies/a = %+v, want the property array", dep)
+	}
+	if dep := s.Dependencies["c"]; dep.Schema == nil || dep.Property != nil {
+		t.Errorf("dependencies/c = %+v, want the schema", dep)
+	}
+
+	// the union is reset by the unmarshal of the other variant
+	items := &PropsOrArray{Schema: &Schema{}}
+	if err := json.Unmarshal([]byte(`[{}]`), items); err != nil || items.Schema != nil || len(items.JSONSchemas) != 1 {
+		t.Errorf("PropsOrArray = %+v, %v", items, err)
+	}
+	dep := &PropsOrStringArray{Property: []string{"a"}}
+	if err := json.Unmarshal([]byte(`{}`), dep); err != nil || dep.Schema == nil || dep.Property != nil {
+		t.Errorf("PropsOrStringArray = %+v, %v", dep, err)
+	}
+}
+
+func TestSchemaJSONError(t *testing.T) {
+	tests := map[string]string{
+		"string":        `"a"`,
+		"number":        `1`,
+		"array":         `[]`,
+		"nestedString":  `{"properties": {"a": "b"}}`,
+		"invalidType":   `{"type": 1}`,
+		"invalidItems":  `{"items": 1}`,
+		"invalidNested": `{"not": [1]}`,
+	}
+	for name, data := range tests {
+		if err := json.Unmarshal([]byte(data), new(Schema)); err == nil {
+			t.Errorf("%s: Unmarshal(%s) succeeded", name, data)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`"a"`), new(Schema)); err == nil || !strings.Contains(err.Error(), "must be a JSON object or boolean") {
+		t.Errorf("Unmarshal of the string = %v", err)
+	}
+}
+
+func TestSchemaJSONDraft7(t *testing.T) {
+	data := `{
+		"$id": "https://example.com/s.json",
+		"$comment": "c",
+		"const": "a",
+		"contains": {"type": "integer"},
+		"propertyNames": {"maxLength": 3},
+		"if": {"required": ["a"]},
+		"then": {"required": ["b"]},
+		"else": {"required": ["c"]},
+		"readOnly": true,
+		"writeOnly": true,
+		"contentMediaType": "application/json",
+		"contentEncoding": "base64",
+		"examples": [1, "a"],
+		"exclusiveMaximum": 10,
+		"exclusiveMinimum": 0.5
+	}`
+	s := mustSchema(t, data)
+	if s.ID != "https://example.com/s.json" || s.Contains == nil || s.PropertyNames == nil || s.If == nil || s.Then == nil || s.Else == nil {
+		t.Fatalf("Unmarshal = %+v", s)
+	}
+	if s.ExclusiveMaximum == nil || *s.ExclusiveMaximum != 10 || s.ExclusiveMinimum == nil || *s.ExclusiveMinimum != 0.5 {
+		t.Fatalf("exclusive bounds = %v, %v", s.ExclusiveMaximum, s.ExclusiveMinimum)
+	}
+
+	got, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !jsonEqual(t, got, []byte(data)) {
+		t.Fatalf("round trip = %s, want %s", got, data)
+	}
+}
+
+func TestSchemaJSONDraft4(t *testing.T) {
+	tests := map[string]struct {
+		data, want string
+	}{
+		"id":                {data: `{"id": "a.json"}`, want: `{"$id": "a.json"}`},
+		"idAndDollarID":     {data: `{"id": "a.json", "$id": "b.json"}`, want: `{"$id": "b.json"}`},
+		"exclusiveMaximum":  {data: `{"maximum": 5, "exclusiveMaximum": true}`, want: `{"exclusiveMaximum": 5}`},
+		"inclusiveMaximum":  {data: `{"maximum": 5, "exclusiveMaximum": false}`, want: `{"maximum": 5}`},
+		"exclusiveMinimum":  {data: `{"minimum": 1, "exclusiveMinimum": true}`, want: `{"exclusiveMinimum": 1}`},
+		"exclusiveWithout":  {data: `{"exclusiveMinimum": true}`, want: `{}`},
+		"nullExclusive":     {data: `{"minimum": 1, "exclusiveMinimum": null}`, want: `{"minimum": 1}`},
+		"numericAndMaximum": {data: `{"maximum": 5, "exclusiveMaximum": 4}`, want: `{"maximum": 5, "exclusiveMaximum": 4}`},
+		"nullConst":         {data: `{"const": null}`, want: `{"const": null}`},
+		"nullDefault":       {data: `{"default": null}`, want: `{"default": null}`},
+	}
+	for name, tt := range tests {
+		got, err := json.Marshal(mustSchema(t, tt.data))
+		if err != nil {
+			t.Errorf("%s: Marshal: %v", name, err)
+			continue
+		}
+		if !jsonEqual(t, got, []byte(tt.want)) {
+			t.Errorf("%s: %s is encoded as %s, want %s", name, tt.data, got, tt.want)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"exclusiveMaximum": "a"}`), new(Schema)); err == nil || !strings.Contains(err.Error(), "invalid exclusiveMaximum") {
+		t.Errorf("Unmarshal of the invalid exclusiveMaximum = %v", err)
+	}
+}
+
+func TestSchemaJSONBool(t *testing.T) {
+	tests := map[string]string{
+		"true":                 `true`,
+		"false":                `false`,
+		"items":                `{"items":false}`,
+		"tupleItems":           `{"additionalItems":false,"items":[true,false]}`,
+		"additionalProperties": `{"additionalProperties":false}`,
+		"properties":           `{"properties":{"a":true,"b":false}}`,
+		"allOf":                `{"allOf":[true,{"not":false}]}`,
+		"definitions":          `{"definitions":{"never":false}}`,
+		"dependencies":         `{"dependencies":{"a":false}}`,
+	}
+	for name, data := range tests {
+		s := new(Schema)
+		if err := json.Unmarshal([]byte(data), s); err != nil {
+			t.Errorf("%s: Unmarshal: %v", name, err)
+			continue
+		}
+		got, err := json.Marshal(s)
+		if err != nil {
+			t.Errorf("%s: Marshal: %v", name, err)
+			continue
+		}
+		if string(got) != data {
+			t.Errorf("%s: round trip = %s, want %s", name, got, data)
+		}
+	}
+
+	s := mustSchema(t, `{"items": false, "properties": {"a": true}, "additionalProperties": false}`)
+	if !s.Items.Schema.IsBool() || *s.Items.Schema.Bool {
+		t.Errorf("items = %+v, want false", s.Items.Schema)
+	}
+	if a := s.Properties["a"]; !a.IsBool() || !*a.Bool {
+		t.Errorf("properties/a = %+v, want true", a)
+	}
+	if !s.AdditionalProperties.Denies() {
+		t.Errorf("additionalProperties = %+v, want false", s.AdditionalProperties)
+	}
+}
+
+func TestPropsOrBoolJSON(t *testing.T) {
+	tests := map[string]struct {
+		p    *PropsOrBool
+		want string
+	}{
+		"unset":  {p: &PropsOrBool{}, want: `{}`},
+		"true":   {p: NewPropsOrBool(true), want: `{"additionalProperties":true}`},
+		"false":  {p: NewPropsOrBool(false), want: `{"additionalProperties":false}`},
+		"schema": {p: &PropsOrBool{Allows: true, Schema: &Schema{Type: Types{TypeString}}}, want: `{"additionalProperties":{"type":"string"}}`},
+	}
+	for name, tt := range tests {
+		got, err := json.Marshal(&Schema{AdditionalProperties: tt.p})
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: Marshal = %s, %v, want %s", name, got, err, tt.want)
+		}
+		if tt.p.IsZero() != (name == "unset") {
+			t.Errorf("%s: IsZero() = %t", name, tt.p.IsZero())
+		}
+	}
+
+	var p PropsOrBool
+	if err := json.Unmarshal([]byte(`null`), &p); err != nil || !p.IsZero() {
+		t.Errorf("Unmarshal of null = %+v, %v", p, err)
+	}
+}
+
+func TestSchemaJSONBoolError(t *testing.T) {
+	b := true
+	if _, err := json.Marshal(&Schema{Bool: &b, Type: Types{TypeString}}); err == nil || !strings.Contains(err.Error(), "boolean schema must not have keywords") {
+		t.Errorf("Marshal of the boolean schema with the keywords = %v", err)
+	}
+	if err := json.Unmarshal([]byte(`tru`), new(Schema)); err == nil {
+		t.Error("Unmarshal of the invalid boolean succeeded")
+	}
+}
@@ -1,44 +1,73 @@
 package jsonschema
 
-// Schema is a JSON-Schema following Specification Draft 4 (http://json-schema.org/).
+// Schema is a JSON-Schema following Specification Draft 7 (http://json-schema.org/).
 type Schema struct {
-	ID                   string
-	Schema               URL
-	Ref                  *string
-	Description          string
-	Type                 string
-	Nullable             bool
-	Format               string
-	Title                string
-	Default              *JSON
-	Maximum              *float64
-	ExclusiveMaximum     bool
-	Minimum              *float64
-	ExclusiveMinimum     bool
-	MaxLength            *int64
-	MinLength            *int64
-	Pattern              string
-	MaxItems             *int64
-	MinItems             *int64
-	UniqueItems          bool
-	MultipleOf           *float64
-	Enum                 []JSON
-	MaxProperties        *int64
-	MinProperties        *int64
-	Required             []string
-	Items                *PropsOrArray
-	AllOf                []Schema
-	OneOf                []Schema
-	AnyOf                []Schema
-	Not                  *Schema
-	Properties           map[string]Schema
-	AdditionalProperties *PropsOrBool
-	PatternProperties    map[string]Schema
-	Dependencies         Dependencies
-	AdditionalItems      *PropsOrBool
-	Definitions          Definitions
-	ExternalDocs         *ExternalDocumentation
-	Example              *JSON
+	ID                   string            `json:"$id,omitempty"`
+	Schema               URL               `json:"$schema,omitempty"`
+	Ref                  *string           `json:"$ref,omitempty"`
+	Comment              string            `json:"$comment,omitempty"`
+	Title                string            `json:"title,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	Default              *JSON             `json:"default,omitempty"`
+	ReadOnly             bool              `json:"readOnly,omitempty"`
+	WriteOnly            bool              `json:"writeOnly,omitempty"`
+	Examples             []JSON            `json:"examples,omitempty"`
+	MultipleOf           *float64          `json:"multipleOf,omitempty"`
+	Maximum              *float64          `json:"maximum,omitempty"`
+	ExclusiveMaximum     *float64          `json:"exclusiveMaximum,omitempty"`
+	Minimum              *float64          `json:"minimum,omitempty"`
+	ExclusiveMinimum     *float64          `json:"exclusiveMinimum,omitempty"`
+	MaxLength            *int64            `json:"maxLength,omitempty"`
+	MinLength            *int64            `json:"minLength,omitempty"`
+	Pattern              string            `json:"pattern,omitempty"`
+	AdditionalItems      *PropsOrBool      `json:"additionalItems,omitempty"`
+	Items                *PropsOrArray     `json:"items,omitempty"`
+	MaxItems             *int64            `json:"maxItems,omitempty"`
+	MinItems             *int64            `json:"minItems,omitempty"`
+	UniqueItems          bool              `json:"uniqueItems,omitempty"`
+	Contains             *Schema           `json:"contains,omitempty"`
+	MaxProperties        *int64            `json:"maxProperties,omitempty"`
+	MinProperties        *int64            `json:"minProperties,omitempty"`
+	Required             []string          `json:"required,omitempty"`
+	AdditionalProperties *PropsOrBool      `json:"additionalProperties,omitempty"`
+	Definitions          Definitions       `json:"definitions,omitempty"`
+	Properties           map[string]Schema `json:"properties,omitempty"`
+	PatternProperties    map[string]Schema `json:"patternProperties,omitempty"`
+	Dependencies         Dependencies      `json:"dependencies,omitempty"`
+	PropertyNames        *Schema           `json:"propertyNames,omitempty"`
+	Const                *JSON             `json:"const,omitempty"`
+	Enum                 []JSON            `json:"enum,omitempty"`
+	Type                 Types             `json:"type,omitempty"`
+	Format               string            `json:"format,omitempty"`
+	ContentMediaType     string            `json:"contentMediaType,omitempty"`
+	ContentEncoding      string            `json:"contentEncoding,omitempty"`
+	If                   *Schema           `json:"if,omitempty"`
+	Then                 *Schema           `json:"then,omitempty"`
+	Else                 *Schema           `json:"else,omitempty"`
+	AllOf                []Schema          `json:"allOf,omitempty"`
+	AnyOf                []Schema          `json:"anyOf,omitempty"`
+	OneOf                []Schema          `json:"oneOf,omitempty"`
+	Not                  *Schema           `json:"not,omitempty"`
+
+	// OpenAPI vocabulary which is not part of the JSON Schema specification.
+	Nullable     bool                   `json:"nullable,omitempty"`
+	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty"`
+	Example      *JSON                  `json:"example,omitempty"`
+
+	// Bool is the value of the boolean schema. The schema is the literal `true` or `false` if Bool is not nil, and all other keywords MUST be empty.
+	Bool *bool `json:"-"`
+}
+
+// NewBool returns the boolean schema which is the literal `true` or `false`.
+//
+// The `true` schema always passes validation, and the `false` schema always fails validation.
+func NewBool(v bool) *Schema {
+	return &Schema{Bool: &v}
+}
+
+// IsBool reports whether the s is a boolean schema.
+func (s *Schema) IsBool() bool {
+	return s != nil && s.Bool != nil
 }
 
 // JSON represents any valid JSON value.
@@ -48,6 +77,31 @@ type JSON interface{}
 // URL represents a schema url.
 type URL string
 
+// The primitive types of the "type" keyword.
+const (
+	TypeNull    = "null"
+	TypeBoolean = "boolean"
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeNumber  = "number"
+	TypeString  = "string"
+	TypeInteger = "integer"
+)
+
+// Types represents the "type" keyword which is either a single primitive type or an array of unique primitive types.
+// Mainly here for serialization purposes.
+type Types []string
+
+// Has reports whether the t contains typ.
+func (t Types) Has(typ string) bool {
+	for _, tt := range t {
+		if tt == typ {
+			return true
+		}
+	}
+	return false
+}
+
 // PropsOrArray represents a value that can either be a JSONSchemaProps
 // or an array of JSONSchemaProps. Mainly here for serialization purposes.
 type PropsOrArray struct {
@@ -57,11 +111,32 @@ type PropsOrArray struct {
 
 // PropsOrBool represents JSONSchemaProps or a boolean value.
 // Defaults to true for the boolean property.
+//
+// The zero value is unset which allows any value, and is omitted by the encoding of the parent Schema.
+// The boolean `false` is held as the boolean schema `false` in Schema, so it is distinguishable from the zero value.
 type PropsOrBool struct {
 	Allows bool
 	Schema *Schema
 }
 
+// NewPropsOrBool returns the PropsOrBool of the boolean value allows.
+func NewPropsOrBool(allows bool) *PropsOrBool {
+	if allows {
+		return &PropsOrBool{Allows: true}
+	}
+	return &PropsOrBool{Schema: NewBool(false)}
+}
+
+// IsZero reports whether the p is unset.
+func (p PropsOrBool) IsZero() bool {
+	return !p.Allows && p.Schema == nil
+}
+
+// Denies reports whether the p is the boolean `false` which allows no value.
+func (p *PropsOrBool) Denies() bool {
+	return p != nil && p.Schema.IsBool() && !*p.Schema.Bool
+}
+
 // Dependencies represent a dependencies property.
 type Dependencies map[string]PropsOrStringArray
 
@@ -76,6 +151,6 @@ type Definitions map[string]Schema
 
 // ExternalDocumentation allows referencing an external resource for extended documentation.
 type ExternalDocumentation struct {
-	Description string
-	URL         string
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
 }
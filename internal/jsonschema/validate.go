@@ -0,0 +1,876 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError is a violation of the instance against the schema.
+type ValidationError struct {
+	// InstancePath is the JSON Pointer to the invalid value in the instance.
+	InstancePath string
+
+	// SchemaPath is the JSON Pointer to the failing keyword from the root schema, which goes through the "$ref"s.
+	SchemaPath string
+
+	// Keyword is the failing keyword such as "required" or "type".
+	Keyword string
+
+	// Message describes the violation.
+	Message string
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	path := e.InstancePath
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("jsonschema: %s: %s", path, e.Message)
+}
+
+// maxRefDepth is the maximum number of the "$ref"s followed for the single instance value, which stops the infinite recursion.
+const maxRefDepth = 1000
+
+// Validator validates the JSON values against the compiled schema.
+//
+// The Validator is safe for concurrent use.
+type Validator struct {
+	root *Schema
+	base string
+
+	// resources holds the schemas which have the absolute "$id", keyed by the URI without the empty fragment.
+	// The location-independent identifiers such as "#foo" are keyed by the URI with the fragment.
+	resources map[string]*Schema
+
+	// refs holds the resolved "$ref"s keyed by the absolute URI.
+	refs map[string]resolved
+
+	// patterns holds the compiled regular expressions of the "pattern" and the "patternProperties" keywords.
+	// The unsupported pattern is nil.
+	patterns map[string]*regexp.Regexp
+
+	// unsupported lists the patterns which RE2 cannot compile in the order of the compilation.
+	unsupported []string
+
+	// formats holds the checkers of the "format" keyword, or nil if the "format" is the annotation only.
+	formats map[string]FormatChecker
+
+	resolver RefResolver
+}
+
+// resolved is the target of "$ref" and its base URI.
+type resolved struct {
+	schema *Schema
+	base   string
+}
+
+// CompileOption configures Compile.
+type CompileOption func(*Validator)
+
+// WithResource adds the schema document s identified by uri, which the "$ref"s can refer to.
+func WithResource(uri string, s *Schema) CompileOption {
+	return func(v *Validator) {
+		v.addResource(trimFragment(uri), s)
+	}
+}
+
+// WithFormatAssertion asserts the "format" keyword with the checkers of the formats defined by the Draft 7 validation
+// specification. The unknown formats are ignored.
+//
+// Without WithFormatAssertion or WithFormat, the "format" keyword is the annotation only as per the Draft 7 specification.
+func WithFormatAssertion() CompileOption {
+	return func(v *Validator) {
+		for name, check := range draft7Formats {
+			if _, ok := v.formats[name]; !ok {
+				v.setFormat(name, check)
+			}
+		}
+	}
+}
+
+// WithFormat asserts the "format" keyword of the name with the check, which takes precedence over the checker of
+// the format defined by the Draft 7 validation specification.
+func WithFormat(name string, check FormatChecker) CompileOption {
+	return func(v *Validator) {
+		v.setFormat(name, check)
+	}
+}
+
+func (v *Validator) setFormat(name string, check FormatChecker) {
+	if v.formats == nil {
+		v.formats = make(map[string]FormatChecker)
+	}
+	v.formats[name] = check
+}
+
+// RefResolver resolves the absolute "$ref" URI which refers to the outside of the schema documents known to the Validator.
+// It returns the target schema and the base URI of the target, or the nil schema if the ref is unknown.
+type RefResolver func(ref string) (*Schema, string, error)
+
+// WithRefResolver resolves the "$ref"s which the Validator cannot resolve by itself with the resolver.
+func WithRefResolver(resolver RefResolver) CompileOption {
+	return func(v *Validator) {
+		v.resolver = resolver
+	}
+}
+
+// Compile compiles the schema s into the Validator.
+//
+// Compile resolves all the "$ref"s and compiles all the regular expressions in advance, so the returned Validator
+// never fails with the invalid schema.
+//
+// The regular expressions are ECMA-262 as per the Draft 7 specification, but are compiled by the RE2 syntax of
+// the regexp package. The patterns which RE2 cannot compile, such as the lookaround and the backreference, are
+// unsupported rather than invalid, and are listed by UnsupportedPatterns: the "pattern" keyword of the unsupported
+// pattern always passes, and the "patternProperties" keyword of the unsupported pattern validates no property but
+// keeps every property from "additionalProperties".
+func Compile(s *Schema, opts ...CompileOption) (*Validator, error) {
+	v := &Validator{
+		root:      s,
+		resources: make(map[string]*Schema),
+		refs:      make(map[string]resolved),
+		patterns:  make(map[string]*regexp.Regexp),
+	}
+	if s != nil && s.Ref == nil && s.ID != "" && !strings.HasPrefix(s.ID, "#") {
+		v.base = trimFragment(s.ID)
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	v.addResource(v.base, s)
+
+	// collect the "$ref"s and the patterns of all the schema documents, and the schemas resolved by the resolver
+	var refs []string
+	collect := func(s *Schema, base string) error {
+		return walk(s, base, func(s *Schema, base string) error {
+			if s.Ref != nil {
+				refs = append(refs, resolveURI(base, *s.Ref))
+				return nil
+			}
+			if s.Pattern != "" {
+				v.compilePattern(s.Pattern)
+			}
+			for _, pattern := range sortedKeys(s.PatternProperties) {
+				v.compilePattern(pattern)
+			}
+			return nil
+		})
+	}
+	seen := make(map[*Schema]bool)
+	for uri, res := range v.resources {
+		if strings.Contains(uri, "#") || seen[res] {
+			continue
+		}
+		seen[res] = true
+		if err := collect(res, uri); err != nil {
+			return nil, err
+		}
+	}
+
+	for len(refs) > 0 {
+		ref := refs[0]
+		refs = refs[1:]
+		if _, ok := v.refs[ref]; ok {
+			continue
+		}
+		target, base, err := v.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		v.refs[ref] = resolved{schema: target, base: base}
+		if !seen[target] {
+			seen[target] = true
+			if err := collect(target, base); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// addResource adds the schema document s and the schemas identified by "$id" in it.
+func (v *Validator) addResource(uri string, s *Schema) {
+	if s == nil {
+		return
+	}
+	v.resources[uri] = s
+	_ = walk(s, uri, func(s *Schema, base string) error {
+		if s.Ref != nil || s.ID == "" {
+			return nil
+		}
+		if strings.HasPrefix(s.ID, "#") {
+			v.resources[base+s.ID] = s
+			return nil
+		}
+		if _, ok := v.resources[base]; !ok {
+			v.resources[base] = s
+		}
+		return nil
+	})
+}
+
+// compilePattern compiles the regular expression pattern, or records it as unsupported if RE2 cannot compile it.
+func (v *Validator) compilePattern(pattern string) {
+	if _, ok := v.patterns[pattern]; ok {
+		return
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		v.unsupported = append(v.unsupported, pattern)
+	}
+	v.patterns[pattern] = re
+}
+
+// UnsupportedPatterns returns the regular expressions of the schema which RE2 cannot compile, and so are not asserted.
+func (v *Validator) UnsupportedPatterns() []string {
+	return append([]string(nil), v.unsupported...)
+}
+
+// resolve returns the schema and its base URI referred by the absolute URI ref.
+func (v *Validator) resolve(ref string) (*Schema, string, error) {
+	s, base, err := v.resolveResource(ref)
+	if err == nil || v.resolver == nil {
+		return s, base, err
+	}
+
+	target, targetBase, rerr := v.resolver(ref)
+	switch {
+	case rerr != nil:
+		return nil, "", fmt.Errorf("jsonschema: cannot resolve $ref %q: %w", ref, rerr)
+	case target == nil:
+		return nil, "", err
+	}
+	return target, targetBase, nil
+}
+
+// resolveResource resolves the absolute URI ref in the schema documents known to the Validator.
+func (v *Validator) resolveResource(ref string) (*Schema, string, error) {
+	uri, fragment := ref, ""
+	if i := strings.IndexByte(ref, '#'); i >= 0 {
+		uri, fragment = ref[:i], ref[i+1:]
+	}
+
+	if fragment != "" && !strings.HasPrefix(fragment, "/") {
+		if s, ok := v.resources[uri+"#"+fragment]; ok {
+			return s, uri, nil
+		}
+		return nil, "", fmt.Errorf("jsonschema: cannot resolve $ref %q", ref)
+	}
+
+	s, ok := v.resources[uri]
+	if !ok {
+		return nil, "", fmt.Errorf("jsonschema: cannot resolve $ref %q: unknown schema %q", ref, uri)
+	}
+	if fragment == "" {
+		return s, uri, nil
+	}
+
+	s, base, err := Lookup(s, uri, fragment)
+	if err != nil {
+		return nil, "", fmt.Errorf("jsonschema: cannot resolve $ref %q: %w", ref, err)
+	}
+	return s, base, nil
+}
+
+// Lookup returns the subschema of s at the JSON Pointer, which may be percent-encoded as the URI fragment,
+// and the base URI of the subschema in the scope of base.
+func Lookup(s *Schema, base, pointer string) (*Schema, string, error) {
+	pointer, err := url.PathUnescape(pointer)
+	if err != nil {
+		return nil, "", err
+	}
+	if pointer == "" {
+		return s, base, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, "", fmt.Errorf("invalid JSON Pointer %q", pointer)
+	}
+	return lookupPointer(s, base, strings.Split(pointer[1:], "/"))
+}
+
+// lookupPointer returns the subschema of s at the JSON Pointer reference tokens and its base URI.
+func lookupPointer(s *Schema, base string, tokens []string) (*Schema, string, error) {
+	rest := make([]string, len(tokens))
+	for i, tok := range tokens {
+		rest[i] = unescapePointerToken(tok)
+	}
+
+	for len(rest) > 0 {
+		base = schemaBase(s, base)
+
+		var next *Schema
+		Subschemas(s, func(sub *Schema, _ string, toks []string) {
+			if next == nil && hasTokens(rest, toks) {
+				next, rest = sub, rest[len(toks):]
+			}
+		})
+		if next == nil {
+			return nil, "", fmt.Errorf("no schema at %q", "/"+strings.Join(tokens, "/"))
+		}
+		s = next
+	}
+
+	return s, base, nil
+}
+
+// hasTokens reports whether the JSON Pointer reference tokens start with the prefix.
+func hasTokens(tokens, prefix []string) bool {
+	if len(tokens) < len(prefix) {
+		return false
+	}
+	for i, tok := range prefix {
+		if tokens[i] != tok {
+			return false
+		}
+	}
+	return true
+}
+
+// walk calls fn with s and all its subschemas, and their base URIs.
+func walk(s *Schema, base string, fn func(s *Schema, base string) error) error {
+	var err error
+	Walk(s, base, func(s *Schema, _ []string, base string) bool {
+		if err != nil || s.IsBool() {
+			return false
+		}
+		err = fn(s, base)
+		return err == nil
+	})
+	return err
+}
+
+// schemaBase returns the base URI of s in the scope of base.
+func schemaBase(s *Schema, base string) string {
+	if s.Ref != nil || s.ID == "" || strings.HasPrefix(s.ID, "#") {
+		return base
+	}
+	return trimFragment(resolveURI(base, s.ID))
+}
+
+// resolveURI resolves the URI reference ref against base.
+func resolveURI(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// trimFragment trims the empty fragment from uri.
+func trimFragment(uri string) string {
+	return strings.TrimSuffix(uri, "#")
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func unescapePointerToken(tok string) string {
+	return pointerUnescaper.Replace(tok)
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func appendPointer(ptr, tok string) string {
+	return ptr + "/" + pointerEscaper.Replace(tok)
+}
+
+// Validate validates the instance, and returns all the violations or nil if the instance is valid.
+//
+// The instance is the value decoded by encoding/json, which is nil, bool, float64, json.Number, string,
+// []interface{} or map[string]interface{}. The integers are also accepted.
+func (v *Validator) Validate(instance interface{}) []*ValidationError {
+	return v.validate(v.root, v.base, instance, "", "", 0)
+}
+
+// IsValid reports whether the instance is valid.
+func (v *Validator) IsValid(instance interface{}) bool {
+	return len(v.Validate(instance)) == 0
+}
+
+func (v *Validator) validate(s *Schema, base string, inst interface{}, ipath, spath string, depth int) (errs []*ValidationError) {
+	fail := func(ipath, keyword, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{
+			InstancePath: ipath,
+			SchemaPath:   appendPointer(spath, keyword),
+			Keyword:      keyword,
+			Message:      fmt.Sprintf(format, args...),
+		})
+	}
+
+	if s == nil {
+		return nil
+	}
+	if s.IsBool() {
+		if !*s.Bool {
+			errs = append(errs, &ValidationError{InstancePath: ipath, SchemaPath: spath, Keyword: "false", Message: "no value is allowed"})
+		}
+		return errs
+	}
+
+	if s.Ref != nil {
+		ref := resolveURI(base, *s.Ref)
+		target, ok := v.refs[ref]
+		switch {
+		case !ok:
+			fail(ipath, "$ref", "cannot resolve $ref %q", *s.Ref)
+		case depth >= maxRefDepth:
+			fail(ipath, "$ref", "exceeded max $ref depth")
+		default:
+			errs = v.validate(target.schema, target.base, inst, ipath, appendPointer(spath, "$ref"), depth+1)
+		}
+		return errs
+	}
+	base = schemaBase(s, base)
+
+	if len(s.Type) > 0 && !(s.Nullable && inst == nil) {
+		typ := typeOf(inst)
+		if !s.Type.Has(typ) && !(typ == TypeInteger && s.Type.Has(TypeNumber)) {
+			if typ == TypeInteger {
+				typ = TypeNumber
+			}
+			if len(s.Type) == 1 {
+				fail(ipath, "type", "expected %s, got %s", s.Type[0], typ)
+			} else {
+				fail(ipath, "type", "expected one of %s, got %s", strings.Join(s.Type, ", "), typ)
+			}
+			return errs
+		}
+	}
+
+	if s.Enum != nil {
+		found := false
+		for _, e := range s.Enum {
+			if equal(inst, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail(ipath, "enum", "value must be one of %s", compact(s.Enum))
+		}
+	}
+	if s.Const != nil && !equal(inst, *s.Const) {
+		fail(ipath, "const", "value must be %s", compact(*s.Const))
+	}
+
+	switch inst := inst.(type) {
+	case string:
+		n := int64(utf8.RuneCountInString(inst))
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail(ipath, "maxLength", "length must be <= %d, got %d", *s.MaxLength, n)
+		}
+		if s.MinLength != nil && n < *s.MinLength {
+			fail(ipath, "minLength", "length must be >= %d, got %d", *s.MinLength, n)
+		}
+		if re := v.patterns[s.Pattern]; re != nil && !re.MatchString(inst) {
+			fail(ipath, "pattern", "%q does not match pattern %q", inst, s.Pattern)
+		}
+		if check, ok := v.formats[s.Format]; ok && !check(inst) {
+			fail(ipath, "format", "%q is not a valid %s", inst, s.Format)
+		}
+
+	case []interface{}:
+		errs = append(errs, v.validateArray(s, base, inst, ipath, spath, depth)...)
+
+	case map[string]interface{}:
+		errs = append(errs, v.validateObject(s, base, inst, ipath, spath, depth)...)
+
+	default:
+		if f, ok := toFloat(inst); ok {
+			if s.MultipleOf != nil && *s.MultipleOf > 0 {
+				if q := f / *s.MultipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+					fail(ipath, "multipleOf", "%v is not a multiple of %v", f, *s.MultipleOf)
+				}
+			}
+			if s.Maximum != nil && f > *s.Maximum {
+				fail(ipath, "maximum", "%v must be <= %v", f, *s.Maximum)
+			}
+			if s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum {
+				fail(ipath, "exclusiveMaximum", "%v must be < %v", f, *s.ExclusiveMaximum)
+			}
+			if s.Minimum != nil && f < *s.Minimum {
+				fail(ipath, "minimum", "%v must be >= %v", f, *s.Minimum)
+			}
+			if s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum {
+				fail(ipath, "exclusiveMinimum", "%v must be > %v", f, *s.ExclusiveMinimum)
+			}
+		}
+	}
+
+	errs = append(errs, v.validateComposition(s, base, inst, ipath, spath, depth)...)
+
+	return errs
+}
+
+func (v *Validator) validateArray(s *Schema, base string, arr []interface{}, ipath, spath string, depth int) (errs []*ValidationError) {
+	fail := func(ipath, keyword, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{
+			InstancePath: ipath,
+			SchemaPath:   appendPointer(spath, keyword),
+			Keyword:      keyword,
+			Message:      fmt.Sprintf(format, args...),
+		})
+	}
+
+	if s.MaxItems != nil && int64(len(arr)) > *s.MaxItems {
+		fail(ipath, "maxItems", "array must have <= %d items, got %d", *s.MaxItems, len(arr))
+	}
+	if s.MinItems != nil && int64(len(arr)) < *s.MinItems {
+		fail(ipath, "minItems", "array must have >= %d items, got %d", *s.MinItems, len(arr))
+	}
+	if s.UniqueItems {
+	unique:
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if equal(arr[i], arr[j]) {
+					fail(ipath, "uniqueItems", "items at index %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+
+	if s.Items != nil {
+		if s.Items.Schema != nil {
+			for i, item := range arr {
+				errs = append(errs, v.validate(s.Items.Schema, base, item, appendPointer(ipath, strconv.Itoa(i)), appendPointer(spath, "items"), depth)...)
+			}
+		} else {
+			for i, item := range arr {
+				itemPath := appendPointer(ipath, strconv.Itoa(i))
+				if i < len(s.Items.JSONSchemas) {
+					errs = append(errs, v.validate(&s.Items.JSONSchemas[i], base, item, itemPath, appendPointer(appendPointer(spath, "items"), strconv.Itoa(i)), depth)...)
+					continue
+				}
+				switch ai := s.AdditionalItems; {
+				case ai.Denies():
+					fail(itemPath, "additionalItems", "additional item is not allowed")
+				case ai != nil && ai.Schema != nil:
+					errs = append(errs, v.validate(ai.Schema, base, item, itemPath, appendPointer(spath, "additionalItems"), depth)...)
+				}
+			}
+		}
+	}
+
+	if s.Contains != nil {
+		found := false
+		for _, item := range arr {
+			if len(v.validate(s.Contains, base, item, ipath, spath, depth)) == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail(ipath, "contains", "array does not contain the matching item")
+		}
+	}
+
+	return errs
+}
+
+func (v *Validator) validateObject(s *Schema, base string, obj map[string]interface{}, ipath, spath string, depth int) (errs []*ValidationError) {
+	fail := func(ipath, keyword, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{
+			InstancePath: ipath,
+			SchemaPath:   appendPointer(spath, keyword),
+			Keyword:      keyword,
+			Message:      fmt.Sprintf(format, args...),
+		})
+	}
+
+	if s.MaxProperties != nil && int64(len(obj)) > *s.MaxProperties {
+		fail(ipath, "maxProperties", "object must have <= %d properties, got %d", *s.MaxProperties, len(obj))
+	}
+	if s.MinProperties != nil && int64(len(obj)) < *s.MinProperties {
+		fail(ipath, "minProperties", "object must have >= %d properties, got %d", *s.MinProperties, len(obj))
+	}
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			fail(ipath, "required", "missing required property %q", name)
+		}
+	}
+
+	keys := sortedKeys(obj)
+	patterns := sortedKeys(s.PatternProperties)
+	for _, key := range keys {
+		value, keyPath := obj[key], appendPointer(ipath, key)
+
+		matched := false
+		if ps, ok := s.Properties[key]; ok {
+			matched = true
+			errs = append(errs, v.validate(&ps, base, value, keyPath, appendPointer(appendPointer(spath, "properties"), key), depth)...)
+		}
+		for _, pattern := range patterns {
+			re := v.patterns[pattern]
+			if re == nil {
+				matched = true // unsupported
+				continue
+			}
+			if re.MatchString(key) {
+				matched = true
+				ps := s.PatternProperties[pattern]
+				errs = append(errs, v.validate(&ps, base, value, keyPath, appendPointer(appendPointer(spath, "patternProperties"), pattern), depth)...)
+			}
+		}
+		switch ap := s.AdditionalProperties; {
+		case matched || ap == nil:
+		case ap.Denies():
+			fail(keyPath, "additionalProperties", "additional property %q is not allowed", key)
+		case ap.Schema != nil:
+			errs = append(errs, v.validate(ap.Schema, base, value, keyPath, appendPointer(spath, "additionalProperties"), depth)...)
+		}
+
+		if s.PropertyNames != nil {
+			errs = append(errs, v.validate(s.PropertyNames, base, key, keyPath, appendPointer(spath, "propertyNames"), depth)...)
+		}
+	}
+
+	for _, key := range sortedKeys(s.Dependencies) {
+		if _, ok := obj[key]; !ok {
+			continue
+		}
+		dep := s.Dependencies[key]
+		if dep.Schema != nil {
+			errs = append(errs, v.validate(dep.Schema, base, obj, ipath, appendPointer(appendPointer(spath, "dependencies"), key), depth)...)
+			continue
+		}
+		for _, name := range dep.Property {
+			if _, ok := obj[name]; !ok {
+				fail(ipath, "dependencies", "property %q is required by property %q", name, key)
+			}
+		}
+	}
+
+	return errs
+}
+
+func (v *Validator) validateComposition(s *Schema, base string, inst interface{}, ipath, spath string, depth int) (errs []*ValidationError) {
+	fail := func(keyword, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{
+			InstancePath: ipath,
+			SchemaPath:   appendPointer(spath, keyword),
+			Keyword:      keyword,
+			Message:      fmt.Sprintf(format, args...),
+		})
+	}
+	branches := func(keyword string, ss []Schema) (valid []int, results [][]*ValidationError) {
+		results = make([][]*ValidationError, len(ss))
+		for i := range ss {
+			results[i] = v.validate(&ss[i], base, inst, ipath, appendPointer(appendPointer(spath, keyword), strconv.Itoa(i)), depth)
+			if len(results[i]) == 0 {
+				valid = append(valid, i)
+			}
+		}
+		return valid, results
+	}
+
+	for i := range s.AllOf {
+		errs = append(errs, v.validate(&s.AllOf[i], base, inst, ipath, appendPointer(appendPointer(spath, "allOf"), strconv.Itoa(i)), depth)...)
+	}
+
+	if len(s.AnyOf) > 0 {
+		if valid, results := branches("anyOf", s.AnyOf); len(valid) == 0 {
+			if best := closest(results); best != nil {
+				errs = append(errs, best...)
+			} else {
+				fail("anyOf", "value does not match any of the schemas")
+			}
+		}
+	}
+
+	if len(s.OneOf) > 0 {
+		switch valid, results := branches("oneOf", s.OneOf); len(valid) {
+		case 0:
+			if best := closest(results); best != nil {
+				errs = append(errs, best...)
+			} else {
+				fail("oneOf", "value does not match any of the schemas")
+			}
+		case 1:
+			// ok
+		default:
+			fail("oneOf", "value matches more than one schema (%d and %d)", valid[0], valid[1])
+		}
+	}
+
+	if s.Not != nil && len(v.validate(s.Not, base, inst, ipath, spath, depth)) == 0 {
+		fail("not", "value must not match the schema")
+	}
+
+	if s.If != nil {
+		if len(v.validate(s.If, base, inst, ipath, spath, depth)) == 0 {
+			errs = append(errs, v.validate(s.Then, base, inst, ipath, appendPointer(spath, "then"), depth)...)
+		} else {
+			errs = append(errs, v.validate(s.Else, base, inst, ipath, appendPointer(spath, "else"), depth)...)
+		}
+	}
+
+	return errs
+}
+
+// closest returns the violations of the failed alternative schema which has the fewest violations,
+// or nil if there are the multiple closest alternatives.
+//
+// The alternatives of "anyOf" and "oneOf" are usually the different kinds of object, so the violations of the closest one
+// are more helpful than the bare "does not match" message.
+func closest(results [][]*ValidationError) []*ValidationError {
+	var best []*ValidationError
+	ambiguous := false
+	for _, errs := range results {
+		switch {
+		case best == nil || len(errs) < len(best):
+			best, ambiguous = errs, false
+		case len(errs) == len(best):
+			ambiguous = true
+		}
+	}
+	if ambiguous {
+		return nil
+	}
+	return best
+}
+
+// typeOf returns the JSON Schema primitive type of the instance. The integral number is TypeInteger.
+func typeOf(inst interface{}) string {
+	switch inst := inst.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case string:
+		return TypeString
+	case []interface{}:
+		return TypeArray
+	case map[string]interface{}:
+		return TypeObject
+	default:
+		f, ok := toFloat(inst)
+		if !ok {
+			return fmt.Sprintf("%T", inst)
+		}
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return TypeInteger
+		}
+		return TypeNumber
+	}
+}
+
+// toFloat returns the float64 value of the numeric instance.
+func toFloat(inst interface{}) (float64, bool) {
+	switch n := inst.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// equal reports whether the instances a and b are the same JSON value.
+func equal(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		bb, ok := b.(bool)
+		return ok && a == bb
+	case string:
+		bs, ok := b.(string)
+		return ok && a == bs
+	case []interface{}:
+		ba, ok := b.([]interface{})
+		if !ok || len(a) != len(ba) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], ba[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bm, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(bm) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := bm[k]
+			if !ok || !equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// compact returns the compact JSON text of v for the error message.
+func compact(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// sortedKeys returns the sorted keys of the map m.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]Schema:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case Definitions:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case Dependencies:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,248 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mustSchema returns the schema of the JSON text data.
+func mustSchema(t *testing.T, data string) *Schema {
+	t.Helper()
+
+	s := new(Schema)
+	if err := json.Unmarshal([]byte(data), s); err != nil {
+		t.Fatalf("unmarshal schema %s: %v", data, err)
+	}
+	return s
+}
+
+// mustInstance returns the instance of the JSON text data decoded with UseNumber.
+func mustInstance(t *testing.T, data string) interface{} {
+	t.Helper()
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(data)))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("decode instance %s: %v", data, err)
+	}
+	return v
+}
+
+// failure is the location and the keyword of the ValidationError.
+type failure struct {
+	instancePath, keyword string
+}
+
+func failures(errs []*ValidationError) []failure {
+	if len(errs) == 0 {
+		return nil
+	}
+	fs := make([]failure, len(errs))
+	for i, err := range errs {
+		fs[i] = failure{err.InstancePath, err.Keyword}
+	}
+	return fs
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		schema   string
+		instance string
+		want     []failure
+	}{
+		"true":                 {schema: `true`, instance: `1`},
+		"false":                {schema: `false`, instance: `1`, want: []failure{{"", "false"}}},
+		"type":                 {schema: `{"type": "string"}`, instance: `1`, want: []failure{{"", "type"}}},
+		"types":                {schema: `{"type": ["string", "null"]}`, instance: `null`},
+		"integer":              {schema: `{"type": "integer"}`, instance: `1.0`},
+		"notInteger":           {schema: `{"type": "integer"}`, instance: `1.5`, want: []failure{{"", "type"}}},
+		"enum":                 {schema: `{"enum": [1, "a"]}`, instance: `"b"`, want: []failure{{"", "enum"}}},
+		"const":                {schema: `{"const": {"a": [1]}}`, instance: `{"a": [1.0]}`},
+		"minimum":              {schema: `{"minimum": 1, "exclusiveMaximum": 2}`, instance: `2`, want: []failure{{"", "exclusiveMaximum"}}},
+		"multipleOf":           {schema: `{"multipleOf": 0.1}`, instance: `0.3`},
+		"maxLength":            {schema: `{"maxLength": 2}`, instance: `"äöü"`, want: []failure{{"", "maxLength"}}},
+		"pattern":              {schema: `{"pattern": "^a+$"}`, instance: `"ab"`, want: []failure{{"", "pattern"}}},
+		"unsupportedPattern":   {schema: `{"pattern": "^(?!a)"}`, instance: `"a"`},
+		"items":                {schema: `{"items": {"type": "integer"}}`, instance: `[1, "a"]`, want: []failure{{"/1", "type"}}},
+		"tupleItems":           {schema: `{"items": [{"type": "integer"}], "additionalItems": false}`, instance: `[1, 2]`, want: []failure{{"/1", "additionalItems"}}},
+		"uniqueItems":          {schema: `{"uniqueItems": true}`, instance: `[1, 1.0]`, want: []failure{{"", "uniqueItems"}}},
+		"contains":             {schema: `{"contains": {"const": 2}}`, instance: `[1]`, want: []failure{{"", "contains"}}},
+		"required":             {schema: `{"required": ["a"]}`, instance: `{}`, want: []failure{{"", "required"}}},
+		"properties":           {schema: `{"properties": {"a/b": {"type": "string"}}}`, instance: `{"a/b": 1}`, want: []failure{{"/a~1b", "type"}}},
+		"additionalProperties": {schema: `{"properties": {"a": true}, "patternProperties": {"^x-": true}, "additionalProperties": false}`, instance: `{"a": 1, "x-b": 2, "c": 3}`, want: []failure{{"/c", "additionalProperties"}}},
+		"unsetAdditional":      {schema: `{"properties": {"a": true}}`, instance: `{"b": 1}`},
+		"unsupportedPatternProperties": {
+			schema:   `{"patternProperties": {"^(?=x)": {"type": "string"}}, "additionalProperties": false}`,
+			instance: `{"x": 1}`,
+		},
+		"propertyNames":    {schema: `{"propertyNames": {"maxLength": 1}}`, instance: `{"ab": 1}`, want: []failure{{"/ab", "maxLength"}}},
+		"dependencies":     {schema: `{"dependencies": {"a": ["b"]}}`, instance: `{"a": 1}`, want: []failure{{"", "dependencies"}}},
+		"allOf":            {schema: `{"allOf": [{"type": "integer"}, {"minimum": 2}]}`, instance: `1`, want: []failure{{"", "minimum"}}},
+		"anyOf":            {schema: `{"anyOf": [{"type": "integer"}, {"type": "string"}]}`, instance: `"a"`},
+		"oneOf":            {schema: `{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`, instance: `1`, want: []failure{{"", "oneOf"}}},
+		"not":              {schema: `{"not": {"type": "null"}}`, instance: `null`, want: []failure{{"", "not"}}},
+		"ifThenElse":       {schema: `{"if": {"type": "integer"}, "then": {"minimum": 1}, "else": {"type": "string"}}`, instance: `true`, want: []failure{{"", "type"}}},
+		"ref":              {schema: `{"definitions": {"a": {"type": "integer"}}, "properties": {"b": {"$ref": "#/definitions/a"}}}`, instance: `{"b": "x"}`, want: []failure{{"/b", "type"}}},
+		"recursiveRef":     {schema: `{"properties": {"next": {"$ref": "#"}}, "required": ["v"]}`, instance: `{"v": 1, "next": {"next": {}}}`, want: []failure{{"/next", "required"}, {"/next/next", "required"}}},
+		"idRef":            {schema: `{"$id": "https://example.com/root.json", "definitions": {"a": {"$id": "#a", "type": "string"}}, "properties": {"b": {"$ref": "#a"}}}`, instance: `{"b": 1}`, want: []failure{{"/b", "type"}}},
+		"formatAnnotation": {schema: `{"format": "email"}`, instance: `"x"`},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			v, err := Compile(mustSchema(t, tt.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := failures(v.Validate(mustInstance(t, tt.instance)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Validate(%s) = %v, want %v", tt.instance, got, tt.want)
+			}
+			if v.IsValid(mustInstance(t, tt.instance)) != (len(tt.want) == 0) {
+				t.Fatalf("IsValid(%s) disagrees with Validate", tt.instance)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaPath(t *testing.T) {
+	v, err := Compile(mustSchema(t, `{"definitions": {"a": {"type": "integer"}}, "properties": {"b": {"$ref": "#/definitions/a"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := v.Validate(mustInstance(t, `{"b": "x"}`))
+	if len(errs) != 1 {
+		t.Fatalf("Validate = %v, want 1 error", errs)
+	}
+	if got, want := errs[0].SchemaPath, "/properties/b/$ref/type"; got != want {
+		t.Fatalf("SchemaPath = %q, want %q", got, want)
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	tests := map[string]string{
+		"unknownRef":      `{"$ref": "#/definitions/none"}`,
+		"unknownResource": `{"$ref": "https://example.com/other.json"}`,
+		"invalidPointer":  `{"properties": {"a": {"$ref": "#/properties/a/items/x"}}}`,
+	}
+	for name, schema := range tests {
+		schema := schema
+		t.Run(name, func(t *testing.T) {
+			if _, err := Compile(mustSchema(t, schema)); err == nil {
+				t.Fatalf("Compile(%s) succeeded", schema)
+			}
+		})
+	}
+}
+
+func TestUnsupportedPatterns(t *testing.T) {
+	v, err := Compile(mustSchema(t, `{
+		"properties": {
+			"a": {"pattern": "^(?!x)"},
+			"b": {"pattern": "^[a-z]+$"},
+			"c": {"pattern": "(a)\\1"}
+		},
+		"patternProperties": {"(?<=y)z": true, "^(?!x)": true}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := v.UnsupportedPatterns()
+	want := []string{"(?<=y)z", "^(?!x)", "(a)\\1"}
+	if !equalSet(got, want) {
+		t.Fatalf("UnsupportedPatterns() = %q, want %q", got, want)
+	}
+}
+
+func equalSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	m := make(map[string]bool, len(a))
+	for _, s := range a {
+		m[s] = true
+	}
+	for _, s := range b {
+		if !m[s] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFormats(t *testing.T) {
+	tests := map[string]struct {
+		format string
+		valid  []string
+		bad    []string
+	}{
+		"date-time":    {format: "date-time", valid: []string{"2019-01-02T03:04:05Z", "2019-01-02t03:04:05.5+09:00"}, bad: []string{"2019-01-02", "2019-13-02T03:04:05Z"}},
+		"date":         {format: "date", valid: []string{"2019-02-28"}, bad: []string{"2019-02-30"}},
+		"email":        {format: "email", valid: []string{"a@example.com"}, bad: []string{"a", "A <a@example.com>"}},
+		"hostname":     {format: "hostname", valid: []string{"example.com", "a-b.c"}, bad: []string{"-a.com", strings.Repeat("a", 64) + ".com"}},
+		"ipv4":         {format: "ipv4", valid: []string{"192.0.2.1"}, bad: []string{"::1", "256.0.0.1"}},
+		"ipv6":         {format: "ipv6", valid: []string{"::1"}, bad: []string{"192.0.2.1"}},
+		"uri":          {format: "uri", valid: []string{"https://example.com/a?b#c"}, bad: []string{"/a"}},
+		"json-pointer": {format: "json-pointer", valid: []string{"", "/a~1b"}, bad: []string{"a", "/~2"}},
+		"regex":        {format: "regex", valid: []string{"^a+$"}, bad: []string{"("}},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			v, err := Compile(mustSchema(t, `{"format": "`+tt.format+`"}`), WithFormatAssertion())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.valid {
+				if !v.IsValid(s) {
+					t.Errorf("%q is not a valid %s", s, tt.format)
+				}
+			}
+			for _, s := range tt.bad {
+				if v.IsValid(s) {
+					t.Errorf("%q is a valid %s", s, tt.format)
+				}
+			}
+		})
+	}
+}
+
+func TestWithFormat(t *testing.T) {
+	even := func(s string) bool { return len(s)%2 == 0 }
+	anyString := func(s string) bool { return true }
+	schema := `{"properties": {"a": {"format": "even"}, "b": {"format": "email"}}}`
+	instance := map[string]interface{}{"a": "abc", "b": "x"}
+
+	tests := map[string]struct {
+		opts []CompileOption
+		want []failure
+	}{
+		"annotation":    {},
+		"custom":        {opts: []CompileOption{WithFormat("even", even)}, want: []failure{{"/a", "format"}}},
+		"draft7":        {opts: []CompileOption{WithFormatAssertion()}, want: []failure{{"/b", "format"}}},
+		"both":          {opts: []CompileOption{WithFormat("even", even), WithFormatAssertion()}, want: []failure{{"/a", "format"}, {"/b", "format"}}},
+		"overrideAfter": {opts: []CompileOption{WithFormatAssertion(), WithFormat("email", anyString)}},
+		"overrideFirst": {opts: []CompileOption{WithFormat("email", anyString), WithFormatAssertion()}},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			v, err := Compile(mustSchema(t, schema), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := failures(v.Validate(instance)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Validate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
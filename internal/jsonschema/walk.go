@@ -0,0 +1,225 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"strconv"
+)
+
+// Walk calls fn with s and all its subschemas in the keyword order, the JSON Pointer reference tokens of the
+// subschemas relative to s, and their base URIs in the scope of base. The subschemas of the schema for which fn
+// returns false are not walked.
+//
+// The "$ref" schema and the boolean schema have no subschemas. The subschemas in the maps are walked in the sorted
+// order of the names, and they are the copies of the map values.
+func Walk(s *Schema, base string, fn func(s *Schema, tokens []string, base string) bool) {
+	walkTokens(s, nil, base, fn)
+}
+
+func walkTokens(s *Schema, tokens []string, base string, fn func(s *Schema, tokens []string, base string) bool) {
+	if s == nil {
+		return
+	}
+	base = schemaBase(s, base)
+	if !fn(s, tokens, base) {
+		return
+	}
+	subschemas(s, false, func(sub *Schema, _ string, toks []string, _ func(*Schema)) {
+		walkTokens(sub, append(tokens[:len(tokens):len(tokens)], toks...), base, fn)
+	})
+}
+
+// Subschemas calls fn with each subschema of s in the order of Walk, its keyword, and its JSON Pointer reference
+// tokens relative to s. The subschemas of the subschemas are not visited.
+//
+// The "$ref" schema and the boolean schema have no subschemas. The subschemas in the maps are the copies of the map
+// values.
+func Subschemas(s *Schema, fn func(sub *Schema, keyword string, tokens []string)) {
+	subschemas(s, false, func(sub *Schema, keyword string, tokens []string, _ func(*Schema)) {
+		fn(sub, keyword, tokens)
+	})
+}
+
+// subschemas calls fn with each subschema of s in the keyword order, its keyword, its JSON Pointer reference tokens
+// relative to s, and the set function which replaces the subschema in s. It is the only enumeration of the keywords
+// of the subschemas, on which all the walks are built.
+//
+// If clone is true, the maps, the arrays and the wrappers of the subschemas in s are replaced with their copies
+// before they are visited, so that set does not modify the schemas sharing them with s. Otherwise s is never
+// modified except by set.
+func subschemas(s *Schema, clone bool, fn func(sub *Schema, keyword string, tokens []string, set func(r *Schema))) {
+	if s == nil || s.IsBool() || s.Ref != nil {
+		return
+	}
+
+	one := func(keyword string, p **Schema) {
+		if *p != nil {
+			fn(*p, keyword, []string{keyword}, func(r *Schema) { *p = r })
+		}
+	}
+	each := func(keyword string, m map[string]Schema) map[string]Schema {
+		if m == nil {
+			return nil
+		}
+		if clone {
+			c := make(map[string]Schema, len(m))
+			for name, s := range m {
+				c[name] = s
+			}
+			m = c
+		}
+		for _, name := range sortedKeys(m) {
+			name, sub := name, m[name]
+			fn(&sub, keyword, []string{keyword, name}, func(r *Schema) { m[name] = *r })
+		}
+		return m
+	}
+	list := func(keyword string, ss []Schema) []Schema {
+		if clone && ss != nil {
+			ss = append([]Schema(nil), ss...)
+		}
+		for i := range ss {
+			i := i
+			fn(&ss[i], keyword, []string{keyword, strconv.Itoa(i)}, func(r *Schema) { ss[i] = *r })
+		}
+		return ss
+	}
+
+	if clone {
+		s.Definitions = each("definitions", s.Definitions)
+		s.Properties = each("properties", s.Properties)
+		s.PatternProperties = each("patternProperties", s.PatternProperties)
+	} else {
+		each("definitions", s.Definitions)
+		each("properties", s.Properties)
+		each("patternProperties", s.PatternProperties)
+	}
+	if s.Dependencies != nil {
+		deps := s.Dependencies
+		if clone {
+			deps = make(Dependencies, len(s.Dependencies))
+			for name, dep := range s.Dependencies {
+				deps[name] = dep
+			}
+			s.Dependencies = deps
+		}
+		for _, name := range sortedKeys(deps) {
+			name := name
+			if dep := deps[name]; dep.Schema != nil {
+				fn(dep.Schema, "dependencies", []string{"dependencies", name}, func(r *Schema) {
+					dep.Schema = r
+					deps[name] = dep
+				})
+			}
+		}
+	}
+	if s.AdditionalProperties != nil {
+		if clone {
+			ap := *s.AdditionalProperties
+			s.AdditionalProperties = &ap
+		}
+		one("additionalProperties", &s.AdditionalProperties.Schema)
+	}
+	if s.AdditionalItems != nil {
+		if clone {
+			ai := *s.AdditionalItems
+			s.AdditionalItems = &ai
+		}
+		one("additionalItems", &s.AdditionalItems.Schema)
+	}
+	if s.Items != nil {
+		if clone {
+			items := *s.Items
+			s.Items = &items
+		}
+		one("items", &s.Items.Schema)
+		if clone {
+			s.Items.JSONSchemas = list("items", s.Items.JSONSchemas)
+		} else {
+			list("items", s.Items.JSONSchemas)
+		}
+	}
+	one("contains", &s.Contains)
+	one("propertyNames", &s.PropertyNames)
+	one("if", &s.If)
+	one("then", &s.Then)
+	one("else", &s.Else)
+	one("not", &s.Not)
+	if clone {
+		s.AllOf = list("allOf", s.AllOf)
+		s.AnyOf = list("anyOf", s.AnyOf)
+		s.OneOf = list("oneOf", s.OneOf)
+	} else {
+		list("allOf", s.AllOf)
+		list("anyOf", s.AnyOf)
+		list("oneOf", s.OneOf)
+	}
+}
+
+// BaseURI returns the base URI of s in the scope of base, which is changed by the "$id" of s.
+func BaseURI(s *Schema, base string) string {
+	if s == nil {
+		return base
+	}
+	return schemaBase(s, base)
+}
+
+// ResolveURI resolves the URI reference ref against the base URI.
+func ResolveURI(base, ref string) string {
+	return resolveURI(base, ref)
+}
+
+// Rewrite returns the copy of s, in which s and all its subschemas are rewritten by fn in the order of Walk.
+//
+// fn is called with the copy of each schema, the JSON Pointer reference tokens of the schema relative to s, and its
+// base URI in the scope of base. fn may modify the copy in place, and then its subschemas are rewritten unless it is
+// the "$ref" schema or the boolean schema. If fn returns the non-nil schema, it replaces the copy as is, without
+// rewriting its subschemas.
+//
+// Only the subschemas are copied, and the other keywords of the copies share their values with s.
+func Rewrite(s *Schema, base string, fn func(s *Schema, tokens []string, base string) (*Schema, error)) (*Schema, error) {
+	return rewriteTokens(s, nil, base, fn)
+}
+
+func rewriteTokens(s *Schema, tokens []string, base string, fn func(s *Schema, tokens []string, base string) (*Schema, error)) (*Schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+	c := *s
+	base = schemaBase(&c, base)
+	if r, err := fn(&c, tokens, base); err != nil || r != nil {
+		return r, err
+	}
+
+	var err error
+	subschemas(&c, true, func(sub *Schema, _ string, toks []string, set func(*Schema)) {
+		if err != nil {
+			return
+		}
+		var r *Schema
+		if r, err = rewriteTokens(sub, append(tokens[:len(tokens):len(tokens)], toks...), base, fn); err == nil {
+			set(r)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Replace replaces the subschemas of s in place with the non-nil results of fn, which is called with the subschemas in
+// the order of Walk. The subschemas of the replaced schemas, the "$ref" schemas and the boolean schemas are not walked.
+//
+// The subschemas in the maps and the arrays are replaced with the copies of the results.
+func Replace(s *Schema, fn func(s *Schema) *Schema) {
+	subschemas(s, false, func(sub *Schema, _ string, _ []string, set func(*Schema)) {
+		if r := fn(sub); r != nil {
+			set(r)
+			return
+		}
+		Replace(sub, fn)
+		set(sub)
+	})
+}
@@ -0,0 +1,261 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// walkTestSchema has the subschema of every keyword, whose "title" is its JSON Pointer.
+const walkTestSchema = `{
+	"$id": "https://example.com/root.json",
+	"definitions": {"d": {"title": "/definitions/d", "$id": "defs/d.json", "not": {"title": "/definitions/d/not"}}},
+	"properties": {"b": {"title": "/properties/b"}, "a": {"title": "/properties/a"}},
+	"patternProperties": {"^x": {"title": "/patternProperties/^x"}},
+	"dependencies": {"a": {"title": "/dependencies/a"}, "b": ["a"]},
+	"additionalProperties": {"title": "/additionalProperties"},
+	"additionalItems": {"title": "/additionalItems"},
+	"items": [{"title": "/items/0"}, {"title": "/items/1"}],
+	"contains": {"title": "/contains"},
+	"propertyNames": {"title": "/propertyNames"},
+	"if": {"title": "/if"},
+	"then": {"title": "/then"},
+	"else": {"title": "/else"},
+	"not": {"title": "/not", "$ref": "#/definitions/d", "properties": {"ignored": {}}},
+	"allOf": [{"title": "/allOf/0"}, true],
+	"anyOf": [{"title": "/anyOf/0"}],
+	"oneOf": [{"title": "/oneOf/0"}]
+}`
+
+// walkTestPointers are the JSON Pointers of the schemas in walkTestSchema in the order of Walk.
+var walkTestPointers = []string{
+	"",
+	"/definitions/d",
+	"/definitions/d/not",
+	"/properties/a",
+	"/properties/b",
+	"/patternProperties/^x",
+	"/dependencies/a",
+	"/additionalProperties",
+	"/additionalItems",
+	"/items/0",
+	"/items/1",
+	"/contains",
+	"/propertyNames",
+	"/if",
+	"/then",
+	"/else",
+	"/not",
+	"/allOf/0",
+	"/allOf/1",
+	"/anyOf/0",
+	"/oneOf/0",
+}
+
+func pointerOf(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(tokens, "/")
+}
+
+func TestWalk(t *testing.T) {
+	s := mustSchema(t, walkTestSchema)
+
+	var got []string
+	bases := make(map[string]string)
+	Walk(s, "", func(sub *Schema, tokens []string, base string) bool {
+		p := pointerOf(tokens)
+		got = append(got, p)
+		bases[p] = base
+		if !sub.IsBool() && sub.Title != p && p != "" {
+			t.Errorf("the schema at %q is titled %q", p, sub.Title)
+		}
+		return true
+	})
+	if !reflect.DeepEqual(got, walkTestPointers) {
+		t.Fatalf("Walk visited %q, want %q", got, walkTestPointers)
+	}
+	for p, want := range map[string]string{
+		"":                   "https://example.com/root.json",
+		"/definitions/d/not": "https://example.com/defs/d.json",
+		"/properties/a":      "https://example.com/root.json",
+	} {
+		if bases[p] != want {
+			t.Errorf("the base URI at %q is %q, want %q", p, bases[p], want)
+		}
+	}
+
+	got = nil
+	Walk(s, "", func(sub *Schema, tokens []string, base string) bool {
+		got = append(got, pointerOf(tokens))
+		return len(tokens) == 0
+	})
+	if len(got) != len(walkTestPointers)-1 {
+		t.Fatalf("Walk did not skip the subschemas, visited %q", got)
+	}
+}
+
+func TestSubschemas(t *testing.T) {
+	s := mustSchema(t, walkTestSchema)
+
+	type visit struct {
+		keyword, pointer string
+	}
+	var got []visit
+	Subschemas(s, func(sub *Schema, keyword string, tokens []string) {
+		got = append(got, visit{keyword, pointerOf(tokens)})
+	})
+	want := []visit{
+		{"definitions", "/definitions/d"},
+		{"properties", "/properties/a"},
+		{"properties", "/properties/b"},
+		{"patternProperties", "/patternProperties/^x"},
+		{"dependencies", "/dependencies/a"},
+		{"additionalProperties", "/additionalProperties"},
+		{"additionalItems", "/additionalItems"},
+		{"items", "/items/0"},
+		{"items", "/items/1"},
+		{"contains", "/contains"},
+		{"propertyNames", "/propertyNames"},
+		{"if", "/if"},
+		{"then", "/then"},
+		{"else", "/else"},
+		{"not", "/not"},
+		{"allOf", "/allOf/0"},
+		{"allOf", "/allOf/1"},
+		{"anyOf", "/anyOf/0"},
+		{"oneOf", "/oneOf/0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Subschemas visited %v, want %v", got, want)
+	}
+
+	for _, data := range []string{`true`, `{"$ref": "#", "properties": {"a": {}}}`} {
+		Subschemas(mustSchema(t, data), func(sub *Schema, keyword string, tokens []string) {
+			t.Errorf("Subschemas of %s visited %q", data, pointerOf(tokens))
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	s := mustSchema(t, walkTestSchema)
+	orig, _ := json.Marshal(s)
+
+	r, err := Rewrite(s, "", func(sub *Schema, tokens []string, base string) (*Schema, error) {
+		switch {
+		case sub.IsBool():
+			return NewBool(false), nil
+		case len(tokens) > 0 && tokens[0] == "items":
+			return &Schema{Title: "rewritten"}, nil
+		}
+		sub.Description = pointerOf(tokens)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(s); string(got) != string(orig) {
+		t.Fatalf("Rewrite modified the schema:\n%s\nwant\n%s", got, orig)
+	}
+
+	var got []string
+	Walk(r, "", func(sub *Schema, tokens []string, base string) bool {
+		p := pointerOf(tokens)
+		switch {
+		case p == "/allOf/1":
+			if !sub.IsBool() || *sub.Bool {
+				t.Errorf("the schema at %q is not replaced", p)
+			}
+		case strings.HasPrefix(p, "/items/"):
+			if sub.Title != "rewritten" {
+				t.Errorf("the schema at %q is not replaced", p)
+			}
+		case sub.Description != p:
+			t.Errorf("the schema at %q is described as %q", p, sub.Description)
+		}
+		got = append(got, p)
+		return true
+	})
+	if !reflect.DeepEqual(got, walkTestPointers) {
+		t.Fatalf("the rewritten schema has %q, want %q", got, walkTestPointers)
+	}
+
+	errStop := errors.New("stop")
+	if _, err := Rewrite(s, "", func(sub *Schema, tokens []string, base string) (*Schema, error) {
+		if pointerOf(tokens) == "/contains" {
+			return nil, errStop
+		}
+		return nil, nil
+	}); err != errStop {
+		t.Fatalf("Rewrite = %v, want %v", err, errStop)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	s := mustSchema(t, walkTestSchema)
+
+	var visited []string
+	Replace(s, func(sub *Schema) *Schema {
+		visited = append(visited, sub.Title)
+		if sub.Title == "/definitions/d" || sub.IsBool() {
+			return nil
+		}
+		return &Schema{Title: "replaced " + sub.Title, Not: &Schema{Title: "not visited"}}
+	})
+	// the root is not visited, and the subschema of the schema which is not replaced is visited
+	if len(visited) != len(walkTestPointers)-1 || visited[1] != "/definitions/d/not" {
+		t.Fatalf("Replace visited %q", visited)
+	}
+
+	Walk(s, "", func(sub *Schema, tokens []string, base string) bool {
+		p := pointerOf(tokens)
+		switch {
+		case p == "" || p == "/definitions/d" || p == "/allOf/1" || sub.Title == "not visited":
+		default:
+			if sub.Title != "replaced "+p {
+				t.Errorf("the schema at %q is titled %q", p, sub.Title)
+			}
+		}
+		return true
+	})
+}
+
+func TestLookup(t *testing.T) {
+	s := mustSchema(t, walkTestSchema)
+
+	for _, p := range walkTestPointers[1:] {
+		got, _, err := Lookup(s, "", strings.ReplaceAll(p, "^", "%5E"))
+		if err != nil {
+			t.Errorf("Lookup(%q): %v", p, err)
+			continue
+		}
+		if p != "/allOf/1" && got.Title != p {
+			t.Errorf("Lookup(%q) = the schema titled %q", p, got.Title)
+		}
+	}
+
+	_, base, err := Lookup(s, "", "/definitions/d/not")
+	if err != nil || base != "https://example.com/defs/d.json" {
+		t.Errorf("Lookup base = %q, %v", base, err)
+	}
+
+	for _, p := range []string{"/properties/c", "/items", "/items/2", "/items/01", "/not/properties/ignored", "/allOf/1/not", "/title", "definitions"} {
+		if _, _, err := Lookup(s, "", p); err == nil {
+			t.Errorf("Lookup(%q) succeeded", p)
+		}
+	}
+
+	escaped := mustSchema(t, `{"properties": {"a/b": {"title": "slash"}, "c~d": {"title": "tilde"}}}`)
+	for p, want := range map[string]string{"/properties/a~1b": "slash", "/properties/c~0d": "tilde"} {
+		if got, _, err := Lookup(escaped, "", p); err != nil || got.Title != want {
+			t.Errorf("Lookup(%q) = %v, %v", p, got, err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zchee/go-openrpc/internal/jsonschema"
+)
+
+// MarshalJSON implements json.Marshaler.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	return marshalObject(schema(s), s.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, s)
+}
+
+func (s *Schema) extensions() *[]*Extension { return &s.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (i Info) MarshalJSON() ([]byte, error) {
+	type info Info
+	return marshalObject(info(i), i.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (i *Info) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, i)
+}
+
+func (i *Info) extensions() *[]*Extension { return &i.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (c Contact) MarshalJSON() ([]byte, error) {
+	type contact Contact
+	return marshalObject(contact(c), c.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Contact) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, c)
+}
+
+func (c *Contact) extensions() *[]*Extension { return &c.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (l License) MarshalJSON() ([]byte, error) {
+	type license License
+	return marshalObject(license(l), l.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *License) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, l)
+}
+
+func (l *License) extensions() *[]*Extension { return &l.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (s Server) MarshalJSON() ([]byte, error) {
+	type server Server
+	return marshalObject(server(s), s.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Server) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, s)
+}
+
+func (s *Server) extensions() *[]*Extension { return &s.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (m Method) MarshalJSON() ([]byte, error) {
+	type method Method
+	return marshalObject(method(m), m.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Method) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, m)
+}
+
+func (m *Method) extensions() *[]*Extension { return &m.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (c Components) MarshalJSON() ([]byte, error) {
+	type components Components
+	return marshalObject(components(c), c.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Components) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, c)
+}
+
+func (c *Components) extensions() *[]*Extension { return &c.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	type tag Tag
+	return marshalObject(tag(t), t.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, t)
+}
+
+func (t *Tag) extensions() *[]*Extension { return &t.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (e ExternalDocumentation) MarshalJSON() ([]byte, error) {
+	type externalDocumentation ExternalDocumentation
+	return marshalObject(externalDocumentation(e), e.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *ExternalDocumentation) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, e)
+}
+
+func (e *ExternalDocumentation) extensions() *[]*Extension { return &e.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (e ExamplePairing) MarshalJSON() ([]byte, error) {
+	type examplePairing ExamplePairing
+	return marshalObject(examplePairing(e), e.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *ExamplePairing) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, e)
+}
+
+func (e *ExamplePairing) extensions() *[]*Extension { return &e.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (e Example) MarshalJSON() ([]byte, error) {
+	type example Example
+	return marshalObject(example(e), e.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *Example) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, e)
+}
+
+func (e *Example) extensions() *[]*Extension { return &e.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (l Link) MarshalJSON() ([]byte, error) {
+	type link Link
+	return marshalObject(link(l), l.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *Link) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, l)
+}
+
+func (l *Link) extensions() *[]*Extension { return &l.Extensions }
+
+// MarshalJSON implements json.Marshaler.
+func (p LinkParam) MarshalJSON() ([]byte, error) {
+	if p.IsExpression() {
+		return json.Marshal(string(p.Expression))
+	}
+	if len(p.Constant) == 0 {
+		return []byte("null"), nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, p.Constant); err != nil {
+		return nil, fmt.Errorf("openrpc: invalid link param constant: %w", err)
+	}
+	if b := buf.Bytes(); b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err == nil && strings.HasPrefix(s, "$") {
+			return nil, fmt.Errorf("openrpc: link param constant %s is indistinguishable from the runtime expression", b)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *LinkParam) UnmarshalJSON(data []byte) error {
+	*p = LinkParam{}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if strings.HasPrefix(s, "$") {
+			p.Expression = RuntimeExpressions(s)
+			return nil
+		}
+	}
+
+	if !json.Valid(data) {
+		return fmt.Errorf("openrpc: invalid link param value %s", data)
+	}
+	p.Constant = append(json.RawMessage(nil), data...)
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (j JSONSchema) MarshalJSON() ([]byte, error) {
+	if j.Schema == nil {
+		return marshalObject(struct{}{}, j.Extensions)
+	}
+	if j.Schema.IsBool() && len(j.Extensions) > 0 {
+		return nil, errors.New("openrpc: boolean schema must not have extensions")
+	}
+	return marshalObject(j.Schema, j.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *JSONSchema) UnmarshalJSON(data []byte) error {
+	j.Schema = new(jsonschema.Schema)
+	if b := bytes.TrimSpace(data); len(b) > 0 && (b[0] == 't' || b[0] == 'f') {
+		j.Extensions = nil
+		return json.Unmarshal(b, j.Schema)
+	}
+	return unmarshalObject(data, j.Schema, &j.Extensions)
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of the Linter.
+//
+// The configuration file is YAML or JSON, which configures the severity and the options of each rule by its name:
+//
+//	rules:
+//	  method-summary: error
+//	  param-description: off
+//	  method-name:
+//	    severity: warning
+//	    options:
+//	      namespaces: [eth, net]
+type Config struct {
+	// Rules maps the rule names to their configurations. The rules not in Rules run with their default severity.
+	Rules map[string]*RuleConfig
+}
+
+// RuleConfig is the configuration of the rule.
+type RuleConfig struct {
+	// Severity overrides the default severity of the rule if not nil.
+	Severity *Severity
+
+	// options is the options of the Configurable rule, or nil.
+	options *yaml.Node
+}
+
+// ParseConfig parses the YAML or JSON configuration data.
+func ParseConfig(data []byte) (*Config, error) {
+	var doc struct {
+		Rules map[string]yaml.Node `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("lint: parse config: %w", err)
+	}
+
+	cfg := &Config{Rules: make(map[string]*RuleConfig, len(doc.Rules))}
+	for name, node := range doc.Rules {
+		node := node
+		rc, err := parseRuleConfig(&node)
+		if err != nil {
+			return nil, fmt.Errorf("lint: parse config: rule %q at line %d: %w", name, node.Line, err)
+		}
+		cfg.Rules[name] = rc
+	}
+
+	return cfg, nil
+}
+
+// LoadConfig reads and parses the YAML or JSON configuration file.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("lint: load config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// parseRuleConfig parses the rule configuration node, which is either the severity or the object of the severity and the options.
+func parseRuleConfig(node *yaml.Node) (*RuleConfig, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		severity, err := parseSeverity(node.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &RuleConfig{Severity: &severity}, nil
+
+	case yaml.MappingNode:
+		rc := new(RuleConfig)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			switch key.Value {
+			case "severity":
+				if value.Kind != yaml.ScalarNode {
+					return nil, fmt.Errorf("severity must be a string")
+				}
+				severity, err := parseSeverity(value.Value)
+				if err != nil {
+					return nil, err
+				}
+				rc.Severity = &severity
+			case "options":
+				if value.Kind != yaml.MappingNode {
+					return nil, fmt.Errorf("options must be an object")
+				}
+				rc.options = value
+			default:
+				return nil, fmt.Errorf("unknown field %q: must be \"severity\" or \"options\"", key.Value)
+			}
+		}
+		return rc, nil
+
+	default:
+		return nil, fmt.Errorf("must be a severity or an object")
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteText writes the issues in the plain text, one issue per line in the form of "filename:line:column: severity: message (rule)".
+//
+// The filename is omitted if empty, and the JSON Pointer is written instead of the line and column if the position is unknown.
+func WriteText(w io.Writer, filename string, issues []*Issue) error {
+	bw := bufio.NewWriter(w)
+	for _, issue := range issues {
+		if filename != "" {
+			bw.WriteString(filename)
+			bw.WriteByte(':')
+		}
+		bw.WriteString(issue.String())
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// WriteJSON writes the issues as the JSON array.
+func WriteJSON(w io.Writer, issues []*Issue) error {
+	if issues == nil {
+		issues = []*Issue{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
+
+// The SARIF 2.1.0 log format.
+//
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// ToolName is the name of the linter reported as the SARIF tool.
+const ToolName = "openrpc-lint"
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string       `json:"name"`
+	Rules []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId"`
+	RuleIndex *int             `json:"ruleIndex,omitempty"`
+	Level     string           `json:"level"`
+	Message   sarifMessage     `json:"message"`
+	Locations []*sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []*sarifLogical        `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogical struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// sarifLevel returns the SARIF level of the severity.
+func sarifLevel(s Severity) string {
+	switch s {
+	case Off:
+		return "none"
+	case Info:
+		return "note"
+	case Warning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// WriteSARIF writes the issues as the SARIF 2.1.0 log of the single run over the file uri.
+//
+// The rules are reported as the rules of the tool, which are usually the enabled rules of the Linter.
+// Each issue is located by its line and column in the file uri, and by its JSON Pointer as the logical location.
+func WriteSARIF(w io.Writer, uri string, rules []Rule, issues []*Issue) error {
+	driver := sarifDriver{
+		Name:  ToolName,
+		Rules: make([]*sarifRule, len(rules)),
+	}
+	index := make(map[string]int, len(rules))
+	for i, r := range rules {
+		driver.Rules[i] = &sarifRule{
+			ID:                   r.Name(),
+			ShortDescription:     sarifMessage{Text: r.Description()},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.DefaultSeverity())},
+		}
+		index[r.Name()] = i
+	}
+
+	results := make([]*sarifResult, len(issues))
+	for i, issue := range issues {
+		loc := &sarifLocation{
+			LogicalLocations: []*sarifLogical{{FullyQualifiedName: issue.Pointer}},
+		}
+		if uri != "" {
+			loc.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uri}}
+			if issue.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: issue.Line, StartColumn: issue.Column}
+			}
+		}
+
+		results[i] = &sarifResult{
+			RuleID:    issue.Rule,
+			Level:     sarifLevel(issue.Severity),
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []*sarifLocation{loc},
+		}
+		if j, ok := index[issue.Rule]; ok {
+			results[i].RuleIndex = &j
+		}
+	}
+
+	log := &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []*sarifRun{{
+			Tool:    sarifTool{Driver: driver},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(log); err != nil {
+		return fmt.Errorf("lint: write SARIF: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lint checks the OpenRPC documents against the configurable house style rules.
+//
+// The rules run over the openrpc.Schema model and report the issues located by the JSON Pointers.
+// The issues of the openrpc.Document are also located by the line and column of the original text.
+package lint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	openrpc "github.com/zchee/go-openrpc"
+)
+
+// Severity is the severity of the issue.
+type Severity int
+
+const (
+	// Off disables the rule.
+	Off Severity = iota
+
+	// Info is the informational issue.
+	Info
+
+	// Warning is the issue which SHOULD be fixed.
+	Warning
+
+	// Error is the issue which MUST be fixed.
+	Error
+)
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	switch s {
+	case Off:
+		return "off"
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return strconv.Itoa(int(s))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	switch s {
+	case Off, Info, Warning, Error:
+		return []byte(s.String()), nil
+	default:
+		return nil, fmt.Errorf("lint: invalid severity %d", int(s))
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := parseSeverity(string(text))
+	if err != nil {
+		return fmt.Errorf("lint: %w", err)
+	}
+	*s = severity
+	return nil
+}
+
+func parseSeverity(text string) (Severity, error) {
+	switch text {
+	case "off":
+		return Off, nil
+	case "info":
+		return Info, nil
+	case "warning", "warn":
+		return Warning, nil
+	case "error":
+		return Error, nil
+	default:
+		return Off, fmt.Errorf("unknown severity %q: must be one of \"off\", \"info\", \"warning\" or \"error\"", text)
+	}
+}
+
+// Issue is the violation of the rule.
+type Issue struct {
+	// Rule is the name of the violated rule.
+	Rule string `json:"rule"`
+
+	// Severity is the configured severity of the rule.
+	Severity Severity `json:"severity"`
+
+	// Pointer is the JSON Pointer to the offending value in the JSON encoding of the document.
+	Pointer string `json:"pointer"`
+
+	// Line and Column are the 1-based position of the offending value in the original text, or zero if unknown.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+// String returns the issue in the form of "line:column: severity: message (rule)", or the pointer if the position is unknown.
+func (i *Issue) String() string {
+	loc := i.Pointer
+	if loc == "" {
+		loc = "/"
+	}
+	if i.Line > 0 {
+		loc = fmt.Sprintf("%d:%d", i.Line, i.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", loc, i.Severity, i.Message, i.Rule)
+}
+
+// Rule is the lint rule which checks the document.
+type Rule interface {
+	// Name returns the unique name of the rule, such as "method-summary".
+	Name() string
+
+	// Description returns the short description of the rule.
+	Description() string
+
+	// DefaultSeverity returns the severity of the rule when the configuration does not set it.
+	DefaultSeverity() Severity
+
+	// Check checks the document and reports the issues to the ctx.
+	Check(ctx *Context)
+}
+
+// Configurable is implemented by the Rule which takes the options from the configuration.
+type Configurable interface {
+	// Configure configures the rule with the options. The decode decodes the options into the Go value v.
+	Configure(decode func(v interface{}) error) error
+}
+
+// Context is the context of the Rule check.
+type Context struct {
+	// Schema is the document to check.
+	Schema *openrpc.Schema
+
+	rule   Rule
+	issues []*Issue
+}
+
+// Report reports the issue of the value at the JSON Pointer in the JSON encoding of the document.
+func (c *Context) Report(pointer, format string, args ...interface{}) {
+	c.issues = append(c.issues, &Issue{
+		Rule:    c.rule.Name(),
+		Pointer: pointer,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Pointer returns the JSON Pointer of the reference tokens, which are the object member names or the array indexes.
+func Pointer(tokens ...interface{}) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		sb.WriteByte('/')
+		switch tok := tok.(type) {
+		case int:
+			sb.WriteString(strconv.Itoa(tok))
+		default:
+			sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(fmt.Sprint(tok)))
+		}
+	}
+	return sb.String()
+}
+
+// Linter runs the configured rules over the documents.
+type Linter struct {
+	rules []*configuredRule
+}
+
+type configuredRule struct {
+	rule     Rule
+	severity Severity
+}
+
+// New returns the Linter which runs the rules configured by cfg. The rules defaults to DefaultRules, and the nil cfg uses
+// the default severity of each rule.
+//
+// New returns the error if cfg configures the unknown rule or the invalid options.
+func New(cfg *Config, rules ...Rule) (*Linter, error) {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	if cfg == nil {
+		cfg = new(Config)
+	}
+
+	known := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if known[r.Name()] {
+			return nil, fmt.Errorf("lint: duplicate rule %q", r.Name())
+		}
+		known[r.Name()] = true
+	}
+	for name := range cfg.Rules {
+		if !known[name] {
+			return nil, fmt.Errorf("lint: unknown rule %q in the configuration", name)
+		}
+	}
+
+	l := new(Linter)
+	for _, r := range rules {
+		severity := r.DefaultSeverity()
+		if rc, ok := cfg.Rules[r.Name()]; ok {
+			if rc.Severity != nil {
+				severity = *rc.Severity
+			}
+			if rc.options != nil {
+				c, ok := r.(Configurable)
+				if !ok {
+					return nil, fmt.Errorf("lint: rule %q does not take options", r.Name())
+				}
+				if err := c.Configure(rc.options.Decode); err != nil {
+					return nil, fmt.Errorf("lint: configure rule %q: %w", r.Name(), err)
+				}
+			}
+		}
+		if severity == Off {
+			continue
+		}
+		l.rules = append(l.rules, &configuredRule{rule: r, severity: severity})
+	}
+
+	return l, nil
+}
+
+// Rules returns the enabled rules.
+func (l *Linter) Rules() []Rule {
+	rules := make([]Rule, len(l.rules))
+	for i, r := range l.rules {
+		rules[i] = r.rule
+	}
+	return rules
+}
+
+// Lint runs the enabled rules over the document s, and returns the issues in the order of the rules.
+func (l *Linter) Lint(s *openrpc.Schema) []*Issue {
+	var issues []*Issue
+	for _, r := range l.rules {
+		ctx := &Context{Schema: s, rule: r.rule}
+		r.rule.Check(ctx)
+		for _, issue := range ctx.issues {
+			issue.Severity = r.severity
+		}
+		issues = append(issues, ctx.issues...)
+	}
+	return issues
+}
+
+// LintDocument runs the enabled rules over the document d, and returns the issues located in the original text.
+func (l *Linter) LintDocument(d *openrpc.Document) []*Issue {
+	issues := l.Lint(d.Schema)
+	for _, issue := range issues {
+		if line, column, ok := d.Position(issue.Pointer); ok {
+			issue.Line, issue.Column = line, column
+		}
+	}
+	return issues
+}
@@ -0,0 +1,535 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	openrpc "github.com/zchee/go-openrpc"
+)
+
+type issue struct {
+	rule, pointer string
+}
+
+// issuesOf returns the rules and the pointers of the issues.
+func issuesOf(issues []*Issue) []issue {
+	var got []issue
+	for _, i := range issues {
+		got = append(got, issue{i.Rule, i.Pointer})
+	}
+	return got
+}
+
+// mustSchema parses the JSON document data.
+func mustSchema(t *testing.T, data string) *openrpc.Schema {
+	t.Helper()
+
+	s, err := openrpc.Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// lintMethods returns the issues of the rule over the document of the methods.
+func lintMethods(t *testing.T, rule Rule, methods string) []issue {
+	t.Helper()
+
+	l, err := New(nil, rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return issuesOf(l.Lint(mustSchema(t, `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": `+methods+`}`)))
+}
+
+func TestMethodNameRule(t *testing.T) {
+	tests := map[string]struct {
+		options string
+		methods string
+		want    []issue
+	}{
+		"valid": {
+			methods: `[{"name": "eth_get_balance"}, {"name": "net_version"}]`,
+		},
+		"camelCase": {
+			methods: `[{"name": "eth_getBalance"}, {"name": "version"}]`,
+			want:    []issue{{RuleMethodName, "/methods/0/name"}, {RuleMethodName, "/methods/1/name"}},
+		},
+		"namespaces": {
+			options: `{"namespaces": ["eth"]}`,
+			methods: `[{"name": "eth_call"}, {"name": "net_version"}]`,
+			want:    []issue{{RuleMethodName, "/methods/1/name"}},
+		},
+		"pattern": {
+			options: `{"pattern": "^[a-z]+\\.[a-zA-Z]+$"}`,
+			methods: `[{"name": "eth.getBalance"}, {"name": "eth_call"}]`,
+			want:    []issue{{RuleMethodName, "/methods/1/name"}},
+		},
+		"emptyName": {
+			methods: `[{"name": ""}]`,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := NewMethodNameRule()
+			if tt.options != "" {
+				if err := r.Configure(func(v interface{}) error { return json.Unmarshal([]byte(tt.options), v) }); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := lintMethods(t, r, tt.methods); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Lint = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	err := NewMethodNameRule().Configure(func(v interface{}) error { return json.Unmarshal([]byte(`{"pattern": "("}`), v) })
+	if err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("Configure of the invalid pattern = %v", err)
+	}
+}
+
+func TestMethodSummaryRule(t *testing.T) {
+	got := lintMethods(t, NewMethodSummaryRule(), `[{"name": "a", "summary": "s"}, {"name": "b", "summary": "  "}, {"name": "c"}]`)
+	want := []issue{{RuleMethodSummary, "/methods/1"}, {RuleMethodSummary, "/methods/2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lint = %v, want %v", got, want)
+	}
+}
+
+func TestParamDescriptionRule(t *testing.T) {
+	l, err := New(nil, NewParamDescriptionRule())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := mustSchema(t, `{
+		"openrpc": "1.3.0",
+		"info": {"title": "t", "version": "1"},
+		"methods": [{
+			"name": "a",
+			"params": [
+				{"name": "x", "description": "d", "schema": {}},
+				{"name": "y", "schema": {}},
+				{"$ref": "#/components/contentDescriptors/z"},
+				{"oneOf": [{"name": "o", "schema": {}}, {"name": "p", "description": "d", "schema": {}}]}
+			]
+		}],
+		"components": {"contentDescriptors": {"z": {"name": "z", "schema": {}}, "w": {"name": "w", "description": "d", "schema": {}}}}
+	}`)
+	want := []issue{
+		{RuleParamDescription, "/methods/0/params/1"},
+		{RuleParamDescription, "/methods/0/params/3/oneOf/0"},
+		{RuleParamDescription, "/components/contentDescriptors/z"},
+	}
+	if got := issuesOf(l.Lint(s)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lint = %v, want %v", got, want)
+	}
+}
+
+func TestDeprecatedReplacementRule(t *testing.T) {
+	tests := map[string]struct {
+		methods string
+		want    []issue
+	}{
+		"extension": {
+			methods: `[{"name": "a", "deprecated": true, "x-replaced-by": "b"}, {"name": "b"}]`,
+		},
+		"mentioned": {
+			methods: `[{"name": "a", "deprecated": true, "description": "Use b instead."}, {"name": "b"}]`,
+		},
+		"notDeprecated": {
+			methods: `[{"name": "a"}]`,
+		},
+		"noReplacement": {
+			methods: `[{"name": "a", "deprecated": true, "summary": "Old."}, {"name": "b"}]`,
+			want:    []issue{{RuleDeprecatedReplacement, "/methods/0"}},
+		},
+		"partialWord": {
+			methods: `[{"name": "a", "deprecated": true, "description": "Use b_2 or ab."}, {"name": "b"}]`,
+			want:    []issue{{RuleDeprecatedReplacement, "/methods/0"}},
+		},
+		"deprecatedReplacement": {
+			methods: `[{"name": "a", "deprecated": true, "description": "Use b."}, {"name": "b", "deprecated": true, "description": "Use a."}]`,
+			want:    []issue{{RuleDeprecatedReplacement, "/methods/0"}, {RuleDeprecatedReplacement, "/methods/1"}},
+		},
+		"unknownMethod": {
+			methods: `[{"name": "a", "deprecated": true, "x-replaced-by": "c"}]`,
+			want:    []issue{{RuleDeprecatedReplacement, "/methods/0/x-replaced-by"}},
+		},
+		"itself": {
+			methods: `[{"name": "a", "deprecated": true, "x-replaced-by": "a"}]`,
+			want:    []issue{{RuleDeprecatedReplacement, "/methods/0/x-replaced-by"}},
+		},
+		"notString": {
+			methods: `[{"name": "a", "deprecated": true, "x-replaced-by": 1}, {"name": "b"}]`,
+			want:    []issue{{RuleDeprecatedReplacement, "/methods/0/x-replaced-by"}},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if got := lintMethods(t, NewDeprecatedReplacementRule(), tt.methods); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Lint = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxInlinePropertiesRule(t *testing.T) {
+	const props = `{"a": {}, "b": {}, "c": {}}`
+	tests := map[string]struct {
+		methods string
+		want    []issue
+	}{
+		"valid": {
+			methods: `[{"name": "a", "params": [{"name": "p", "schema": {"properties": {"a": {}, "b": {}}}}]}]`,
+		},
+		"param": {
+			methods: `[{"name": "a", "params": [{"name": "p", "schema": {"properties": ` + props + `}}]}]`,
+			want:    []issue{{RuleMaxInlineProperties, "/methods/0/params/0/schema"}},
+		},
+		"result": {
+			methods: `[{"name": "a", "params": [], "result": {"name": "r", "schema": {"items": {"properties": ` + props + `}}}}]`,
+			want:    []issue{{RuleMaxInlineProperties, "/methods/0/result/schema/items"}},
+		},
+		"nested": {
+			methods: `[{"name": "a", "params": [{"name": "p", "schema": {"properties": {"x/y": {"anyOf": [{}, {"properties": ` + props + `}]}}}}]}]`,
+			want:    []issue{{RuleMaxInlineProperties, "/methods/0/params/0/schema/properties/x~1y/anyOf/1"}},
+		},
+		"oneOf": {
+			methods: `[{"name": "a", "params": [{"oneOf": [{"name": "p", "schema": {"properties": ` + props + `}}]}]}]`,
+			want:    []issue{{RuleMaxInlineProperties, "/methods/0/params/0/oneOf/0/schema"}},
+		},
+		"propertyNames": {
+			methods: `[{"name": "a", "params": [{"name": "p", "schema": {"propertyNames": {"properties": ` + props + `}}}]}]`,
+			want:    []issue{{RuleMaxInlineProperties, "/methods/0/params/0/schema/propertyNames"}},
+		},
+		"definitions": {
+			methods: `[{"name": "a", "params": [{"name": "p", "schema": {"definitions": {"d": {"properties": ` + props + `}}}}]}]`,
+		},
+		"reference": {
+			methods: `[{"name": "a", "params": [{"name": "p", "schema": {"$ref": "#/components/schemas/s", "properties": ` + props + `}}]}]`,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := NewMaxInlinePropertiesRule()
+			if err := r.Configure(func(v interface{}) error { return json.Unmarshal([]byte(`{"max": 2}`), v) }); err != nil {
+				t.Fatal(err)
+			}
+			if got := lintMethods(t, r, tt.methods); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Lint = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	err := NewMaxInlinePropertiesRule().Configure(func(v interface{}) error { return json.Unmarshal([]byte(`{"max": -1}`), v) })
+	if err == nil || !strings.Contains(err.Error(), "non-negative") {
+		t.Errorf("Configure of the negative max = %v", err)
+	}
+}
+
+func TestSpecVersionRule(t *testing.T) {
+	tests := map[string][]issue{
+		"":      nil,
+		"1.2.6": nil,
+		"1.3.0": nil,
+		"1.1.0": {{RuleSpecVersion, "/openrpc"}},
+		"2.0.0": {{RuleSpecVersion, "/openrpc"}},
+		"x":     {{RuleSpecVersion, "/openrpc"}},
+	}
+	for version, want := range tests {
+		l, err := New(nil, NewSpecVersionRule())
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := &openrpc.Schema{OpenRPC: version}
+		if got := issuesOf(l.Lint(s)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: Lint = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+rules:
+  method-summary: error
+  param-description: off
+  method-name:
+    severity: warn
+    options:
+      namespaces: [eth]
+  max-inline-properties:
+    options:
+      max: 1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, r := range l.Rules() {
+		names = append(names, r.Name())
+	}
+	if want := []string{RuleMethodName, RuleMethodSummary, RuleDeprecatedReplacement, RuleMaxInlineProperties, RuleSpecVersion}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Rules = %q, want %q", names, want)
+	}
+
+	s := mustSchema(t, `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": [
+		{"name": "net_version", "params": [{"name": "p", "schema": {"properties": {"a": {}, "b": {}}}}]}
+	]}`)
+	type severityIssue struct {
+		rule     string
+		severity Severity
+	}
+	var got []severityIssue
+	for _, i := range l.Lint(s) {
+		got = append(got, severityIssue{i.Rule, i.Severity})
+	}
+	want := []severityIssue{{RuleMethodName, Warning}, {RuleMethodSummary, Error}, {RuleMaxInlineProperties, Warning}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lint = %v, want %v", got, want)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "lint.json")
+	if err := os.WriteFile(filename, []byte(`{"rules": {"spec-version": "error"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc := cfg.Rules[RuleSpecVersion]; rc == nil || rc.Severity == nil || *rc.Severity != Error {
+		t.Fatalf("LoadConfig = %+v", cfg.Rules)
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "none.yaml")); err == nil {
+		t.Error("LoadConfig of the missing file succeeded")
+	}
+}
+
+func TestConfigError(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		msg    string
+	}{
+		"syntax":          {config: "rules: [", msg: "parse config"},
+		"severity":        {config: "rules:\n  method-summary: fatal", msg: `unknown severity "fatal"`},
+		"severityType":    {config: "rules:\n  method-summary:\n    severity: [error]", msg: "severity must be a string"},
+		"optionsType":     {config: "rules:\n  method-name:\n    options: [eth]", msg: "options must be an object"},
+		"unknownField":    {config: "rules:\n  method-name:\n    level: error", msg: `unknown field "level"`},
+		"ruleType":        {config: "rules:\n  method-name: [error]", msg: "must be a severity or an object"},
+		"line":            {config: "rules:\n  method-summary: info\n  method-name: fatal", msg: "at line 3"},
+		"unknownRule":     {config: "rules:\n  no-such-rule: error", msg: `unknown rule "no-such-rule"`},
+		"notConfigurable": {config: "rules:\n  method-summary:\n    options: {a: 1}", msg: "does not take options"},
+		"invalidOptions":  {config: "rules:\n  max-inline-properties:\n    options: {max: -1}", msg: `configure rule "max-inline-properties"`},
+		"optionsDecode":   {config: "rules:\n  max-inline-properties:\n    options: {max: a}", msg: `configure rule "max-inline-properties"`},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(tt.config))
+			if err == nil {
+				_, err = New(cfg)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("error = %v, want %q", err, tt.msg)
+			}
+		})
+	}
+
+	if _, err := New(nil, NewMethodSummaryRule(), NewMethodSummaryRule()); err == nil || !strings.Contains(err.Error(), "duplicate rule") {
+		t.Errorf("New of the duplicate rules = %v", err)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for _, s := range []Severity{Off, Info, Warning, Error} {
+		text, err := s.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Severity
+		if err := got.UnmarshalText(text); err != nil || got != s {
+			t.Errorf("UnmarshalText(%s) = %v, %v, want %v", text, got, err, s)
+		}
+	}
+
+	if _, err := Severity(9).MarshalText(); err == nil {
+		t.Error("MarshalText of the invalid severity succeeded")
+	}
+	if got := Severity(9).String(); got != "9" {
+		t.Errorf("String() = %q", got)
+	}
+	var s Severity
+	if err := s.UnmarshalText([]byte("fatal")); err == nil {
+		t.Error("UnmarshalText(fatal) succeeded")
+	}
+}
+
+func TestPointer(t *testing.T) {
+	tests := map[string][]interface{}{
+		"":                  nil,
+		"/methods/0/name":   {"methods", 0, "name"},
+		"/a~1b/c~0d/e~01":   {"a/b", "c~d", "e~1"},
+		"/components/x y/1": {"components", "x y", "1"},
+	}
+	for want, tokens := range tests {
+		if got := Pointer(tokens...); got != want {
+			t.Errorf("Pointer(%q) = %q, want %q", tokens, got, want)
+		}
+	}
+}
+
+func TestLintDocument(t *testing.T) {
+	d, err := openrpc.ParseDocument([]byte(`openrpc: 1.2.6
+info:
+  title: t
+  version: "1"
+methods:
+  - name: a
+    summary: s
+  - name: b_c
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := New(nil, NewMethodNameRule(), NewMethodSummaryRule())
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := l.LintDocument(d)
+
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.String())
+	}
+	want := []string{
+		`6:11: error: method name "a" does not match the pattern "` + DefaultMethodNamePattern + `" (method-name)`,
+		`8:5: warning: method "b_c" has no summary (method-summary)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("LintDocument = %q, want %q", got, want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	issues := []*Issue{
+		{Rule: "r", Severity: Error, Pointer: "/methods/0", Line: 3, Column: 5, Message: "m"},
+		{Rule: "s", Severity: Info, Message: "n"},
+	}
+	tests := map[string]string{
+		"":             "3:5: error: m (r)\n/: info: n (s)\n",
+		"openrpc.yaml": "openrpc.yaml:3:5: error: m (r)\nopenrpc.yaml:/: info: n (s)\n",
+	}
+	for filename, want := range tests {
+		var buf bytes.Buffer
+		if err := WriteText(&buf, filename, issues); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("WriteText(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, nil); err != nil || buf.String() != "[]\n" {
+		t.Fatalf("WriteJSON(nil) = %q, %v", buf.String(), err)
+	}
+
+	buf.Reset()
+	issues := []*Issue{{Rule: "r", Severity: Warning, Pointer: "/a", Line: 1, Column: 2, Message: "m"}, {Rule: "s", Severity: Error, Pointer: "/b", Message: "n"}}
+	if err := WriteJSON(&buf, issues); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]interface{}{
+		{"rule": "r", "severity": "warning", "pointer": "/a", "line": 1.0, "column": 2.0, "message": "m"},
+		{"rule": "s", "severity": "error", "pointer": "/b", "message": "n"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("WriteJSON = %v, want %v", got, want)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	rules := []Rule{NewMethodNameRule(), NewMethodSummaryRule()}
+	issues := []*Issue{
+		{Rule: RuleMethodSummary, Severity: Info, Pointer: "/methods/0", Line: 6, Column: 5, Message: "m"},
+		{Rule: "custom", Severity: Error, Pointer: "/methods/1", Message: "n"},
+	}
+	tests := map[string]string{
+		"openrpc.yaml": `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "openrpc-lint", "rules": [
+      {"id": "method-name", "shortDescription": {"text": "Method names must be snake_case with a namespace prefix."}, "defaultConfiguration": {"level": "error"}},
+      {"id": "method-summary", "shortDescription": {"text": "Every method must have a summary."}, "defaultConfiguration": {"level": "warning"}}
+    ]}},
+    "results": [
+      {"ruleId": "method-summary", "ruleIndex": 1, "level": "note", "message": {"text": "m"}, "locations": [{
+        "physicalLocation": {"artifactLocation": {"uri": "openrpc.yaml"}, "region": {"startLine": 6, "startColumn": 5}},
+        "logicalLocations": [{"fullyQualifiedName": "/methods/0"}]
+      }]},
+      {"ruleId": "custom", "level": "error", "message": {"text": "n"}, "locations": [{
+        "physicalLocation": {"artifactLocation": {"uri": "openrpc.yaml"}},
+        "logicalLocations": [{"fullyQualifiedName": "/methods/1"}]
+      }]}
+    ]
+  }]
+}`,
+		"": `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "openrpc-lint", "rules": [
+      {"id": "method-name", "shortDescription": {"text": "Method names must be snake_case with a namespace prefix."}, "defaultConfiguration": {"level": "error"}},
+      {"id": "method-summary", "shortDescription": {"text": "Every method must have a summary."}, "defaultConfiguration": {"level": "warning"}}
+    ]}},
+    "results": [
+      {"ruleId": "method-summary", "ruleIndex": 1, "level": "note", "message": {"text": "m"}, "locations": [{"logicalLocations": [{"fullyQualifiedName": "/methods/0"}]}]},
+      {"ruleId": "custom", "level": "error", "message": {"text": "n"}, "locations": [{"logicalLocations": [{"fullyQualifiedName": "/methods/1"}]}]}
+    ]
+  }]
+}`,
+	}
+	for uri, want := range tests {
+		var buf bytes.Buffer
+		if err := WriteSARIF(&buf, uri, rules, issues); err != nil {
+			t.Fatal(err)
+		}
+		var got, w interface{}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(want), &w); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("WriteSARIF(%q) =\n%s\nwant\n%s", uri, buf.String(), want)
+		}
+	}
+}
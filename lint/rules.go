@@ -0,0 +1,441 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	openrpc "github.com/zchee/go-openrpc"
+	"github.com/zchee/go-openrpc/internal/jsonschema"
+)
+
+// The names of the built-in rules.
+const (
+	// RuleMethodName is the rule that the method names MUST be snake_case with the namespace prefix, such as "eth_get_balance".
+	RuleMethodName = "method-name"
+
+	// RuleMethodSummary is the rule that every method MUST have the summary.
+	RuleMethodSummary = "method-summary"
+
+	// RuleParamDescription is the rule that every param MUST have the description.
+	RuleParamDescription = "param-description"
+
+	// RuleDeprecatedReplacement is the rule that every deprecated method MUST name the method which replaces it.
+	RuleDeprecatedReplacement = "deprecated-replacement"
+
+	// RuleMaxInlineProperties is the rule that the inline object schemas MUST NOT have more than the max properties.
+	RuleMaxInlineProperties = "max-inline-properties"
+
+	// RuleSpecVersion is the rule that the specification version MUST be supported by the package.
+	RuleSpecVersion = "spec-version"
+)
+
+// ReplacedByExtension is the name of the method extension which names the method replacing the deprecated method.
+const ReplacedByExtension = "x-replaced-by"
+
+// DefaultRules returns the new built-in rules with the default options.
+func DefaultRules() []Rule {
+	return []Rule{
+		NewMethodNameRule(),
+		NewMethodSummaryRule(),
+		NewParamDescriptionRule(),
+		NewDeprecatedReplacementRule(),
+		NewMaxInlinePropertiesRule(),
+		NewSpecVersionRule(),
+	}
+}
+
+// DefaultMethodNamePattern is the default pattern of the method names, which is snake_case with the namespace prefix.
+const DefaultMethodNamePattern = `^[a-z][a-z0-9]*_[a-z][a-z0-9]*(_[a-z0-9]+)*$`
+
+// MethodNameRule checks the method names against the pattern, and optionally the namespace prefixes.
+//
+// The options are:
+//
+//	pattern: the regular expression of the method names. Defaults to DefaultMethodNamePattern.
+//	namespaces: the allowed namespace prefixes, such as ["eth", "net"]. Defaults to any namespace.
+type MethodNameRule struct {
+	pattern    *regexp.Regexp
+	namespaces []string
+}
+
+var _ Configurable = (*MethodNameRule)(nil)
+
+// NewMethodNameRule returns the new MethodNameRule with the default options.
+func NewMethodNameRule() *MethodNameRule {
+	return &MethodNameRule{
+		pattern: regexp.MustCompile(DefaultMethodNamePattern),
+	}
+}
+
+// Name implements Rule.
+func (*MethodNameRule) Name() string { return RuleMethodName }
+
+// Description implements Rule.
+func (*MethodNameRule) Description() string {
+	return "Method names must be snake_case with a namespace prefix."
+}
+
+// DefaultSeverity implements Rule.
+func (*MethodNameRule) DefaultSeverity() Severity { return Error }
+
+// Configure implements Configurable.
+func (r *MethodNameRule) Configure(decode func(v interface{}) error) error {
+	var opts struct {
+		Pattern    string   `json:"pattern" yaml:"pattern"`
+		Namespaces []string `json:"namespaces" yaml:"namespaces"`
+	}
+	if err := decode(&opts); err != nil {
+		return err
+	}
+
+	if opts.Pattern != "" {
+		re, err := regexp.Compile(opts.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		r.pattern = re
+	}
+	r.namespaces = opts.Namespaces
+
+	return nil
+}
+
+// Check implements Rule.
+func (r *MethodNameRule) Check(ctx *Context) {
+	for i, m := range ctx.Schema.Methods {
+		if m == nil || m.Name == "" {
+			continue
+		}
+		if !r.pattern.MatchString(m.Name) {
+			ctx.Report(Pointer("methods", i, "name"), "method name %q does not match the pattern %q", m.Name, r.pattern)
+			continue
+		}
+		if len(r.namespaces) > 0 && !r.inNamespace(m.Name) {
+			ctx.Report(Pointer("methods", i, "name"), "method name %q is not prefixed by one of the namespaces %q", m.Name, r.namespaces)
+		}
+	}
+}
+
+func (r *MethodNameRule) inNamespace(name string) bool {
+	for _, ns := range r.namespaces {
+		if strings.HasPrefix(name, ns+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// MethodSummaryRule checks that every method has the summary.
+type MethodSummaryRule struct{}
+
+// NewMethodSummaryRule returns the new MethodSummaryRule.
+func NewMethodSummaryRule() *MethodSummaryRule { return new(MethodSummaryRule) }
+
+// Name implements Rule.
+func (*MethodSummaryRule) Name() string { return RuleMethodSummary }
+
+// Description implements Rule.
+func (*MethodSummaryRule) Description() string { return "Every method must have a summary." }
+
+// DefaultSeverity implements Rule.
+func (*MethodSummaryRule) DefaultSeverity() Severity { return Warning }
+
+// Check implements Rule.
+func (*MethodSummaryRule) Check(ctx *Context) {
+	for i, m := range ctx.Schema.Methods {
+		if m != nil && strings.TrimSpace(m.Summary) == "" {
+			ctx.Report(Pointer("methods", i), "method %q has no summary", m.Name)
+		}
+	}
+}
+
+// ParamDescriptionRule checks that every param has the description.
+//
+// The params defined inline in the methods, including the ones nested in the oneOf, and the content descriptors in the
+// Components are checked. The references are checked at their targets.
+type ParamDescriptionRule struct{}
+
+// NewParamDescriptionRule returns the new ParamDescriptionRule.
+func NewParamDescriptionRule() *ParamDescriptionRule { return new(ParamDescriptionRule) }
+
+// Name implements Rule.
+func (*ParamDescriptionRule) Name() string { return RuleParamDescription }
+
+// Description implements Rule.
+func (*ParamDescriptionRule) Description() string { return "Every param must have a description." }
+
+// DefaultSeverity implements Rule.
+func (*ParamDescriptionRule) DefaultSeverity() Severity { return Warning }
+
+// Check implements Rule.
+func (r *ParamDescriptionRule) Check(ctx *Context) {
+	for i, m := range ctx.Schema.Methods {
+		if m == nil {
+			continue
+		}
+		for j, p := range m.Params {
+			r.check(ctx, m, p, []interface{}{"methods", i, "params", j})
+		}
+	}
+
+	if c := ctx.Schema.Components; c != nil {
+		for _, name := range sortedKeys(c.ContentDescriptors) {
+			cd := c.ContentDescriptors[name]
+			if cd != nil && strings.TrimSpace(cd.Description) == "" {
+				ctx.Report(Pointer("components", "contentDescriptors", name), "content descriptor %q has no description", cd.Name)
+			}
+		}
+	}
+}
+
+func (r *ParamDescriptionRule) check(ctx *Context, m *openrpc.Method, p *openrpc.ContentDescriptorOrReference, path []interface{}) {
+	switch {
+	case p == nil:
+	case p.IsContentDescriptor():
+		if strings.TrimSpace(p.ContentDescriptor.Description) == "" {
+			ctx.Report(Pointer(path...), "param %q of the method %q has no description", p.ContentDescriptor.Name, m.Name)
+		}
+	case p.IsOneOf():
+		for i, p := range p.OneOf.OneOf {
+			r.check(ctx, m, p, append(path[:len(path):len(path)], "oneOf", i))
+		}
+	}
+}
+
+// DeprecatedReplacementRule checks that every deprecated method names the method which replaces it.
+//
+// The replacement is named either by the ReplacedByExtension extension of the method, or by mentioning the name of the
+// other non-deprecated method in the summary or the description.
+type DeprecatedReplacementRule struct{}
+
+// NewDeprecatedReplacementRule returns the new DeprecatedReplacementRule.
+func NewDeprecatedReplacementRule() *DeprecatedReplacementRule { return new(DeprecatedReplacementRule) }
+
+// Name implements Rule.
+func (*DeprecatedReplacementRule) Name() string { return RuleDeprecatedReplacement }
+
+// Description implements Rule.
+func (*DeprecatedReplacementRule) Description() string {
+	return "Deprecated methods must say which method replaces them."
+}
+
+// DefaultSeverity implements Rule.
+func (*DeprecatedReplacementRule) DefaultSeverity() Severity { return Warning }
+
+// Check implements Rule.
+func (*DeprecatedReplacementRule) Check(ctx *Context) {
+	methods := make(map[string]*openrpc.Method, len(ctx.Schema.Methods))
+	for _, m := range ctx.Schema.Methods {
+		if m != nil {
+			methods[m.Name] = m
+		}
+	}
+
+	for i, m := range ctx.Schema.Methods {
+		if m == nil || !m.Deprecated {
+			continue
+		}
+
+		if ext := openrpc.LookupExtension(m.Extensions, ReplacedByExtension); ext != nil {
+			var name string
+			if err := json.Unmarshal(ext.Value, &name); err != nil || name == "" {
+				ctx.Report(Pointer("methods", i, ReplacedByExtension), "%s of the method %q must be the method name", ReplacedByExtension, m.Name)
+				continue
+			}
+			switch r, ok := methods[name]; {
+			case !ok:
+				ctx.Report(Pointer("methods", i, ReplacedByExtension), "method %q is replaced by the unknown method %q", m.Name, name)
+			case r == m:
+				ctx.Report(Pointer("methods", i, ReplacedByExtension), "method %q is replaced by itself", m.Name)
+			}
+			continue
+		}
+
+		if !mentionsReplacement(m, methods) {
+			ctx.Report(Pointer("methods", i), "deprecated method %q does not name its replacement", m.Name)
+		}
+	}
+}
+
+// mentionsReplacement reports whether the summary or the description of the method m mentions the other non-deprecated method.
+func mentionsReplacement(m *openrpc.Method, methods map[string]*openrpc.Method) bool {
+	text := m.Summary + "\n" + m.Description
+	for name, r := range methods {
+		if r != m && !r.Deprecated && containsWord(text, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWord reports whether the text contains the word which is not a part of the longer identifier.
+func containsWord(text, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if (start == 0 || !isIdentByte(text[start-1])) && (end == len(text) || !isIdentByte(text[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '.' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// DefaultMaxInlineProperties is the default max properties of the inline object schemas.
+const DefaultMaxInlineProperties = 10
+
+// MaxInlinePropertiesRule checks that the inline object schemas of the params and the results do not have more than
+// the max properties. The large object SHOULD be defined in the Components and referenced instead.
+//
+// The options are:
+//
+//	max: the max properties. Defaults to DefaultMaxInlineProperties.
+type MaxInlinePropertiesRule struct {
+	max int
+}
+
+var _ Configurable = (*MaxInlinePropertiesRule)(nil)
+
+// NewMaxInlinePropertiesRule returns the new MaxInlinePropertiesRule with the default options.
+func NewMaxInlinePropertiesRule() *MaxInlinePropertiesRule {
+	return &MaxInlinePropertiesRule{max: DefaultMaxInlineProperties}
+}
+
+// Name implements Rule.
+func (*MaxInlinePropertiesRule) Name() string { return RuleMaxInlineProperties }
+
+// Description implements Rule.
+func (r *MaxInlinePropertiesRule) Description() string {
+	return "Inline object schemas must not be larger than the max properties."
+}
+
+// DefaultSeverity implements Rule.
+func (*MaxInlinePropertiesRule) DefaultSeverity() Severity { return Warning }
+
+// Configure implements Configurable.
+func (r *MaxInlinePropertiesRule) Configure(decode func(v interface{}) error) error {
+	var opts struct {
+		Max *int `json:"max" yaml:"max"`
+	}
+	if err := decode(&opts); err != nil {
+		return err
+	}
+
+	if opts.Max != nil {
+		if *opts.Max < 0 {
+			return fmt.Errorf("max must be non-negative, got %d", *opts.Max)
+		}
+		r.max = *opts.Max
+	}
+
+	return nil
+}
+
+// Check implements Rule.
+func (r *MaxInlinePropertiesRule) Check(ctx *Context) {
+	for i, m := range ctx.Schema.Methods {
+		if m == nil {
+			continue
+		}
+		for j, p := range m.Params {
+			r.checkContentDescriptor(ctx, p, []interface{}{"methods", i, "params", j})
+		}
+		r.checkContentDescriptor(ctx, m.Result, []interface{}{"methods", i, "result"})
+	}
+}
+
+func (r *MaxInlinePropertiesRule) checkContentDescriptor(ctx *Context, cd *openrpc.ContentDescriptorOrReference, path []interface{}) {
+	switch {
+	case cd == nil:
+	case cd.IsContentDescriptor():
+		if s := cd.ContentDescriptor.Schema; s != nil {
+			r.checkSchema(ctx, s.Schema, append(path[:len(path):len(path)], "schema"))
+		}
+	case cd.IsOneOf():
+		for i, cd := range cd.OneOf.OneOf {
+			r.checkContentDescriptor(ctx, cd, append(path[:len(path):len(path)], "oneOf", i))
+		}
+	}
+}
+
+// checkSchema checks the schema s and its inline subschemas. The definitions are not inline, so they are not checked.
+func (r *MaxInlinePropertiesRule) checkSchema(ctx *Context, s *jsonschema.Schema, path []interface{}) {
+	if s == nil || s.Ref != nil {
+		return
+	}
+	if n := len(s.Properties); n > r.max {
+		ctx.Report(Pointer(path...), "inline object schema has %d properties, more than %d", n, r.max)
+	}
+
+	jsonschema.Subschemas(s, func(sub *jsonschema.Schema, keyword string, tokens []string) {
+		if keyword == "definitions" {
+			return
+		}
+		p := path[:len(path):len(path)]
+		for _, tok := range tokens {
+			p = append(p, tok)
+		}
+		r.checkSchema(ctx, sub, p)
+	})
+}
+
+// SpecVersionRule checks that the package supports the specification version of the document.
+//
+// The document of the unsupported minor version is validated against the nearest supported version, which may miss
+// the rules of its own version.
+type SpecVersionRule struct{}
+
+// NewSpecVersionRule returns the new SpecVersionRule.
+func NewSpecVersionRule() *SpecVersionRule { return new(SpecVersionRule) }
+
+// Name implements Rule.
+func (*SpecVersionRule) Name() string { return RuleSpecVersion }
+
+// Description implements Rule.
+func (*SpecVersionRule) Description() string {
+	return "The OpenRPC specification version must be supported."
+}
+
+// DefaultSeverity implements Rule.
+func (*SpecVersionRule) DefaultSeverity() Severity { return Warning }
+
+// Check implements Rule.
+func (*SpecVersionRule) Check(ctx *Context) {
+	if ctx.Schema.OpenRPC == "" {
+		return // reported by the validation
+	}
+
+	v, err := ctx.Schema.Version()
+	switch {
+	case err != nil:
+		ctx.Report(Pointer("openrpc"), "%q is not the semantic version number", ctx.Schema.OpenRPC)
+	case !v.IsSupported():
+		ctx.Report(Pointer("openrpc"), "unsupported version %s, must be one of %q", v, openrpc.SupportedVersions())
+	}
+}
+
+// sortedKeys returns the sorted keys of the map m.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*openrpc.ContentDescriptor:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,176 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrUnsupportedURI is the error of the Loader which does not load the URI of the unsupported scheme.
+var ErrUnsupportedURI = errors.New("openrpc: unsupported URI")
+
+// Loader loads the documents referred by the external references.
+type Loader interface {
+	// Load returns the content of the document at the uri, which is the URI reference resolved against the base URI
+	// of the referring document without the fragment.
+	//
+	// Load returns the error wrapping ErrUnsupportedURI if the Loader does not load the scheme of the uri.
+	Load(uri string) ([]byte, error)
+}
+
+// LoaderFunc is the function which implements Loader.
+type LoaderFunc func(uri string) ([]byte, error)
+
+// Load implements Loader.
+func (f LoaderFunc) Load(uri string) ([]byte, error) {
+	return f(uri)
+}
+
+// fsLoader loads the documents from the file system.
+type fsLoader struct {
+	fsys fs.FS
+}
+
+// FSLoader returns the Loader which loads the "file" URIs and the URIs without the scheme from fsys.
+//
+// The path of the URI is the path in fsys, where the leading slash is trimmed. So the base URI "openrpc.json" and
+// "/openrpc.json" of the root document both refer the file "openrpc.json" at the root of fsys.
+func FSLoader(fsys fs.FS) Loader {
+	return &fsLoader{fsys: fsys}
+}
+
+// Load implements Loader.
+func (l *fsLoader) Load(uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "" && u.Scheme != "file" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURI, uri)
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	return fs.ReadFile(l.fsys, name)
+}
+
+// MemoryLoader is the Loader which loads the documents from the map of the URIs to the contents.
+//
+// The URIs are matched exactly, so the relative URIs are the ones resolved against the base URI, such as "/schemas/block.json".
+type MemoryLoader map[string][]byte
+
+// Load implements Loader.
+func (l MemoryLoader) Load(uri string) ([]byte, error) {
+	data, ok := l[uri]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", uri, fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+// DefaultMaxHTTPSize is the maximum size of the document loaded by the HTTPLoader whose MaxSize is zero.
+const DefaultMaxHTTPSize = 10 << 20
+
+// HTTPLoader is the Loader which loads the "http" and "https" URIs.
+//
+// The zero HTTPLoader does not fetch anything, so that the documents are never fetched over the network unless the
+// loading is enabled explicitly.
+type HTTPLoader struct {
+	// Client is the HTTP client. The nil Client is http.DefaultClient.
+	Client *http.Client
+
+	// Enabled enables the loading over the network.
+	Enabled bool
+
+	// MaxSize is the maximum size of the loaded document in bytes. The zero MaxSize is DefaultMaxHTTPSize.
+	MaxSize int64
+}
+
+// httpContentTypes are the media types of the documents loaded by the HTTPLoader.
+var httpContentTypes = map[string]bool{
+	"application/json":   true,
+	"application/yaml":   true,
+	"application/x-yaml": true,
+	"text/yaml":          true,
+	"text/x-yaml":        true,
+	"text/plain":         true,
+}
+
+// Load implements Loader.
+//
+// Load fails if the response is not 200 OK, if its Content-Type is neither JSON, YAML nor the plain text, or if its
+// body exceeds the MaxSize.
+func (l *HTTPLoader) Load(uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURI, uri)
+	}
+	if !l.Enabled {
+		return nil, fmt.Errorf("loading %s over HTTP is not enabled", uri)
+	}
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", uri, resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || !httpContentTypes[mt] && !strings.HasSuffix(mt, "+json") && !strings.HasSuffix(mt, "+yaml") {
+			return nil, fmt.Errorf("GET %s: unsupported Content-Type %q", uri, ct)
+		}
+	}
+
+	limit := l.MaxSize
+	if limit <= 0 {
+		limit = DefaultMaxHTTPSize
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("GET %s: the document exceeds %d bytes", uri, limit)
+	}
+	return data, nil
+}
+
+// multiLoader tries the loaders in order.
+type multiLoader []Loader
+
+// MultiLoader returns the Loader which tries the loaders in order, until the loader which supports the URI.
+func MultiLoader(loaders ...Loader) Loader {
+	return multiLoader(loaders)
+}
+
+// Load implements Loader.
+func (l multiLoader) Load(uri string) ([]byte, error) {
+	for _, loader := range l {
+		data, err := loader.Load(uri)
+		if errors.Is(err, ErrUnsupportedURI) {
+			continue
+		}
+		return data, err
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedURI, uri)
+}
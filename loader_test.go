@@ -0,0 +1,156 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHTTPLoader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/doc.json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"a": 1}`))
+		case "/doc.yaml":
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write([]byte(`a: 1`))
+		case "/doc.openrpc":
+			w.Header().Set("Content-Type", "application/openrpc+json")
+			w.Write([]byte(`{"a": 1}`))
+		case "/doc.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html></html>`))
+		case "/large.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`"` + strings.Repeat("x", 100) + `"`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		loader  *HTTPLoader
+		path    string
+		want    string
+		wantErr string
+	}{
+		"json":        {loader: &HTTPLoader{Enabled: true}, path: "/doc.json", want: `{"a": 1}`},
+		"yaml":        {loader: &HTTPLoader{Enabled: true}, path: "/doc.yaml", want: `a: 1`},
+		"suffix":      {loader: &HTTPLoader{Enabled: true}, path: "/doc.openrpc", want: `{"a": 1}`},
+		"client":      {loader: &HTTPLoader{Client: srv.Client(), Enabled: true}, path: "/doc.json", want: `{"a": 1}`},
+		"notEnabled":  {loader: &HTTPLoader{}, path: "/doc.json", wantErr: "not enabled"},
+		"notFound":    {loader: &HTTPLoader{Enabled: true}, path: "/none.json", wantErr: "404"},
+		"contentType": {loader: &HTTPLoader{Enabled: true}, path: "/doc.html", wantErr: "Content-Type"},
+		"maxSize":     {loader: &HTTPLoader{Enabled: true, MaxSize: 100}, path: "/large.json", wantErr: "exceeds 100 bytes"},
+		"exactSize":   {loader: &HTTPLoader{Enabled: true, MaxSize: 102}, path: "/large.json", want: `"` + strings.Repeat("x", 100) + `"`},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			data, err := tt.loader.Load(srv.URL + tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load = %v, want the error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Fatalf("Load = %q, want %q", data, tt.want)
+			}
+		})
+	}
+
+	if _, err := new(HTTPLoader).Load("file:///doc.json"); !errors.Is(err, ErrUnsupportedURI) {
+		t.Fatalf("Load of the file URI = %v, want ErrUnsupportedURI", err)
+	}
+}
+
+func TestLoaders(t *testing.T) {
+	fsys := fstest.MapFS{"specs/a.json": {Data: []byte(`"fs"`)}}
+	loader := MultiLoader(new(HTTPLoader), FSLoader(fsys), MemoryLoader{"mem:a": []byte(`"mem"`)})
+
+	tests := map[string]struct {
+		want    string
+		wantErr error
+	}{
+		"specs/a.json":           {want: `"fs"`},
+		"/specs/a.json":          {want: `"fs"`},
+		"file:///specs/a.json":   {want: `"fs"`},
+		"/specs/../specs/a.json": {want: `"fs"`},
+		"/specs/b.json":          {wantErr: fs.ErrNotExist},
+		"mem:a":                  {want: `"mem"`},
+		"mem:b":                  {wantErr: fs.ErrNotExist},
+	}
+	for uri, tt := range tests {
+		data, err := loader.Load(uri)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Load(%q) = %v, want %v", uri, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || string(data) != tt.want {
+			t.Errorf("Load(%q) = %q, %v, want %q", uri, data, err, tt.want)
+		}
+	}
+
+	if _, err := MultiLoader(new(HTTPLoader)).Load("/a.json"); !errors.Is(err, ErrUnsupportedURI) {
+		t.Errorf("MultiLoader without the supporting loader = %v, want ErrUnsupportedURI", err)
+	}
+}
+
+func TestResolverConcurrentLoad(t *testing.T) {
+	// the slow load must not block the resolution of the root document
+	release := make(chan struct{})
+	var calls int
+	var mu sync.Mutex
+	loader := LoaderFunc(func(uri string) ([]byte, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return []byte(`{"components": {"errors": {"e": {"code": 1, "message": "x"}}}}`), nil
+	})
+	s := resolveTestSchema(t, resolveTestDocument)
+	r := NewResolver(s, WithLoader(loader), WithBaseURI("/openrpc.json"))
+
+	const n = 4
+	results := make(chan *Error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			e, err := r.ResolveError("/common.json#/components/errors/e")
+			if err != nil {
+				t.Error(err)
+			}
+			results <- e
+		}()
+	}
+	if _, err := r.ResolveError("#/components/errors/e"); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+
+	first := <-results
+	for i := 1; i < n; i++ {
+		if e := <-results; e != first {
+			t.Fatal("the concurrent loads resolved to the different objects")
+		}
+	}
+	if calls == 0 || calls > n {
+		t.Fatalf("loaded %d times", calls)
+	}
+}
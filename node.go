@@ -0,0 +1,321 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// nodeKind is the kind of the node.
+type nodeKind uint8
+
+const (
+	nullNode nodeKind = iota
+	boolNode
+	numberNode
+	stringNode
+	arrayNode
+	objectNode
+)
+
+// String implements fmt.Stringer.
+func (k nodeKind) String() string {
+	switch k {
+	case nullNode:
+		return "null"
+	case boolNode:
+		return "boolean"
+	case numberNode:
+		return "number"
+	case stringNode:
+		return "string"
+	case arrayNode:
+		return "array"
+	case objectNode:
+		return "object"
+	default:
+		return fmt.Sprintf("nodeKind(%d)", k)
+	}
+}
+
+// node is a position-aware JSON value of the document.
+type node struct {
+	kind nodeKind
+
+	// value is the string value, the number literal or the "true" or "false" literal of the scalar node.
+	value string
+
+	// keys holds the object member names in document order.
+	keys []string
+
+	// children holds the object member values or the array elements in document order.
+	children []*node
+
+	// keyOffsets holds the byte offsets of the object member names in the JSON text.
+	keyOffsets []int
+
+	// raw is the JSON text of the node, or nil if the node is not parsed from the JSON text.
+	raw []byte
+
+	// offset is the byte offset of the node in the JSON text, or -1 if unknown.
+	offset int
+
+	// line and column are the 1-based position of the node which is not parsed from the JSON text.
+	line, column int
+}
+
+// member returns the value of the object member whose name is key, or nil if the n does not have it.
+func (n *node) member(key string) *node {
+	for i, k := range n.keys {
+		if k == key {
+			return n.children[i]
+		}
+	}
+	return nil
+}
+
+// lookup returns the descendant of n at the JSON Pointer reference tokens, or nil if the n does not have it.
+func (n *node) lookup(tokens []string) *node {
+	for _, tok := range tokens {
+		switch n.kind {
+		case objectNode:
+			n = n.member(tok)
+		case arrayNode:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(n.children) {
+				return nil
+			}
+			n = n.children[i]
+		default:
+			n = nil
+		}
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// JSON returns the JSON text of the n.
+func (n *node) JSON() []byte {
+	if n.raw != nil {
+		return n.raw
+	}
+	return n.appendJSON(nil)
+}
+
+// appendJSON appends the compact JSON text of the n to buf.
+func (n *node) appendJSON(buf []byte) []byte {
+	if n.raw != nil {
+		return append(buf, n.raw...)
+	}
+
+	switch n.kind {
+	case nullNode:
+		return append(buf, "null"...)
+	case boolNode, numberNode:
+		return append(buf, n.value...)
+	case stringNode:
+		return appendString(buf, n.value)
+	case arrayNode:
+		buf = append(buf, '[')
+		for i, child := range n.children {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = child.appendJSON(buf)
+		}
+		return append(buf, ']')
+	default:
+		buf = append(buf, '{')
+		for i, child := range n.children {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendString(buf, n.keys[i])
+			buf = append(buf, ':')
+			buf = child.appendJSON(buf)
+		}
+		return append(buf, '}')
+	}
+}
+
+// appendString appends the JSON string literal of s to buf.
+func appendString(buf []byte, s string) []byte {
+	b, _ := json.Marshal(s) // never fails
+	return append(buf, b...)
+}
+
+// nodeParser parses the JSON text into the node tree with encoding/json, and locates the tokens in the JSON text.
+type nodeParser struct {
+	data []byte
+	dec  *json.Decoder
+
+	// end is the byte offset just after the last token.
+	end int
+}
+
+// parseNode parses the JSON text data into the node tree.
+//
+// The syntax error is reported as *DecodeError.
+func parseNode(data []byte) (*node, error) {
+	p := &nodeParser{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	p.dec.UseNumber()
+
+	n, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if rest := bytes.TrimLeft(data[p.end:], " \t\r\n"); len(rest) > 0 {
+		return nil, p.errorAt(len(data)-len(rest), "invalid character %q after top-level value", rest[0])
+	}
+
+	return n, nil
+}
+
+func (p *nodeParser) errorAt(offset int, format string, args ...interface{}) error {
+	line, column := lineColumn(p.data, offset)
+	return &DecodeError{
+		Line:   line,
+		Column: column,
+		Offset: int64(offset),
+		Err:    fmt.Errorf(format, args...),
+	}
+}
+
+// skipSpace returns the offset of the next token which follows the offset in the JSON text.
+func (p *nodeParser) skipSpace(offset int) int {
+	for offset < len(p.data) {
+		switch p.data[offset] {
+		case ' ', '\t', '\n', '\r', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// token returns the next token and its byte offset in the JSON text.
+func (p *nodeParser) token() (json.Token, int, error) {
+	tok, err := p.dec.Token()
+	if err != nil {
+		var serr *json.SyntaxError
+		switch {
+		case errors.As(err, &serr):
+			offset := int(serr.Offset)
+			if offset > 0 && !strings.HasPrefix(serr.Error(), "unexpected end") {
+				offset-- // the offset is after the offending byte
+			}
+			return nil, 0, p.errorAt(offset, "%s", serr)
+		case err == io.EOF, err == io.ErrUnexpectedEOF:
+			return nil, 0, p.errorAt(len(p.data), "unexpected end of JSON input")
+		default:
+			return nil, 0, err
+		}
+	}
+
+	start := p.skipSpace(p.end)
+	p.end = int(p.dec.InputOffset())
+
+	return tok, start, nil
+}
+
+func (p *nodeParser) parseValue() (*node, error) {
+	tok, start, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+
+	var n *node
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '{' {
+			n, err = p.parseObject()
+		} else {
+			n, err = p.parseArray()
+		}
+		if err != nil {
+			return nil, err
+		}
+	case string:
+		n = &node{kind: stringNode, value: tok}
+	case json.Number:
+		n = &node{kind: numberNode, value: string(tok)}
+	case bool:
+		n = &node{kind: boolNode, value: strconv.FormatBool(tok)}
+	default:
+		n = &node{kind: nullNode, value: "null"}
+	}
+
+	n.offset = start
+	n.raw = p.data[start:p.end:p.end]
+
+	return n, nil
+}
+
+func (p *nodeParser) parseArray() (*node, error) {
+	n := &node{kind: arrayNode}
+	for p.dec.More() {
+		child, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, child)
+	}
+
+	if _, _, err := p.token(); err != nil { // ']'
+		return nil, err
+	}
+	return n, nil
+}
+
+func (p *nodeParser) parseObject() (*node, error) {
+	n := &node{kind: objectNode}
+	for p.dec.More() {
+		tok, keyOffset, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		child, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		n.keys = append(n.keys, tok.(string))
+		n.children = append(n.children, child)
+		n.keyOffsets = append(n.keyOffsets, keyOffset)
+	}
+
+	if _, _, err := p.token(); err != nil { // '}'
+		return nil, err
+	}
+	return n, nil
+}
+
+// lineColumn returns the 1-based line and column of the byte offset in data.
+func lineColumn(data []byte, offset int) (line, column int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+
+	line, column = 1, 1
+	for _, c := range data[:offset] {
+		if c == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+
+	return line, column
+}
@@ -0,0 +1,263 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var nodeTestInputs = []string{
+	`null`,
+	`true`,
+	`false`,
+	`0`,
+	`-1.5e+10`,
+	`"aé\n\"b\\"`,
+	`"😀"`,
+	"\"\xff\"",
+	`[]`,
+	`{}`,
+	` { "a" : [ 1 , 2 , { "b" : null } ] , "c" : "d" } `,
+	"{\n  \"openrpc\": \"1.2.6\",\n  \"methods\": [\n    {\"name\": \"a\", \"params\": []}\n  ]\n}\n",
+	`{"a": 1, "a": 2}`,
+	`{"": {"~1/": [[[]]]}}`,
+	`[1, "2", [3], {"4": 5}, true, null]`,
+
+	// invalid
+	``,
+	`   `,
+	`{`,
+	`{"a"}`,
+	`{"a": }`,
+	`{"a": 1,}`,
+	`[1,]`,
+	`[1 2]`,
+	`{} {}`,
+	`{},`,
+	`01`,
+	`1.`,
+	`-`,
+	`nul`,
+	`tru`,
+	`"abc`,
+	"\"a\tb\"",
+	`"\x"`,
+	`{1: 2}`,
+	`[}`,
+}
+
+// nodeValue returns the node n as the value decoded by encoding/json with UseNumber.
+func nodeValue(n *node) interface{} {
+	switch n.kind {
+	case nullNode:
+		return nil
+	case boolNode:
+		return n.value == "true"
+	case numberNode:
+		return json.Number(n.value)
+	case stringNode:
+		return n.value
+	case arrayNode:
+		a := make([]interface{}, len(n.children))
+		for i, child := range n.children {
+			a[i] = nodeValue(child)
+		}
+		return a
+	default:
+		m := make(map[string]interface{}, len(n.keys))
+		for i, key := range n.keys {
+			m[key] = nodeValue(n.children[i])
+		}
+		return m
+	}
+}
+
+// decodeValue decodes data by encoding/json with UseNumber.
+func decodeValue(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// checkNodeOffsets checks that the offsets and the raw JSON texts of the n and its descendants locate the values in data.
+func checkNodeOffsets(t testing.TB, data []byte, n *node) {
+	t.Helper()
+
+	if n.offset < 0 || n.offset+len(n.raw) > len(data) || !bytes.Equal(data[n.offset:n.offset+len(n.raw)], n.raw) {
+		t.Fatalf("node at %d does not locate its raw text %q", n.offset, n.raw)
+	}
+	v, err := decodeValue(n.raw)
+	if err != nil {
+		t.Fatalf("raw text %q at %d: %v", n.raw, n.offset, err)
+	}
+	if want := nodeValue(n); !reflect.DeepEqual(v, want) {
+		t.Fatalf("raw text %q at %d is %#v, want %#v", n.raw, n.offset, v, want)
+	}
+
+	for i, child := range n.children {
+		if n.kind == objectNode {
+			off := n.keyOffsets[i]
+			var key string
+			if data[off] != '"' || json.Unmarshal(data[off:stringEnd(data, off)], &key) != nil || key != n.keys[i] {
+				t.Fatalf("key offset %d does not locate the key %q", off, n.keys[i])
+			}
+		}
+		checkNodeOffsets(t, data, child)
+	}
+}
+
+// checkParseNode checks that parseNode agrees with encoding/json on data.
+func checkParseNode(t testing.TB, data []byte) {
+	t.Helper()
+
+	n, err := parseNode(data)
+	want, werr := decodeValue(data)
+	if werr == nil && !json.Valid(data) {
+		werr = errors.New("trailing data") // Decode reads only the first value
+	}
+
+	if (err == nil) != (werr == nil) {
+		t.Fatalf("parseNode(%q) error = %v, encoding/json error = %v", data, err, werr)
+	}
+	if err != nil {
+		var derr *DecodeError
+		if !errors.As(err, &derr) {
+			t.Fatalf("parseNode(%q) error = %T, want *DecodeError", data, err)
+		}
+		if derr.Offset < 0 || derr.Offset > int64(len(data)) || derr.Line < 1 || derr.Column < 1 {
+			t.Fatalf("parseNode(%q) error is not located: %+v", data, derr)
+		}
+		return
+	}
+
+	if got := nodeValue(n); !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseNode(%q) = %#v, want %#v", data, got, want)
+	}
+	checkNodeOffsets(t, data, n)
+}
+
+func TestParseNode(t *testing.T) {
+	for _, in := range nodeTestInputs {
+		checkParseNode(t, []byte(in))
+	}
+}
+
+func TestParseNodeError(t *testing.T) {
+	tests := map[string]struct {
+		data         string
+		line, column int
+		offset       int64
+		msg          string
+	}{
+		"badValue":     {data: "{\n  \"a\": }", line: 2, column: 8, offset: 9},
+		"trailing":     {data: "{}\n\n  x", line: 3, column: 3, offset: 6, msg: "after top-level value"},
+		"trailingNode": {data: "{} {}", line: 1, column: 4, offset: 3, msg: "after top-level value"},
+		"truncated":    {data: "{\"a\": [1,\n", line: 2, column: 1, offset: 10, msg: "unexpected end of JSON input"},
+		"empty":        {data: "", line: 1, column: 1, offset: 0, msg: "unexpected end of JSON input"},
+		"badKey":       {data: "{\n1: 2}", line: 2, column: 1, offset: 2},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			_, err := parseNode([]byte(tt.data))
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Fatalf("got %v, want *DecodeError", err)
+			}
+			if derr.Line != tt.line || derr.Column != tt.column || derr.Offset != tt.offset {
+				t.Fatalf("got %d:%d (%d), want %d:%d (%d): %v", derr.Line, derr.Column, derr.Offset, tt.line, tt.column, tt.offset, err)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("got %q, want the message %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseNodeDepth(t *testing.T) {
+	deep := strings.Repeat("[", 5000) + strings.Repeat("]", 5000)
+	if _, err := parseNode([]byte(deep)); err != nil {
+		t.Fatalf("depth 5000: %v", err)
+	}
+
+	tooDeep := strings.Repeat("[", 20000) + strings.Repeat("]", 20000)
+	if _, err := parseNode([]byte(tooDeep)); err == nil {
+		t.Fatal("depth 20000 succeeded")
+	}
+}
+
+func TestNodeJSON(t *testing.T) {
+	n, err := parseNode([]byte(` {"b": [1, "x\n", {"c": null}], "a": true} `))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the node tree which is not parsed from the JSON text is encoded compactly in the key order
+	var strip func(n *node)
+	strip = func(n *node) {
+		n.raw = nil
+		for _, child := range n.children {
+			strip(child)
+		}
+	}
+	strip(n)
+	if got, want := string(n.JSON()), `{"b":[1,"x\n",{"c":null}],"a":true}`; got != want {
+		t.Fatalf("JSON() = %s, want %s", got, want)
+	}
+}
+
+func TestNodeLookup(t *testing.T) {
+	n, err := parseNode([]byte(`{"a": [{"b/c": 1}, {"d": 2}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		tokens []string
+		want   string
+	}{
+		"root":     {tokens: nil, want: `{"a": [{"b/c": 1}, {"d": 2}]}`},
+		"element":  {tokens: []string{"a", "1", "d"}, want: `2`},
+		"slashKey": {tokens: []string{"a", "0", "b/c"}, want: `1`},
+		"missing":  {tokens: []string{"a", "2"}},
+		"notIndex": {tokens: []string{"a", "x"}},
+		"scalar":   {tokens: []string{"a", "1", "d", "e"}},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			got := n.lookup(tt.tokens)
+			switch {
+			case tt.want == "" && got != nil:
+				t.Fatalf("lookup(%q) = %s, want nil", tt.tokens, got.raw)
+			case tt.want != "" && (got == nil || string(got.raw) != tt.want):
+				t.Fatalf("lookup(%q) = %v, want %s", tt.tokens, got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseNode(f *testing.F) {
+	for _, in := range nodeTestInputs {
+		f.Add([]byte(in))
+	}
+	f.Add([]byte(streamTestDocument))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) > 4096 {
+			return // checkNodeOffsets is quadratic in the nesting depth
+		}
+		checkParseNode(t, data)
+	})
+}
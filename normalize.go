@@ -0,0 +1,355 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/zchee/go-openrpc/internal/jsonschema"
+)
+
+// Normalize returns the copy of the document s, in which the inline content descriptors and JSON Schemas repeated in
+// the document are extracted into the Components and replaced with the references to them.
+//
+// The inline ContentDescriptors of the methods which are identical to each other, or to the one of the
+// Components.ContentDescriptors, are replaced with the references, and the extracted ones are named after their names.
+// Then the schemas of the content descriptors and the subschemas nested in them and in the Components.Schemas are
+// normalized in the same way, and the extracted ones are named after their titles, their property or definition names,
+// or the names of their content descriptors or components, in order of preference. The names colliding with the
+// existing components are suffixed with the sequence number.
+//
+// The objects are identical if their canonical JSON encodings are the same. Only the schemas which have the title, the
+// enum or any subschema are extracted, and the nested subschemas which have the "$id" or the local references other
+// than the ones to the Components are left inline, as they depend on their root schemas.
+// The outermost repeated schemas are extracted first, and the subschemas of the extracted ones are normalized until no
+// schema is repeated, so the same document always produces the same normalized document.
+func (s *Schema) Normalize() (*Schema, error) {
+	ns := new(Schema)
+	if err := cloneJSON(ns, s); err != nil {
+		return nil, err
+	}
+
+	n := &normalizer{schema: ns}
+	if err := n.contentDescriptors(); err != nil {
+		return nil, err
+	}
+	for {
+		changed, err := n.schemas()
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			break
+		}
+	}
+
+	return ns, nil
+}
+
+// normalizer extracts the repeated objects of the schema into its Components.
+type normalizer struct {
+	schema *Schema
+}
+
+func (n *normalizer) components() *Components {
+	if n.schema.Components == nil {
+		n.schema.Components = new(Components)
+	}
+	return n.schema.Components
+}
+
+// canonicalKey returns the key of v which is the same for the structurally identical values.
+func canonicalKey(v interface{}) (string, error) {
+	data, err := MarshalCanonical(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// uniqueComponentName returns the name derived from the hint, or the fallback if the hint has no allowed character,
+// which is not taken.
+func uniqueComponentName(hint, fallback string, taken func(name string) bool) string {
+	name := sanitizeComponentName(hint)
+	if name == "" {
+		name = fallback
+	}
+	if !taken(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		if n := name + "_" + strconv.Itoa(i); !taken(n) {
+			return n
+		}
+	}
+}
+
+// eachMethodContentDescriptor calls fn with the inline content descriptors of the methods in order.
+func (n *normalizer) eachMethodContentDescriptor(fn func(cd *ContentDescriptorOrReference) error) error {
+	var visit func(cd *ContentDescriptorOrReference) error
+	visit = func(cd *ContentDescriptorOrReference) error {
+		switch {
+		case cd.IsOneOf():
+			for _, cd := range cd.OneOf.OneOf {
+				if err := visit(cd); err != nil {
+					return err
+				}
+			}
+		case cd.IsContentDescriptor():
+			return fn(cd)
+		}
+		return nil
+	}
+
+	for _, m := range n.schema.Methods {
+		if m == nil {
+			continue
+		}
+		for _, p := range m.Params {
+			if err := visit(p); err != nil {
+				return err
+			}
+		}
+		if err := visit(m.Result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contentDescriptors extracts the repeated inline content descriptors of the methods.
+func (n *normalizer) contentDescriptors() error {
+	known := make(map[string]string)
+	if comps := n.schema.Components; comps != nil {
+		for _, name := range sortedMapKeys(comps.ContentDescriptors) {
+			cd := comps.ContentDescriptors[name]
+			if cd == nil {
+				continue
+			}
+			key, err := canonicalKey(cd)
+			if err != nil {
+				return err
+			}
+			if _, ok := known[key]; !ok {
+				known[key] = name
+			}
+		}
+	}
+
+	counts := make(map[string]int)
+	if err := n.eachMethodContentDescriptor(func(cd *ContentDescriptorOrReference) error {
+		key, err := canonicalKey(cd.ContentDescriptor)
+		if err != nil {
+			return err
+		}
+		counts[key]++
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return n.eachMethodContentDescriptor(func(cd *ContentDescriptorOrReference) error {
+		key, err := canonicalKey(cd.ContentDescriptor)
+		if err != nil {
+			return err
+		}
+		name, ok := known[key]
+		if !ok {
+			if counts[key] < 2 {
+				return nil
+			}
+			comps := n.components()
+			if comps.ContentDescriptors == nil {
+				comps.ContentDescriptors = make(map[string]*ContentDescriptor)
+			}
+			name = uniqueComponentName(cd.ContentDescriptor.Name, "contentDescriptor", func(name string) bool {
+				_, ok := comps.ContentDescriptors[name]
+				return ok
+			})
+			comps.ContentDescriptors[name] = cd.ContentDescriptor
+			known[key] = name
+		}
+		*cd = ContentDescriptorOrReference{Reference: &Reference{Ref: componentRef("contentDescriptors", name)}}
+		return nil
+	})
+}
+
+// schemaSite is the JSONSchema whose subschemas are normalized.
+type schemaSite struct {
+	slot **JSONSchema
+
+	// hint is the name of the content descriptor or the component of the JSONSchema.
+	hint string
+
+	// component reports whether the JSONSchema is the one of the Components.Schemas, which is not extracted itself.
+	component bool
+}
+
+// schemaSites returns the JSONSchemas of the inline content descriptors and the Components.Schemas in order.
+func (n *normalizer) schemaSites() []schemaSite {
+	var sites []schemaSite
+	n.eachMethodContentDescriptor(func(cd *ContentDescriptorOrReference) error {
+		sites = append(sites, schemaSite{slot: &cd.ContentDescriptor.Schema, hint: cd.ContentDescriptor.Name})
+		return nil
+	})
+	if comps := n.schema.Components; comps != nil {
+		for _, name := range sortedMapKeys(comps.ContentDescriptors) {
+			if cd := comps.ContentDescriptors[name]; cd != nil {
+				sites = append(sites, schemaSite{slot: &cd.Schema, hint: cd.Name})
+			}
+		}
+		for _, name := range sortedMapKeys(comps.Schemas) {
+			js := comps.Schemas[name]
+			sites = append(sites, schemaSite{slot: &js, hint: name, component: true})
+		}
+	}
+	return sites
+}
+
+// schemas extracts the outermost repeated schemas once, and reports whether any schema is replaced with the reference.
+func (n *normalizer) schemas() (bool, error) {
+	known := make(map[string]string)
+	if comps := n.schema.Components; comps != nil {
+		for _, name := range sortedMapKeys(comps.Schemas) {
+			js := comps.Schemas[name]
+			if js == nil || js.Schema == nil || js.Ref != nil {
+				continue
+			}
+			key, err := canonicalKey(js)
+			if err != nil {
+				return false, err
+			}
+			if _, ok := known[key]; !ok {
+				known[key] = name
+			}
+		}
+	}
+
+	sites := n.schemaSites()
+	counts := make(map[string]int)
+	hints := make(map[string]string)
+	for _, site := range sites {
+		js := *site.slot
+		if js == nil || js.Schema == nil {
+			continue
+		}
+		var err error
+		jsonschema.Walk(js.Schema, "", func(s *jsonschema.Schema, tokens []string, _ string) bool {
+			root := len(tokens) == 0
+			if err != nil || root && site.component || !extractable(s, root) {
+				return err == nil
+			}
+			var key string
+			if root {
+				key, err = canonicalKey(js)
+			} else {
+				key, err = canonicalKey(&JSONSchema{Schema: s})
+			}
+			counts[key]++
+			if _, ok := hints[key]; !ok {
+				hints[key] = schemaHint(s, tokens, site.hint)
+			}
+			return err == nil
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	changed := false
+	extract := func(key string, js *JSONSchema) *jsonschema.Schema {
+		name, ok := known[key]
+		if !ok {
+			if counts[key] < 2 {
+				return nil
+			}
+			comps := n.components()
+			if comps.Schemas == nil {
+				comps.Schemas = make(map[string]*JSONSchema)
+			}
+			name = uniqueComponentName(hints[key], "schema", func(name string) bool {
+				_, ok := comps.Schemas[name]
+				return ok
+			})
+			comps.Schemas[name] = js
+			known[key] = name
+		}
+		changed = true
+		ref := componentRef("schemas", name)
+		return &jsonschema.Schema{Ref: &ref}
+	}
+
+	for _, site := range sites {
+		js := *site.slot
+		if js == nil || js.Schema == nil {
+			continue
+		}
+		if !site.component && extractable(js.Schema, true) {
+			key, err := canonicalKey(js)
+			if err != nil {
+				return false, err
+			}
+			if ref := extract(key, js); ref != nil {
+				*site.slot = &JSONSchema{Schema: ref}
+				continue
+			}
+		}
+
+		var err error
+		jsonschema.Replace(js.Schema, func(s *jsonschema.Schema) *jsonschema.Schema {
+			if err != nil || !extractable(s, false) {
+				return nil
+			}
+			c := &JSONSchema{Schema: s}
+			var key string
+			if key, err = canonicalKey(c); err != nil {
+				return nil
+			}
+			return extract(key, c)
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return changed, nil
+}
+
+// extractable reports whether the schema s can be extracted into the Components. The root schema may have the "$id"s
+// and the local references, which are moved with it.
+func extractable(s *jsonschema.Schema, root bool) bool {
+	if s.IsBool() || s.Ref != nil {
+		return false
+	}
+
+	nested, ok := false, true
+	jsonschema.Walk(s, "", func(sub *jsonschema.Schema, tokens []string, _ string) bool {
+		if len(tokens) > 0 {
+			nested = true
+		}
+		if !root && (sub.ID != "" || sub.Ref != nil && strings.HasPrefix(*sub.Ref, "#") && !strings.HasPrefix(*sub.Ref, "#/components/")) {
+			ok = false
+		}
+		return ok && !(root && nested)
+	})
+
+	return ok && (s.Title != "" || len(s.Enum) > 0 || nested)
+}
+
+// schemaHint returns the hint of the name of the schema s at the JSON Pointer reference tokens in the JSONSchema, whose
+// hint is siteHint.
+func schemaHint(s *jsonschema.Schema, tokens []string, siteHint string) string {
+	if s.Title != "" {
+		return s.Title
+	}
+	if n := len(tokens); n >= 2 {
+		switch tokens[n-2] {
+		case "properties", "definitions":
+			return tokens[n-1]
+		}
+	}
+	return siteHint
+}
@@ -0,0 +1,192 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"testing"
+)
+
+// normalizeTestDocument returns the document of the methods and the components.
+func normalizeTestDocument(t *testing.T, methods, components string) *Schema {
+	t.Helper()
+
+	if components == "" {
+		components = `{}`
+	}
+	return resolveTestSchema(t, `{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": `+methods+`, "components": `+components+`}`)
+}
+
+func TestNormalize(t *testing.T) {
+	const (
+		address = `{"title": "Address", "type": "object", "properties": {"city": {"type": "string"}}}`
+		color   = `{"enum": ["red", "green"]}`
+		point   = `{"type": "object", "properties": {"x": {"type": "number"}, "y": {"type": "number"}}}`
+	)
+	tests := map[string]struct {
+		methods, components string
+		wantMethods         string
+		wantComponents      string
+	}{
+		"unique": {
+			methods:        `[{"name": "a", "params": [{"name": "id", "schema": ` + address + `}]}]`,
+			wantMethods:    `[{"name": "a", "params": [{"name": "id", "schema": ` + address + `}]}]`,
+			wantComponents: `{}`,
+		},
+		"contentDescriptor": {
+			methods: `[
+				{"name": "a", "params": [{"name": "id", "schema": {"type": "string"}}], "result": {"name": "id", "schema": {"type": "string"}}},
+				{"name": "b", "params": [{"oneOf": [{"name": "id", "schema": {"type": "string"}}]}]}
+			]`,
+			wantMethods: `[
+				{"name": "a", "params": [{"$ref": "#/components/contentDescriptors/id"}], "result": {"$ref": "#/components/contentDescriptors/id"}},
+				{"name": "b", "params": [{"oneOf": [{"$ref": "#/components/contentDescriptors/id"}]}]}
+			]`,
+			wantComponents: `{"contentDescriptors": {"id": {"name": "id", "schema": {"type": "string"}}}}`,
+		},
+		"knownContentDescriptor": {
+			methods:        `[{"name": "a", "params": [{"name": "id", "schema": {}}, {"name": "x", "schema": {}}]}]`,
+			components:     `{"contentDescriptors": {"known": {"name": "id", "schema": {}}}}`,
+			wantMethods:    `[{"name": "a", "params": [{"$ref": "#/components/contentDescriptors/known"}, {"name": "x", "schema": {}}]}]`,
+			wantComponents: `{"contentDescriptors": {"known": {"name": "id", "schema": {}}}}`,
+		},
+		"contentDescriptorCollision": {
+			methods:        `[{"name": "a", "params": [{"name": "id", "schema": {}}]}, {"name": "b", "params": [{"name": "id", "schema": {}}]}]`,
+			components:     `{"contentDescriptors": {"id": {"name": "other", "schema": {}}}}`,
+			wantMethods:    `[{"name": "a", "params": [{"$ref": "#/components/contentDescriptors/id_2"}]}, {"name": "b", "params": [{"$ref": "#/components/contentDescriptors/id_2"}]}]`,
+			wantComponents: `{"contentDescriptors": {"id": {"name": "other", "schema": {}}, "id_2": {"name": "id", "schema": {}}}}`,
+		},
+		"schemaByTitle": {
+			methods:        `[{"name": "a", "params": [{"name": "from", "schema": ` + address + `}, {"name": "to", "schema": ` + address + `}]}]`,
+			wantMethods:    `[{"name": "a", "params": [{"name": "from", "schema": {"$ref": "#/components/schemas/Address"}}, {"name": "to", "schema": {"$ref": "#/components/schemas/Address"}}]}]`,
+			wantComponents: `{"schemas": {"Address": ` + address + `}}`,
+		},
+		"nestedByProperty": {
+			methods:        `[{"name": "a", "params": [{"name": "line", "schema": {"properties": {"start": ` + point + `, "end": ` + point + `}}}]}]`,
+			wantMethods:    `[{"name": "a", "params": [{"name": "line", "schema": {"properties": {"start": {"$ref": "#/components/schemas/end"}, "end": {"$ref": "#/components/schemas/end"}}}}]}]`,
+			wantComponents: `{"schemas": {"end": ` + point + `}}`,
+		},
+		"enumByContentDescriptor": {
+			methods:        `[{"name": "a", "params": [{"name": "fg", "schema": ` + color + `}, {"name": "bg", "schema": ` + color + `}]}]`,
+			wantMethods:    `[{"name": "a", "params": [{"name": "fg", "schema": {"$ref": "#/components/schemas/fg"}}, {"name": "bg", "schema": {"$ref": "#/components/schemas/fg"}}]}]`,
+			wantComponents: `{"schemas": {"fg": ` + color + `}}`,
+		},
+		"knownSchema": {
+			methods:        `[{"name": "a", "params": [{"name": "p", "schema": {"items": ` + point + `}}]}]`,
+			components:     `{"schemas": {"Point": ` + point + `}}`,
+			wantMethods:    `[{"name": "a", "params": [{"name": "p", "schema": {"items": {"$ref": "#/components/schemas/Point"}}}]}]`,
+			wantComponents: `{"schemas": {"Point": ` + point + `}}`,
+		},
+		"schemaCollision": {
+			methods:        `[{"name": "a", "params": [{"name": "from", "schema": ` + address + `}, {"name": "to", "schema": ` + address + `}]}]`,
+			components:     `{"schemas": {"Address": {"type": "null"}}}`,
+			wantMethods:    `[{"name": "a", "params": [{"name": "from", "schema": {"$ref": "#/components/schemas/Address_2"}}, {"name": "to", "schema": {"$ref": "#/components/schemas/Address_2"}}]}]`,
+			wantComponents: `{"schemas": {"Address": {"type": "null"}, "Address_2": ` + address + `}}`,
+		},
+		"plainSchema": {
+			methods:        `[{"name": "a", "params": [{"name": "p", "schema": {"properties": {"a": {"type": "string"}, "b": {"type": "string"}}}}]}]`,
+			wantMethods:    `[{"name": "a", "params": [{"name": "p", "schema": {"properties": {"a": {"type": "string"}, "b": {"type": "string"}}}}]}]`,
+			wantComponents: `{}`,
+		},
+		"localReference": {
+			methods:        `[{"name": "a", "params": [{"name": "p", "schema": {"definitions": {"d": {}}, "properties": {"a": {"items": {"$ref": "#/definitions/d"}}, "b": {"items": {"$ref": "#/definitions/d"}}}}}]}]`,
+			wantMethods:    `[{"name": "a", "params": [{"name": "p", "schema": {"definitions": {"d": {}}, "properties": {"a": {"items": {"$ref": "#/definitions/d"}}, "b": {"items": {"$ref": "#/definitions/d"}}}}}]}]`,
+			wantComponents: `{}`,
+		},
+		"nestedID": {
+			methods:        `[{"name": "a", "params": [{"name": "p", "schema": {"properties": {"a": {"$id": "a.json", "enum": [1]}, "b": {"$id": "a.json", "enum": [1]}}}}]}]`,
+			wantMethods:    `[{"name": "a", "params": [{"name": "p", "schema": {"properties": {"a": {"$id": "a.json", "enum": [1]}, "b": {"$id": "a.json", "enum": [1]}}}}]}]`,
+			wantComponents: `{}`,
+		},
+		"outermostFirst": {
+			methods: `[
+				{"name": "a", "params": [{"name": "p", "schema": {"title": "Outer", "properties": {"inner": ` + point + `}}}]},
+				{"name": "b", "params": [{"name": "q", "schema": {"title": "Outer", "properties": {"inner": ` + point + `}}}, {"name": "r", "schema": {"items": ` + point + `}}]}
+			]`,
+			wantMethods: `[
+				{"name": "a", "params": [{"name": "p", "schema": {"$ref": "#/components/schemas/Outer"}}]},
+				{"name": "b", "params": [{"name": "q", "schema": {"$ref": "#/components/schemas/Outer"}}, {"name": "r", "schema": {"items": {"$ref": "#/components/schemas/inner"}}}]}
+			]`,
+			wantComponents: `{"schemas": {"Outer": {"title": "Outer", "properties": {"inner": {"$ref": "#/components/schemas/inner"}}}, "inner": ` + point + `}}`,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			s := normalizeTestDocument(t, tt.methods, tt.components)
+			before := mustMarshalJSON(t, s)
+
+			ns, err := s.Normalize()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if after := mustMarshalJSON(t, s); after != before {
+				t.Fatalf("Normalize changed the document:\n%s\nwant\n%s", after, before)
+			}
+			want := normalizeTestDocument(t, tt.wantMethods, tt.wantComponents)
+			if got, w := mustMarshalJSON(t, ns), mustMarshalJSON(t, want); got != w {
+				t.Fatalf("Normalize =\n%s\nwant\n%s", got, w)
+			}
+
+			// the normalized document is normalized
+			again, err := ns.Normalize()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, w := mustMarshalJSON(t, again), mustMarshalJSON(t, ns); got != w {
+				t.Fatalf("Normalize is not idempotent:\n%s\nwant\n%s", got, w)
+			}
+		})
+	}
+}
+
+func TestNormalizeReproducible(t *testing.T) {
+	const doc = `[
+		{"name": "a", "params": [{"name": "p", "schema": {"properties": {"x": {"enum": [1]}, "y": {"enum": [2]}, "z": {"enum": [3]}}}}]},
+		{"name": "b", "params": [{"name": "q", "schema": {"properties": {"x": {"enum": [2]}, "y": {"enum": [3]}, "z": {"enum": [1]}}}}]},
+		{"name": "c", "params": [{"name": "r", "schema": {"properties": {"x": {"enum": [3]}, "y": {"enum": [1]}, "z": {"enum": [2]}}}}]}
+	]`
+	var first string
+	for i := 0; i < 20; i++ {
+		ns, err := normalizeTestDocument(t, doc, `{"schemas": {"a": {}, "b": {}, "c": {}}}`).Normalize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := MarshalCanonical(ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = string(data)
+			continue
+		}
+		if string(data) != first {
+			t.Fatalf("the normalized document #%d differs:\n%s\nwant\n%s", i, data, first)
+		}
+	}
+}
+
+func TestNormalizeError(t *testing.T) {
+	s := normalizeTestDocument(t, `[{"name": "a", "params": [{"name": "p", "schema": {"title": "T", "x-big": 1e400}}]}]`, "")
+	if _, err := s.Normalize(); err == nil {
+		t.Fatal("Normalize of the unrepresentable number succeeded")
+	}
+}
+
+func TestUniqueComponentName(t *testing.T) {
+	taken := map[string]bool{"a": true, "a_2": true, "schema": true}
+	tests := map[string]string{
+		"b":     "b",
+		"a":     "a_3",
+		"x y":   "x_y",
+		"":      "schema_2",
+		"日本":    "schema_2",
+		"a/b c": "a_b_c",
+	}
+	for hint, want := range tests {
+		if got := uniqueComponentName(hint, "schema", func(name string) bool { return taken[name] }); got != want {
+			t.Errorf("uniqueComponentName(%q) = %q, want %q", hint, got, want)
+		}
+	}
+}
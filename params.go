@@ -0,0 +1,252 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// The rules of the params validation reported in InvalidParamsData, in addition to the JSON Schema keywords of the
+// failing param values.
+const (
+	// RuleParamStructure is the rule that the params MUST be the structure which Method.ParamStructure expects.
+	RuleParamStructure = "param-structure"
+
+	// RuleMissingParam is the rule that the params MUST have all the required params of the method.
+	RuleMissingParam = "missing-param"
+
+	// RuleExtraParam is the rule that the params MUST NOT have the params which the method does not have.
+	RuleExtraParam = "extra-param"
+)
+
+// InvalidParamsData is the Data of the InvalidParams Error returned by ParamsValidator.
+type InvalidParamsData struct {
+	// Violations lists every violation of the params. The Pointer of the violation is the JSON Pointer to the offending
+	// value in the params.
+	Violations []*ParamViolation `json:"violations"`
+}
+
+// ParamViolation is a violation of the params of the JSON-RPC request.
+type ParamViolation struct {
+	// Param is the name of the offending param, or empty if the violation is not of the single param.
+	Param string `json:"param,omitempty"`
+
+	// Pointer is the JSON Pointer to the offending value in the params.
+	Pointer string `json:"pointer"`
+
+	// Rule is the failing rule, such as RuleMissingParam or the JSON Schema keyword "type".
+	Rule string `json:"rule"`
+
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// ParamsValidator validates the params of the JSON-RPC request against the params of the Method.
+//
+// The ParamsValidator is safe for concurrent use.
+type ParamsValidator struct {
+	method    *Method
+	structure ParamStructure
+	params    []*paramValidator
+}
+
+// paramValidator is the compiled param of the method.
+type paramValidator struct {
+	cd     *ContentDescriptor
+	schema *SchemaValidator
+}
+
+// NewParamsValidator compiles the params of the method m into the ParamsValidator.
+//
+// The references to the Components given by WithComponents are resolved, for both the content descriptors and the schemas.
+func NewParamsValidator(m *Method, opts ...SchemaOption) (*ParamsValidator, error) {
+	o := new(schemaOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	c := &checker{schema: &Schema{Components: o.components}}
+
+	v := &ParamsValidator{
+		method:    m,
+		structure: m.ParamStructure.OrDefault(),
+		params:    make([]*paramValidator, len(m.Params)),
+	}
+	for i, p := range m.Params {
+		if p.IsOneOf() {
+			return nil, fmt.Errorf("openrpc: method %q: param %d: oneOf param is not supported", m.Name, i)
+		}
+		cd := c.contentDescriptor(p)
+		if cd == nil {
+			return nil, fmt.Errorf("openrpc: method %q: param %d: cannot resolve the content descriptor", m.Name, i)
+		}
+
+		pv := &paramValidator{cd: cd}
+		if cd.Schema != nil {
+			schema, err := CompileSchema(cd.Schema, opts...)
+			if err != nil {
+				return nil, fmt.Errorf("openrpc: method %q: param %q: %w", m.Name, cd.Name, err)
+			}
+			pv.schema = schema
+		}
+		v.params[i] = pv
+	}
+
+	return v, nil
+}
+
+// ValidateParams validates the params of the JSON-RPC request against the params of the method m.
+//
+// ValidateParams compiles the schemas on every call. Use NewParamsValidator to validate the requests of the same method repeatedly.
+func (m *Method) ValidateParams(params json.RawMessage, opts ...SchemaOption) *Error {
+	v, err := NewParamsValidator(m, opts...)
+	if err != nil {
+		return &Error{Code: InternalError, Message: err.Error()}
+	}
+	return v.Validate(params)
+}
+
+// Validate validates the params of the JSON-RPC request, and returns the InvalidParams Error whose Data is
+// InvalidParamsData listing every violation, or nil if the params are valid.
+//
+// The params must be an array for the by-position method and an object for the by-name method. The method whose
+// ParamStructure is "either" or unset accepts both.
+// The absent or null params are the empty params. The null value of the optional param is the absent param.
+func (v *ParamsValidator) Validate(params json.RawMessage) *Error {
+	var violations []*ParamViolation
+	report := func(param, pointer, rule, format string, args ...interface{}) {
+		violations = append(violations, &ParamViolation{
+			Param:   param,
+			Pointer: pointer,
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	var value interface{}
+	if len(bytes.TrimSpace(params)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(params))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return invalidParams([]*ParamViolation{{Rule: RuleParamStructure, Message: fmt.Sprintf("invalid JSON: %v", err)}})
+		}
+	}
+
+	present := make([]bool, len(v.params))
+	switch value := value.(type) {
+	case nil:
+		// no params
+
+	case []interface{}:
+		if v.structure == ByName {
+			report("", "", RuleParamStructure, "params of the by-name method %q must be an object", v.method.Name)
+			break
+		}
+		for i, elem := range value {
+			pointer := "/" + strconv.Itoa(i)
+			if i >= len(v.params) {
+				report("", pointer, RuleExtraParam, "method %q takes %d params, got %d", v.method.Name, len(v.params), len(value))
+				break
+			}
+			p := v.params[i]
+			if elem == nil && !p.cd.Required {
+				continue
+			}
+			present[i] = true
+			violations = append(violations, p.validate(elem, pointer)...)
+		}
+
+	case map[string]interface{}:
+		if v.structure == ByPosition {
+			report("", "", RuleParamStructure, "params of the by-position method %q must be an array", v.method.Name)
+			break
+		}
+		for _, name := range sortedNames(value) {
+			pointer := "/" + escapePointerToken(name)
+			i := v.index(name)
+			if i < 0 {
+				report(name, pointer, RuleExtraParam, "method %q does not have the param %q", v.method.Name, name)
+				continue
+			}
+			p := v.params[i]
+			if value[name] == nil && !p.cd.Required {
+				continue
+			}
+			present[i] = true
+			violations = append(violations, p.validate(value[name], pointer)...)
+		}
+
+	default:
+		report("", "", RuleParamStructure, "params must be an array or an object")
+	}
+
+	if len(violations) == 0 || violations[0].Rule != RuleParamStructure {
+		for i, p := range v.params {
+			if p.cd.Required && !present[i] {
+				report(p.cd.Name, "", RuleMissingParam, "missing required param %q", p.cd.Name)
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return invalidParams(violations)
+}
+
+// index returns the index of the param whose name is name, or -1 if the method does not have it.
+func (v *ParamsValidator) index(name string) int {
+	for i, p := range v.params {
+		if p.cd.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// validate validates the value of the param at the JSON Pointer in the params.
+func (p *paramValidator) validate(value interface{}, pointer string) []*ParamViolation {
+	if p.schema == nil {
+		return nil
+	}
+
+	verrs, ok := p.schema.Validate(value).(ValidationErrors)
+	if !ok {
+		return nil
+	}
+	violations := make([]*ParamViolation, len(verrs))
+	for i, err := range verrs {
+		violations[i] = &ParamViolation{
+			Param:   p.cd.Name,
+			Pointer: pointer + err.Pointer,
+			Rule:    err.Rule,
+			Message: fmt.Sprintf("param %q: %s", p.cd.Name, err.Message),
+		}
+	}
+	return violations
+}
+
+// invalidParams returns the InvalidParams Error whose Data lists the violations.
+func invalidParams(violations []*ParamViolation) *Error {
+	data, _ := json.Marshal(&InvalidParamsData{Violations: violations}) // never fails
+	return &Error{
+		Code:    InvalidParams,
+		Message: "Invalid params",
+		Data:    data,
+	}
+}
+
+// sortedNames returns the sorted member names of the object m.
+func sortedNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,150 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// paramsTestMethod returns the method which takes the required integer "a" and the optional string "b".
+func paramsTestMethod(t *testing.T, structure string) *Method {
+	t.Helper()
+
+	doc := `{
+		"name": "m",
+		"params": [
+			{"name": "a", "required": true, "schema": {"type": "integer", "minimum": 0}},
+			{"name": "b", "schema": {"type": "string"}}
+		],
+		"result": {"name": "r", "schema": {}}`
+	if structure != "" {
+		doc += `, "paramStructure": "` + structure + `"`
+	}
+	doc += `}`
+
+	m := new(Method)
+	if err := json.Unmarshal([]byte(doc), m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestParamsValidator(t *testing.T) {
+	type violation struct {
+		param, pointer, rule string
+	}
+	tests := map[string]struct {
+		structure string
+		params    string
+		want      []violation
+	}{
+		"unsetArray":         {params: `[1, "x"]`},
+		"unsetObject":        {params: `{"a": 1, "b": "x"}`},
+		"eitherArray":        {structure: "either", params: `[1]`},
+		"eitherObject":       {structure: "either", params: `{"a": 1}`},
+		"byPositionArray":    {structure: "by-position", params: `[1, null]`},
+		"byNameObject":       {structure: "by-name", params: `{"a": 1, "b": null}`},
+		"byPositionObject":   {structure: "by-position", params: `{"a": 1}`, want: []violation{{"", "", RuleParamStructure}}},
+		"byNameArray":        {structure: "by-name", params: `[1]`, want: []violation{{"", "", RuleParamStructure}}},
+		"scalar":             {params: `1`, want: []violation{{"", "", RuleParamStructure}}},
+		"invalidJSON":        {params: `[1,`, want: []violation{{"", "", RuleParamStructure}}},
+		"absent":             {params: ``, want: []violation{{"a", "", RuleMissingParam}}},
+		"null":               {params: `null`, want: []violation{{"a", "", RuleMissingParam}}},
+		"nullRequired":       {params: `[null]`, want: []violation{{"a", "/0", "type"}}},
+		"missingByName":      {params: `{"b": "x"}`, want: []violation{{"a", "", RuleMissingParam}}},
+		"extraByPosition":    {params: `[1, "x", 3]`, want: []violation{{"", "/2", RuleExtraParam}}},
+		"extraByName":        {params: `{"a": 1, "a/b": 2}`, want: []violation{{"a/b", "/a~1b", RuleExtraParam}}},
+		"invalidValue":       {params: `[-1, 2]`, want: []violation{{"a", "/0", "minimum"}, {"b", "/1", "type"}}},
+		"invalidNamedValues": {params: `{"b": 2, "a": "x"}`, want: []violation{{"a", "/a", "type"}, {"b", "/b", "type"}}},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			v, err := NewParamsValidator(paramsTestMethod(t, tt.structure))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rpcErr := v.Validate(json.RawMessage(tt.params))
+			if len(tt.want) == 0 {
+				if rpcErr != nil {
+					t.Fatalf("Validate(%s) = %s", tt.params, rpcErr.Data)
+				}
+				return
+			}
+			if rpcErr == nil {
+				t.Fatalf("Validate(%s) = nil, want %v", tt.params, tt.want)
+			}
+			if rpcErr.Code != InvalidParams {
+				t.Fatalf("Code = %d, want %d", rpcErr.Code, InvalidParams)
+			}
+
+			var data InvalidParamsData
+			if err := json.Unmarshal(rpcErr.Data, &data); err != nil {
+				t.Fatal(err)
+			}
+			got := make([]violation, len(data.Violations))
+			for i, vio := range data.Violations {
+				got[i] = violation{vio.Param, vio.Pointer, vio.Rule}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Validate(%s) = %v, want %v", tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewParamsValidatorError(t *testing.T) {
+	tests := map[string]string{
+		"oneOf":      `{"name": "m", "params": [{"oneOf": [{"name": "a", "schema": {}}]}], "result": {"name": "r", "schema": {}}}`,
+		"unresolved": `{"name": "m", "params": [{"$ref": "#/components/contentDescriptors/a"}], "result": {"name": "r", "schema": {}}}`,
+	}
+	for name, doc := range tests {
+		doc := doc
+		t.Run(name, func(t *testing.T) {
+			m := new(Method)
+			if err := json.Unmarshal([]byte(doc), m); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewParamsValidator(m); err == nil {
+				t.Fatal("NewParamsValidator succeeded")
+			}
+			if rpcErr := m.ValidateParams(json.RawMessage(`[]`)); rpcErr == nil || rpcErr.Code != InternalError {
+				t.Fatalf("ValidateParams = %v, want the InternalError", rpcErr)
+			}
+		})
+	}
+}
+
+func TestParamsValidatorComponents(t *testing.T) {
+	m := new(Method)
+	if err := json.Unmarshal([]byte(`{
+		"name": "m",
+		"params": [{"$ref": "#/components/contentDescriptors/a"}],
+		"result": {"name": "r", "schema": {}}
+	}`), m); err != nil {
+		t.Fatal(err)
+	}
+	c := new(Components)
+	if err := json.Unmarshal([]byte(`{
+		"contentDescriptors": {"a": {"name": "a", "required": true, "schema": {"$ref": "#/components/schemas/n"}}},
+		"schemas": {"n": {"type": "number"}}
+	}`), c); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewParamsValidator(m, WithComponents(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rpcErr := v.Validate(json.RawMessage(`{"a": 1.5}`)); rpcErr != nil {
+		t.Fatalf("Validate = %s", rpcErr.Data)
+	}
+	if rpcErr := v.Validate(json.RawMessage(`["x"]`)); rpcErr == nil {
+		t.Fatal("Validate of the string param succeeded")
+	}
+}
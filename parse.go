@@ -0,0 +1,194 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format represents the encoding format of the OpenRPC document.
+type Format int
+
+const (
+	// FormatAuto detects the format by the content of the document.
+	FormatAuto Format = iota
+	// FormatJSON is the JSON format.
+	FormatJSON
+	// FormatYAML is the YAML format.
+	FormatYAML
+)
+
+// String implements fmt.Stringer.
+func (f Format) String() string {
+	switch f {
+	case FormatAuto:
+		return "auto"
+	case FormatJSON:
+		return "json"
+	case FormatYAML:
+		return "yaml"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// formatByExt returns the format of the file name by the file extension, or FormatAuto if unknown.
+func formatByExt(name string) Format {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatAuto
+	}
+}
+
+// detectFormat returns the format of data by the content.
+//
+// The document which begins with '{' or '[' is JSON, otherwise YAML which is a superset of JSON.
+func detectFormat(data []byte) Format {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if len(data) > 0 && (data[0] == '{' || data[0] == '[') {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// parseOptions holds the options of Parse.
+type parseOptions struct {
+	strict bool
+	format Format
+}
+
+// ParseOption configures the Parse, ParseReader and ParseFile.
+type ParseOption func(*parseOptions)
+
+// WithStrict reports the unknown fields which are not the "x-" patterned fields as an error if strict is true.
+//
+// The JSON object keys are also matched case-sensitively in strict mode.
+// The keywords of the JSON Schema are not checked because the JSON Schema allows the unknown keywords.
+func WithStrict(strict bool) ParseOption {
+	return func(o *parseOptions) {
+		o.strict = strict
+	}
+}
+
+// WithFormat sets the encoding format of the document. The default is FormatAuto.
+func WithFormat(format Format) ParseOption {
+	return func(o *parseOptions) {
+		o.format = format
+	}
+}
+
+// Parse parses the OpenRPC document data encoded in JSON or YAML.
+//
+// The decode error is reported as *DecodeError which holds the JSON Pointer and the position of the offending node.
+func Parse(data []byte, opts ...ParseOption) (*Schema, error) {
+	o := newParseOptions(opts)
+
+	src, err := parseSource(data, o.format)
+	if err != nil {
+		return nil, err
+	}
+
+	return src.decode(o)
+}
+
+// newParseOptions returns the parseOptions configured by opts.
+func newParseOptions(opts []ParseOption) *parseOptions {
+	o := new(parseOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// source is the parsed text of the OpenRPC document.
+type source struct {
+	format Format
+
+	// root is the node tree of the document.
+	root *node
+
+	// json is the JSON text of the document, or nil if the format is not FormatJSON.
+	json []byte
+
+	// yaml is the YAML document node, or nil if the format is not FormatYAML.
+	yaml *yaml.Node
+}
+
+// parseSource parses the data encoded in the format.
+func parseSource(data []byte, format Format) (*source, error) {
+	if format == FormatAuto {
+		format = detectFormat(data)
+	}
+
+	src := &source{format: format}
+	var err error
+	switch format {
+	case FormatJSON:
+		src.root, err = parseNode(data)
+		src.json = data
+	case FormatYAML:
+		src.root, src.yaml, err = parseYAML(data)
+	default:
+		return nil, fmt.Errorf("openrpc: unknown format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return src, nil
+}
+
+// decode decodes the src into the new Schema.
+func (src *source) decode(o *parseOptions) (*Schema, error) {
+	schema := new(Schema)
+	d := &decoder{src: src.json, strict: o.strict}
+	if err := d.decode(src.root, reflect.ValueOf(schema)); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// ParseReader parses the OpenRPC document read from r.
+func ParseReader(r io.Reader, opts ...ParseOption) (*Schema, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("openrpc: read document: %w", err)
+	}
+
+	return Parse(data, opts...)
+}
+
+// ParseFile parses the OpenRPC document file name in fsys.
+//
+// The format is detected by the file extension ".json", ".yaml" or ".yml", and by the content for the other files unless WithFormat is given.
+func ParseFile(fsys fs.FS, name string, opts ...ParseOption) (*Schema, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("openrpc: read document: %w", err)
+	}
+
+	if format := formatByExt(name); format != FormatAuto {
+		opts = append([]ParseOption{WithFormat(format)}, opts...)
+	}
+	schema, err := Parse(data, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,226 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const parseTestDocument = `{
+  "openrpc": "1.2.6",
+  "info": {"title": "t", "version": "1"},
+  "methods": [
+    {
+      "name": "a",
+      "params": [],
+      "result": {"name": "r", "schema": {"type": "string"}},
+      "x-a": 1
+    }
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(parseTestDocument), WithStrict(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.OpenRPC != "1.2.6" || s.Info.Title != "t" || len(s.Methods) != 1 || s.Methods[0].Name != "a" {
+		t.Fatalf("Parse = %+v", s)
+	}
+	if ext := LookupExtension(s.Methods[0].Extensions, "x-a"); ext == nil || string(ext.Value) != "1" {
+		t.Fatalf("the extension is not decoded: %v", s.Methods[0].Extensions)
+	}
+
+	s, err = ParseReader(strings.NewReader(parseTestDocument))
+	if err != nil || s.Methods[0].Result.ContentDescriptor.Name != "r" {
+		t.Fatalf("ParseReader = %+v, %v", s, err)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	data := `{
+  "openrpc": "1.2.6",
+  "info": {"title": "t", "version": "1"},
+  "methods": [],
+  "externaldocs": {"url": "https://example.com"}
+}`
+	// the non-strict mode matches the key case-insensitively
+	s, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ExternalDocs == nil || s.ExternalDocs.URL != "https://example.com" {
+		t.Fatalf("ExternalDocs = %+v", s.ExternalDocs)
+	}
+
+	// the unknown field is located at its value
+	_, err = Parse([]byte(data), WithStrict(true))
+	var derr *DecodeError
+	if !errors.As(err, &derr) {
+		t.Fatalf("Parse = %v, want *DecodeError", err)
+	}
+	if derr.Pointer != "/externaldocs" || derr.Line != 5 || derr.Column != 19 {
+		t.Fatalf("DecodeError at %q line %d column %d, want /externaldocs line 5 column 19", derr.Pointer, derr.Line, derr.Column)
+	}
+	if !strings.Contains(err.Error(), "unknown field") {
+		t.Fatalf("Error() = %q", err)
+	}
+}
+
+func TestParseDecodeError(t *testing.T) {
+	tests := map[string]struct {
+		data         string
+		opts         []ParseOption
+		pointer      string
+		line, column int
+		msg          string
+	}{
+		"type": {
+			data:    "{\n  \"openrpc\": 1\n}",
+			pointer: "/openrpc", line: 2, column: 14,
+		},
+		"nested": {
+			data:    "{\n  \"methods\": [\n    {\"name\": \"a\"},\n    {\"name\": true}\n  ]\n}",
+			pointer: "/methods/1/name", line: 4, column: 14,
+		},
+		"strictNested": {
+			data:    "{\n  \"methods\": [\n    {\"name\": \"a\", \"param\": []}\n  ]\n}",
+			opts:    []ParseOption{WithStrict(true)},
+			pointer: "/methods/0/param", line: 3, column: 28,
+			msg: "unknown field",
+		},
+		"schema": {
+			data:    "{\n  \"components\": {\"schemas\": {\"a\": {\"type\": 1}}}\n}",
+			pointer: "/components/schemas/a", line: 2, column: 35,
+		},
+		"syntax": {
+			data: "{\n  \"openrpc\": \"1.2.6\",\n  \"info\": }\n}",
+			line: 3, column: 11,
+		},
+		"trailing": {
+			data: "{}\n{}",
+			line: 2, column: 1,
+		},
+		"paramStructure": {
+			data:    "{\"methods\": [{\"name\": \"a\", \"paramStructure\": \"by-index\"}]}",
+			pointer: "/methods/0/paramStructure", line: 1, column: 46,
+			msg: "by-index",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), append(tt.opts, WithFormat(FormatJSON))...)
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Fatalf("Parse = %v, want *DecodeError", err)
+			}
+			if derr.Pointer != tt.pointer || derr.Line != tt.line || derr.Column != tt.column {
+				t.Fatalf("DecodeError at %q line %d column %d, want %q line %d column %d (%v)", derr.Pointer, derr.Line, derr.Column, tt.pointer, tt.line, tt.column, err)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("Error() = %q does not contain %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorString(t *testing.T) {
+	inner := errors.New("openrpc: bad value")
+	tests := map[string]struct {
+		err  *DecodeError
+		want string
+	}{
+		"full":       {err: &DecodeError{Pointer: "/a/0", Line: 3, Column: 7, Err: inner}, want: "openrpc: /a/0: line 3, column 7: bad value"},
+		"noPosition": {err: &DecodeError{Pointer: "/a", Err: inner}, want: "openrpc: /a: bad value"},
+		"noPointer":  {err: &DecodeError{Line: 1, Column: 1, Err: errors.New("x")}, want: "openrpc: line 1, column 1: x"},
+	}
+	for name, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("%s: Error() = %q, want %q", name, got, tt.want)
+		}
+	}
+	if !errors.Is(&DecodeError{Err: inner}, inner) {
+		t.Error("DecodeError does not unwrap the underlying error")
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	yamlDoc := "openrpc: 1.2.6\ninfo:\n  title: t\n  version: \"1\"\nmethods: []\n"
+	fsys := fstest.MapFS{
+		"openrpc.json":  {Data: []byte(parseTestDocument)},
+		"openrpc.yaml":  {Data: []byte(yamlDoc)},
+		"openrpc.yml":   {Data: []byte(yamlDoc)},
+		"openrpc":       {Data: []byte(parseTestDocument)},
+		"yaml.txt":      {Data: []byte(yamlDoc)},
+		"invalid.json":  {Data: []byte(yamlDoc)},
+		"dir/nested.js": {Data: []byte(parseTestDocument)},
+	}
+	tests := map[string]struct {
+		opts    []ParseOption
+		wantErr bool
+	}{
+		"openrpc.json":  {},
+		"openrpc.yaml":  {},
+		"openrpc.yml":   {},
+		"openrpc":       {},
+		"yaml.txt":      {},
+		"invalid.json":  {wantErr: true},
+		"dir/nested.js": {opts: []ParseOption{WithFormat(FormatJSON)}},
+	}
+	for name, tt := range tests {
+		s, err := ParseFile(fsys, name, tt.opts...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFile(%q) = %v, wantErr %t", name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (s.OpenRPC != "1.2.6" || s.Info.Title != "t") {
+			t.Errorf("ParseFile(%q) = %+v", name, s)
+		}
+	}
+
+	if _, err := ParseFile(fsys, "none.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ParseFile of the missing file = %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	exts := map[string]Format{
+		"a.json": FormatJSON,
+		"a.JSON": FormatJSON,
+		"a.yaml": FormatYAML,
+		"a.yml":  FormatYAML,
+		"a.txt":  FormatAuto,
+		"a":      FormatAuto,
+	}
+	for name, want := range exts {
+		if got := formatByExt(name); got != want {
+			t.Errorf("formatByExt(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	contents := map[string]Format{
+		`{}`:        FormatJSON,
+		" \n\t[]":   FormatJSON,
+		"a: 1":      FormatYAML,
+		"---\na: 1": FormatYAML,
+		"":          FormatYAML,
+	}
+	for data, want := range contents {
+		if got := detectFormat([]byte(data)); got != want {
+			t.Errorf("detectFormat(%q) = %v, want %v", data, got, want)
+		}
+	}
+
+	for f, want := range map[Format]string{FormatAuto: "auto", FormatJSON: "json", FormatYAML: "yaml"} {
+		if got := f.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", f, got, want)
+		}
+	}
+}
@@ -0,0 +1,951 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zchee/go-openrpc/internal/jsonschema"
+)
+
+// RuleUnresolvedReference is the rule that the references MUST resolve to the objects of the document, reported as ValidationError.Rule.
+const RuleUnresolvedReference = "unresolved-reference"
+
+// ReferenceError is the error of resolving the reference.
+type ReferenceError struct {
+	// Ref is the failing reference.
+	Ref string
+
+	// Pointer is the JSON Pointer to the value using the Ref in the JSON encoding of the document.
+	//
+	// The Pointer is set by Bundle and Dereference, which walk the document. It is empty in the error returned by
+	// the Resolve methods of the Resolver, which are given the Ref only and do not know where it is used.
+	Pointer string
+
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error implements error.
+func (e *ReferenceError) Error() string {
+	if e.Pointer == "" {
+		return fmt.Sprintf("openrpc: resolve %q: %v", e.Ref, e.Err)
+	}
+	return fmt.Sprintf("openrpc: resolve %q used at %s: %v", e.Ref, e.Pointer, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// errExternalReference is the error of the reference to the other document without the Loader.
+var errExternalReference = errors.New("external reference is not supported without the loader")
+
+// ResolverOption configures the Resolver.
+type ResolverOption func(*resolverState)
+
+// WithLoader sets the Loader which loads the documents referred by the external references.
+// Without the Loader, only the local references are resolved.
+func WithLoader(l Loader) ResolverOption {
+	return func(st *resolverState) {
+		st.loader = l
+	}
+}
+
+// WithBaseURI sets the base URI of the document, such as "openrpc.json" or "file:///specs/openrpc.json",
+// against which the relative references of the document are resolved.
+//
+// The external references back to the document, such as "../openrpc.json#/components/schemas/Block", are resolved to
+// the document itself only if the base URI is set.
+func WithBaseURI(uri string) ResolverOption {
+	return func(st *resolverState) {
+		st.base = uri
+	}
+}
+
+// Resolver resolves the references of the document.
+//
+// The local references "#/components/<kind>/<name>" are resolved to the Components of the document. The external
+// references are resolved against the base URI of the referring document, and loaded by the Loader given by WithLoader.
+// The loaded documents are cached by the Resolver, so the same reference always resolves to the same object.
+//
+// The "$id"s of the JSON Schemas in the Components.Schemas and in the loaded documents identify the schemas, and change
+// the base URI of the references in them.
+//
+// The Resolver does not modify the document, so the Resolver of the document which is not modified concurrently is safe for concurrent use.
+type Resolver struct {
+	state *resolverState
+
+	// scope is the scope of the references resolved by the Resolver.
+	scope *origin
+}
+
+// resolverState is the state of the Resolver shared by its scopes.
+type resolverState struct {
+	schema *Schema
+	base   string
+	loader Loader
+
+	// root is the scope of the root document.
+	root *origin
+
+	mu sync.Mutex
+
+	// docs caches the loaded documents by their URIs.
+	docs map[string]*loadedDocument
+
+	// ids maps the "$id" URIs to the identified schemas.
+	ids     map[string]*idTarget
+	indexed bool
+
+	// origins maps the objects decoded from the loaded documents to their scopes.
+	origins map[interface{}]*origin
+}
+
+// origin is the scope of the references, which is the document and the base URI.
+type origin struct {
+	// doc is the loaded document, or nil for the root document.
+	doc *loadedDocument
+
+	base string
+}
+
+// idTarget is the schema identified by the "$id".
+type idTarget struct {
+	// doc is the loaded document, or nil for the root document.
+	doc *loadedDocument
+
+	// pointer is the JSON Pointer to the schema in the doc.
+	pointer string
+}
+
+// loadedDocument is the document loaded by the Loader.
+type loadedDocument struct {
+	uri string
+	src *source
+
+	// values caches the decoded values by the JSON Pointer and the type.
+	values map[valueKey]interface{}
+}
+
+type valueKey struct {
+	pointer string
+	typ     reflect.Type
+}
+
+// NewResolver returns the new Resolver of the document s.
+func NewResolver(s *Schema, opts ...ResolverOption) *Resolver {
+	st := &resolverState{
+		schema:  s,
+		docs:    make(map[string]*loadedDocument),
+		ids:     make(map[string]*idTarget),
+		origins: make(map[interface{}]*origin),
+	}
+	for _, opt := range opts {
+		opt(st)
+	}
+	st.base = normalizeURI(st.base)
+	st.root = &origin{base: st.base}
+
+	return &Resolver{state: st, scope: st.root}
+}
+
+// Scope returns the Resolver which resolves the references used in v, which is the object returned by the Resolver
+// such as the *ContentDescriptor or its nested *JSONSchema, against the document v was loaded from.
+// The Resolver of the other objects resolves the references against the root document.
+func (r *Resolver) Scope(v interface{}) *Resolver {
+	st := r.state
+	st.mu.Lock()
+	o, ok := st.origins[v]
+	st.mu.Unlock()
+	if !ok {
+		o = st.root
+	}
+	return &Resolver{state: st, scope: o}
+}
+
+// BaseURI returns the base URI of the scope of r.
+func (r *Resolver) BaseURI() string {
+	return r.scope.base
+}
+
+func (r *Resolver) components() *Components {
+	if r.state.schema == nil || r.state.schema.Components == nil {
+		return new(Components)
+	}
+	return r.state.schema.Components
+}
+
+// normalizeURI returns the uri without the fragment, whose relative path is resolved to the absolute path.
+func normalizeURI(uri string) string {
+	if i := strings.IndexByte(uri, '#'); i >= 0 {
+		uri = uri[:i]
+	}
+	if uri == "" {
+		return ""
+	}
+	return jsonschema.ResolveURI(uri, "")
+}
+
+// splitFragment splits the URI reference into the normalized URI and the raw fragment.
+func splitFragment(ref string) (uri, fragment string) {
+	if i := strings.IndexByte(ref, '#'); i >= 0 {
+		ref, fragment = ref[:i], ref[i+1:]
+	}
+	return normalizeURI(ref), fragment
+}
+
+// locate returns the document of the ref used in the scope of r and the raw fragment in the document.
+// The nil document is the root document.
+//
+// The st.mu is not held while the Loader loads the document, so that the slow loads do not block the other
+// resolutions. The concurrent loads of the same document keep the one cached first.
+func (r *Resolver) locate(ref string) (*loadedDocument, string, error) {
+	st := r.state
+	uri, fragment := splitFragment(jsonschema.ResolveURI(r.scope.base, ref))
+
+	st.mu.Lock()
+	doc, pointer, ok := st.lookup(uri, fragment)
+	st.mu.Unlock()
+	if ok {
+		return doc, pointer, nil
+	}
+	if st.loader == nil {
+		return nil, "", errExternalReference
+	}
+
+	src, err := st.load(uri)
+	if err != nil {
+		return nil, "", err
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	doc, ok = st.docs[uri]
+	if !ok {
+		doc = st.add(uri, src)
+	}
+	if t, ok := st.ids[uri]; ok && t.doc != doc {
+		return t.doc, t.pointer + fragment, nil
+	}
+	return doc, fragment, nil
+}
+
+// lookup returns the document of the uri, which is the root document or the one already loaded, and the raw
+// fragment in the document. It returns false if the document has not been loaded. The st.mu must be held.
+func (st *resolverState) lookup(uri, fragment string) (*loadedDocument, string, bool) {
+	st.index()
+	if t, ok := st.ids[uri+"#"+fragment]; ok && fragment != "" && !strings.HasPrefix(fragment, "/") {
+		return t.doc, t.pointer, true
+	}
+	if t, ok := st.ids[uri]; ok {
+		return t.doc, t.pointer + fragment, true
+	}
+	if uri == st.base {
+		return nil, fragment, true
+	}
+	if doc, ok := st.docs[uri]; ok {
+		return doc, fragment, true
+	}
+	return nil, "", false
+}
+
+// index indexes the "$id"s of the Components.Schemas of the root document once. The st.mu must be held.
+func (st *resolverState) index() {
+	if st.indexed {
+		return
+	}
+	st.indexed = true
+
+	if st.schema == nil || st.schema.Components == nil {
+		return
+	}
+	schemas := st.schema.Components.Schemas
+	for _, name := range sortedMapKeys(schemas) {
+		js := schemas[name]
+		if js == nil {
+			continue
+		}
+		prefix := formatPointer([]string{"components", "schemas", name})
+		jsonschema.Walk(js.Schema, st.base, func(s *jsonschema.Schema, tokens []string, base string) bool {
+			if s.ID != "" && s.Ref == nil {
+				st.addID(base, s.ID, &idTarget{pointer: prefix + formatPointer(tokens)})
+			}
+			return true
+		})
+	}
+}
+
+// addID adds the schema identified by the "$id" id whose base URI is base, which is changed by the id unless it is the plain name fragment.
+func (st *resolverState) addID(base, id string, t *idTarget) {
+	key := base
+	if strings.HasPrefix(id, "#") {
+		key += id
+	}
+	if _, ok := st.ids[key]; !ok {
+		st.ids[key] = t
+	}
+}
+
+// load loads and parses the document at the uri. The st.mu must not be held.
+func (st *resolverState) load(uri string) (*source, error) {
+	data, err := st.loader.Load(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	format := FormatAuto
+	if u, err := url.Parse(uri); err == nil {
+		format = formatByExt(u.Path)
+	}
+	src, err := parseSource(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", uri, err)
+	}
+	return src, nil
+}
+
+// add caches the document src loaded from the uri and indexes its "$id"s. The st.mu must be held.
+func (st *resolverState) add(uri string, src *source) *loadedDocument {
+	doc := &loadedDocument{
+		uri:    uri,
+		src:    src,
+		values: make(map[valueKey]interface{}),
+	}
+	st.docs[uri] = doc
+	st.indexDocument(doc, src.root, uri, nil)
+
+	return doc
+}
+
+// valueKeywords are the JSON Schema keywords whose values are the instances, which are not walked for the "$id"s.
+var valueKeywords = map[string]bool{
+	"const":    true,
+	"default":  true,
+	"enum":     true,
+	"example":  true,
+	"examples": true,
+}
+
+// indexDocument indexes the "$id"s of the objects in the loaded document doc.
+func (st *resolverState) indexDocument(doc *loadedDocument, n *node, base string, tokens []string) {
+	switch n.kind {
+	case objectNode:
+		if id := n.member("$id"); id != nil && id.kind == stringNode && n.member("$ref") == nil {
+			if !strings.HasPrefix(id.value, "#") {
+				base = normalizeURI(jsonschema.ResolveURI(base, id.value))
+			}
+			st.addID(base, id.value, &idTarget{doc: doc, pointer: formatPointer(tokens)})
+		}
+		for i, key := range n.keys {
+			if !valueKeywords[key] {
+				st.indexDocument(doc, n.children[i], base, append(tokens[:len(tokens):len(tokens)], key))
+			}
+		}
+	case arrayNode:
+		for i, child := range n.children {
+			st.indexDocument(doc, child, base, append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i)))
+		}
+	}
+}
+
+// baseOf returns the base URI of the value at the JSON Pointer reference tokens in doc, which is changed by the "$id"s
+// of its ancestors.
+func (doc *loadedDocument) baseOf(tokens []string) string {
+	base := doc.uri
+	n := doc.src.root
+	for _, tok := range tokens {
+		if n.kind == objectNode && n.member("$ref") == nil {
+			if id := n.member("$id"); id != nil && id.kind == stringNode && !strings.HasPrefix(id.value, "#") {
+				base = normalizeURI(jsonschema.ResolveURI(base, id.value))
+			}
+		}
+		if n = n.lookup([]string{tok}); n == nil {
+			break
+		}
+	}
+	return base
+}
+
+// pointerTokens parses the raw fragment, which may be percent-encoded, into the JSON Pointer reference tokens.
+func pointerTokens(fragment string) ([]string, error) {
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fragment: %w", err)
+	}
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = unescapePointerToken(tok)
+	}
+	return tokens, nil
+}
+
+// decode returns the value of the type typ decoded from the value at the raw fragment in the loaded document doc.
+//
+// The decoded value is cached, and the objects in it are registered to the scope of doc.
+func (st *resolverState) decode(doc *loadedDocument, fragment string, typ reflect.Type) (interface{}, error) {
+	tokens, err := pointerTokens(fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	key := valueKey{pointer: formatPointer(tokens), typ: typ}
+	if v, ok := doc.values[key]; ok {
+		return v, nil
+	}
+
+	n := doc.src.root.lookup(tokens)
+	if n == nil {
+		return nil, fmt.Errorf("%s has no value at %q", doc.uri, key.pointer)
+	}
+	v := reflect.New(typ)
+	d := &decoder{src: doc.src.json, path: append([]string(nil), tokens...)}
+	if err := d.decode(n, v); err != nil {
+		return nil, fmt.Errorf("%s: %w", doc.uri, err)
+	}
+
+	doc.values[key] = v.Interface()
+	st.register(v, &origin{doc: doc, base: doc.baseOf(tokens)})
+
+	return v.Interface(), nil
+}
+
+// modelPkgPath is the import path of the package, whose objects are registered to their scopes.
+var modelPkgPath = reflect.TypeOf(Schema{}).PkgPath()
+
+// register registers the objects in v to the scope o. The "$id" of the JSONSchema changes the base URI of its scope.
+func (st *resolverState) register(v reflect.Value, o *origin) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || v.Type().Elem().Kind() != reflect.Struct || v.Type().Elem().PkgPath() != modelPkgPath {
+			return
+		}
+		key := v.Interface()
+		if _, ok := st.origins[key]; ok {
+			return
+		}
+		if js, ok := key.(*JSONSchema); ok && js.Schema != nil {
+			o = &origin{doc: o.doc, base: jsonschema.BaseURI(js.Schema, o.base)}
+		}
+		st.origins[key] = o
+		st.register(v.Elem(), o)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				st.register(v.Field(i), o)
+			}
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			st.register(v.Index(i), o)
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			st.register(iter.Value(), o)
+		}
+	}
+}
+
+// componentKinds maps the kinds of the Components to the nouns in the error messages.
+var componentKinds = map[string]string{
+	"contentDescriptors":    "content descriptor",
+	"schemas":               "schema",
+	"examples":              "example",
+	"links":                 "link",
+	"errors":                "error",
+	"examplePairingObjects": "example pairing",
+	"tags":                  "tag",
+}
+
+// parseComponentPointer parses the raw fragment "/components/<kind>/<name><rest>" into the unescaped name and the rest
+// raw JSON Pointer.
+func parseComponentPointer(fragment, kind string) (name, rest string, err error) {
+	prefix := "/components/" + kind + "/"
+	if unescaped, err := url.PathUnescape(fragment); err != nil {
+		return "", "", fmt.Errorf("invalid fragment: %w", err)
+	} else if !strings.HasPrefix(unescaped, prefix) {
+		return "", "", fmt.Errorf("must refer \"#%s<name>\"", prefix)
+	}
+
+	// the prefix has no percent-encoded characters in the valid reference
+	name = strings.TrimPrefix(fragment, prefix)
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+	if name, err = url.PathUnescape(name); err != nil {
+		return "", "", fmt.Errorf("invalid fragment: %w", err)
+	}
+	return unescapePointerToken(name), rest, nil
+}
+
+// resolveObject resolves the ref to the object of the kind, whose type is typ.
+//
+// The local reference of the root document is looked up in the Components by the lookup. The object of the loaded
+// document which is the Reference Object is followed.
+func (r *Resolver) resolveObject(ref, kind string, typ reflect.Type, lookup func(c *Components, name string) (interface{}, bool)) (interface{}, error) {
+	seen := make(map[string]bool)
+	cur := r
+	for next := ref; ; {
+		doc, fragment, err := cur.locate(next)
+		if err != nil {
+			return nil, &ReferenceError{Ref: ref, Err: chainError(ref, next, err)}
+		}
+
+		if doc == nil {
+			name, rest, err := parseComponentPointer(fragment, kind)
+			if err == nil && rest != "" {
+				err = fmt.Errorf("must refer the component itself, not %q in it", rest)
+			}
+			if err != nil {
+				return nil, &ReferenceError{Ref: ref, Err: chainError(ref, next, err)}
+			}
+			v, ok := lookup(cur.components(), name)
+			if !ok {
+				return nil, &ReferenceError{Ref: ref, Err: chainError(ref, next, fmt.Errorf("unknown %s %q", componentKinds[kind], name))}
+			}
+			return v, nil
+		}
+
+		key := doc.uri + "#" + fragment
+		if seen[key] {
+			return nil, &ReferenceError{Ref: ref, Err: fmt.Errorf("circular reference through %q", next)}
+		}
+		seen[key] = true
+
+		rv, err := r.state.decode(doc, fragment, reflect.TypeOf(Reference{}))
+		if err != nil {
+			return nil, &ReferenceError{Ref: ref, Err: chainError(ref, next, err)}
+		}
+		if rv := rv.(*Reference); rv.Ref != "" {
+			cur, next = &Resolver{state: r.state, scope: &origin{doc: doc, base: doc.baseOf(nil)}}, rv.Ref
+			continue
+		}
+
+		v, err := r.state.decode(doc, fragment, typ)
+		if err != nil {
+			return nil, &ReferenceError{Ref: ref, Err: chainError(ref, next, err)}
+		}
+		return v, nil
+	}
+}
+
+// chainError returns the err of resolving the reference next which is followed from the ref.
+func chainError(ref, next string, err error) error {
+	if next == ref {
+		return err
+	}
+	return fmt.Errorf("%q: %w", next, err)
+}
+
+// ResolveContentDescriptor resolves the ref "#/components/contentDescriptors/<name>", or the external reference to the content descriptor.
+func (r *Resolver) ResolveContentDescriptor(ref string) (*ContentDescriptor, error) {
+	v, err := r.resolveObject(ref, "contentDescriptors", reflect.TypeOf(ContentDescriptor{}), func(c *Components, name string) (interface{}, bool) {
+		cd := c.ContentDescriptors[name]
+		return cd, cd != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ContentDescriptor), nil
+}
+
+// ResolveError resolves the ref "#/components/errors/<name>", or the external reference to the error.
+func (r *Resolver) ResolveError(ref string) (*Error, error) {
+	v, err := r.resolveObject(ref, "errors", reflect.TypeOf(Error{}), func(c *Components, name string) (interface{}, bool) {
+		e := c.Errors[name]
+		return e, e != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Error), nil
+}
+
+// ResolveExample resolves the ref "#/components/examples/<name>", or the external reference to the example.
+func (r *Resolver) ResolveExample(ref string) (*Example, error) {
+	v, err := r.resolveObject(ref, "examples", reflect.TypeOf(Example{}), func(c *Components, name string) (interface{}, bool) {
+		ex := c.Examples[name]
+		return ex, ex != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Example), nil
+}
+
+// ResolveLink resolves the ref "#/components/links/<name>", or the external reference to the link.
+func (r *Resolver) ResolveLink(ref string) (*Link, error) {
+	v, err := r.resolveObject(ref, "links", reflect.TypeOf(Link{}), func(c *Components, name string) (interface{}, bool) {
+		l := c.Links[name]
+		return l, l != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Link), nil
+}
+
+// ResolveTag resolves the ref "#/components/tags/<name>", or the external reference to the tag.
+func (r *Resolver) ResolveTag(ref string) (*Tag, error) {
+	v, err := r.resolveObject(ref, "tags", reflect.TypeOf(Tag{}), func(c *Components, name string) (interface{}, bool) {
+		t := c.Tags[name]
+		return t, t != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Tag), nil
+}
+
+// ResolveExamplePairing resolves the ref "#/components/examplePairingObjects/<name>", or the external reference to the example pairing.
+func (r *Resolver) ResolveExamplePairing(ref string) (*ExamplePairing, error) {
+	v, err := r.resolveObject(ref, "examplePairingObjects", reflect.TypeOf(ExamplePairing{}), func(c *Components, name string) (interface{}, bool) {
+		p := c.ExamplePairingObjects[name]
+		return p, p != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ExamplePairing), nil
+}
+
+// ResolveSchema resolves the ref "#/components/schemas/<name>", which may be followed by the JSON Pointer to the
+// subschema such as "#/components/schemas/<name>/properties/<property>", or the external reference to the schema.
+//
+// The target which is the "$ref" schema is followed until the schema which is not the reference.
+func (r *Resolver) ResolveSchema(ref string) (*JSONSchema, error) {
+	return r.ResolveSchemaRef(nil, ref)
+}
+
+// ResolveSchemaRef resolves the ref used in the JSONSchema root, such as the "$ref" of its nested subschema.
+//
+// The references to the Components are resolved as ResolveSchema, and the other local references of the root document
+// such as "#/definitions/<name>" are resolved against the root, as CompileSchema does. The local references of the
+// root loaded from the other document are resolved against that document.
+// The target which is the "$ref" schema is followed, and the circular references are reported as the error.
+//
+// The base URI of the ref is the one of root, so the "$id"s of the subschemas between the root and the ref are not
+// taken into account.
+func (r *Resolver) ResolveSchemaRef(root *JSONSchema, ref string) (*JSONSchema, error) {
+	scope := r
+	base := r.scope.base
+	if root != nil {
+		scope = r.Scope(root)
+		base = scope.scope.base
+		if scope.scope.doc == nil && root.Schema != nil {
+			base = jsonschema.BaseURI(root.Schema, base)
+		}
+	}
+	return scope.resolveSchema(root, base, ref)
+}
+
+// resolveSchema resolves the ref used in the JSONSchema root, whose base URI is base.
+func (r *Resolver) resolveSchema(root *JSONSchema, base, ref string) (*JSONSchema, error) {
+	type key struct {
+		root *JSONSchema
+		uri  string
+	}
+	seen := make(map[key]bool)
+
+	cur := ref
+	for {
+		k := key{root: root, uri: jsonschema.ResolveURI(base, cur)}
+		if seen[k] {
+			return nil, &ReferenceError{Ref: ref, Err: fmt.Errorf("circular reference through %q", cur)}
+		}
+		seen[k] = true
+
+		target, targetRoot, targetBase, err := r.lookupSchema(root, base, cur)
+		if err != nil {
+			return nil, &ReferenceError{Ref: ref, Err: chainError(ref, cur, err)}
+		}
+		if target.Schema == nil || target.Ref == nil {
+			return target, nil
+		}
+		root, base, cur = targetRoot, targetBase, *target.Ref
+	}
+}
+
+// lookupSchema returns the schema referred by the ref used in root whose base URI is base, and the root and the base
+// URI of the returned schema.
+func (r *Resolver) lookupSchema(root *JSONSchema, base, ref string) (*JSONSchema, *JSONSchema, string, error) {
+	st := r.state
+	scope := &Resolver{state: st, scope: &origin{doc: r.scope.doc, base: base}}
+	if root != nil {
+		if o := r.Scope(root).scope; o.doc != nil {
+			scope.scope.doc = o.doc
+		}
+	}
+	doc, fragment, err := scope.locate(ref)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	if doc != nil {
+		v, err := st.decode(doc, fragment, reflect.TypeOf(JSONSchema{}))
+		if err != nil {
+			return nil, nil, "", err
+		}
+		js := v.(*JSONSchema)
+		return js, js, r.Scope(js).scope.base, nil
+	}
+
+	name, rest, err := parseComponentPointer(fragment, "schemas")
+	switch {
+	case err == nil:
+		js := r.components().Schemas[name]
+		if js == nil {
+			return nil, nil, "", fmt.Errorf("unknown schema %q", name)
+		}
+		jsBase := st.base
+		if js.Schema != nil {
+			jsBase = jsonschema.BaseURI(js.Schema, jsBase)
+		}
+		if rest == "" {
+			return js, js, jsBase, nil
+		}
+		sub, subBase, err := lookupSubschema(js, jsBase, rest)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return sub, js, subBase, nil
+
+	case root == nil:
+		return nil, nil, "", err
+
+	default:
+		rootBase := st.base
+		if root.Schema != nil {
+			rootBase = jsonschema.BaseURI(root.Schema, rootBase)
+		}
+		sub, subBase, err := lookupSubschema(root, rootBase, fragment)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return sub, root, subBase, nil
+	}
+}
+
+// lookupSubschema returns the subschema of js at the JSON Pointer, which may be percent-encoded as the URI fragment,
+// and its base URI in the scope of base.
+func lookupSubschema(js *JSONSchema, base, pointer string) (*JSONSchema, string, error) {
+	s := js.Schema
+	if s == nil {
+		s = new(jsonschema.Schema)
+	}
+	sub, subBase, err := jsonschema.Lookup(s, base, pointer)
+	if err != nil {
+		return nil, "", err
+	}
+	if sub == s {
+		return js, subBase, nil
+	}
+	return &JSONSchema{Schema: sub}, subBase, nil
+}
+
+// CheckReferences resolves every reference of the document, including the "$ref"s nested in the JSON Schemas,
+// and returns the unresolvable ones as ValidationErrors located at their uses, or nil if all the references are resolved.
+//
+// The external references are resolved by the Resolver configured by opts, and not checked without the Loader.
+// The references in the loaded documents are not checked.
+func (s *Schema) CheckReferences(opts ...ResolverOption) error {
+	c := &checker{schema: s}
+	r := NewResolver(s, opts...)
+
+	for i, m := range s.Methods {
+		if m == nil {
+			continue
+		}
+		path := []string{"methods", strconv.Itoa(i)}
+		for j, t := range m.Tags {
+			if t != nil && t.IsReference() {
+				c.checkReference(appendPath(path, "tags", strconv.Itoa(j)), t.Reference.Ref, r.resolveTag)
+			}
+		}
+		for j, p := range m.Params {
+			c.checkContentDescriptorReferences(r, p, appendPath(path, "params", strconv.Itoa(j)))
+		}
+		c.checkContentDescriptorReferences(r, m.Result, appendPath(path, "result"))
+		for j, e := range m.Errors {
+			if e != nil && e.IsReference() {
+				c.checkReference(appendPath(path, "errors", strconv.Itoa(j)), e.Reference.Ref, r.resolveError)
+			}
+		}
+		for j, l := range m.Links {
+			if l != nil && l.IsReference() {
+				c.checkReference(appendPath(path, "links", strconv.Itoa(j)), l.Reference.Ref, r.resolveLink)
+			}
+		}
+		for j, e := range m.Examples {
+			examplePath := appendPath(path, "examples", strconv.Itoa(j))
+			switch {
+			case e == nil:
+			case e.IsReference():
+				c.checkReference(examplePath, e.Reference.Ref, r.resolveExamplePairing)
+			case e.IsExamplePairing():
+				c.checkExamplePairingReferences(r, e.ExamplePairing, examplePath)
+			}
+		}
+	}
+
+	if comps := s.Components; comps != nil {
+		for _, name := range sortedMapKeys(comps.ContentDescriptors) {
+			cd := comps.ContentDescriptors[name]
+			if cd == nil {
+				continue
+			}
+			path := []string{"components", "contentDescriptors", name}
+			c.checkSchemaReferences(r, cd.Schema, appendPath(path, "schema"))
+			for i, p := range cd.Examples {
+				c.checkExamplePairingReferences(r, p, appendPath(path, "examples", strconv.Itoa(i)))
+			}
+		}
+		for _, name := range sortedMapKeys(comps.Schemas) {
+			c.checkSchemaReferences(r, comps.Schemas[name], []string{"components", "schemas", name})
+		}
+		for _, name := range sortedMapKeys(comps.ExamplePairingObjects) {
+			c.checkExamplePairingReferences(r, comps.ExamplePairingObjects[name], []string{"components", "examplePairingObjects", name})
+		}
+	}
+
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+func (r *Resolver) resolveContentDescriptor(ref string) error {
+	_, err := r.ResolveContentDescriptor(ref)
+	return err
+}
+
+func (r *Resolver) resolveError(ref string) error {
+	_, err := r.ResolveError(ref)
+	return err
+}
+
+func (r *Resolver) resolveExample(ref string) error {
+	_, err := r.ResolveExample(ref)
+	return err
+}
+
+func (r *Resolver) resolveLink(ref string) error {
+	_, err := r.ResolveLink(ref)
+	return err
+}
+
+func (r *Resolver) resolveTag(ref string) error {
+	_, err := r.ResolveTag(ref)
+	return err
+}
+
+func (r *Resolver) resolveExamplePairing(ref string) error {
+	_, err := r.ResolveExamplePairing(ref)
+	return err
+}
+
+// checkReference reports the ref used at the path which the resolve fails to resolve. The external references are
+// skipped without the Loader.
+func (c *checker) checkReference(path []string, ref string, resolve func(ref string) error) {
+	err := resolve(ref)
+	if err == nil || errors.Is(err, errExternalReference) {
+		return
+	}
+	var rerr *ReferenceError
+	if errors.As(err, &rerr) {
+		err = rerr.Err
+	}
+	c.report(RuleUnresolvedReference, path, "cannot resolve %q: %v", ref, err)
+}
+
+func (c *checker) checkContentDescriptorReferences(r *Resolver, cd *ContentDescriptorOrReference, path []string) {
+	switch {
+	case cd == nil:
+	case cd.IsReference():
+		c.checkReference(path, cd.Reference.Ref, r.resolveContentDescriptor)
+	case cd.IsOneOf():
+		for i, cd := range cd.OneOf.OneOf {
+			c.checkContentDescriptorReferences(r, cd, appendPath(path, "oneOf", strconv.Itoa(i)))
+		}
+	case cd.IsContentDescriptor():
+		c.checkSchemaReferences(r, cd.ContentDescriptor.Schema, appendPath(path, "schema"))
+		for i, p := range cd.ContentDescriptor.Examples {
+			c.checkExamplePairingReferences(r, p, appendPath(path, "examples", strconv.Itoa(i)))
+		}
+	}
+}
+
+func (c *checker) checkExamplePairingReferences(r *Resolver, p *ExamplePairing, path []string) {
+	if p == nil {
+		return
+	}
+	for i, e := range p.Params {
+		if e != nil && e.IsReference() {
+			c.checkReference(appendPath(path, "params", strconv.Itoa(i)), e.Reference.Ref, r.resolveExample)
+		}
+	}
+	if p.Result != nil && p.Result.IsReference() {
+		c.checkReference(appendPath(path, "result"), p.Result.Reference.Ref, r.resolveExample)
+	}
+}
+
+// checkSchemaReferences checks the "$ref"s of the JSONSchema js and its nested subschemas.
+func (c *checker) checkSchemaReferences(r *Resolver, js *JSONSchema, path []string) {
+	if js == nil || js.Schema == nil {
+		return
+	}
+	jsonschema.Walk(js.Schema, r.BaseURI(), func(s *jsonschema.Schema, tokens []string, base string) bool {
+		if s.Ref != nil {
+			c.checkReference(appendPath(path, tokens...), *s.Ref, func(ref string) error {
+				_, err := r.resolveSchema(js, base, ref)
+				return err
+			})
+		}
+		return true
+	})
+}
+
+// sortedMapKeys returns the sorted keys of the map of the components.
+func sortedMapKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*ContentDescriptor:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*JSONSchema:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*ExamplePairing:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
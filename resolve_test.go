@@ -0,0 +1,270 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// resolveTestSchema returns the document of the JSON text data.
+func resolveTestSchema(t *testing.T, data string) *Schema {
+	t.Helper()
+
+	s := new(Schema)
+	if err := json.Unmarshal([]byte(data), s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+const resolveTestDocument = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "t", "version": "1"},
+	"methods": [],
+	"components": {
+		"contentDescriptors": {
+			"cd": {"name": "cd", "schema": {"$ref": "#/components/schemas/a"}},
+			"a/b c": {"name": "escaped", "schema": {}}
+		},
+		"schemas": {
+			"a": {"$ref": "#/components/schemas/b"},
+			"b": {"type": "object", "properties": {"p": {"type": "integer"}}, "definitions": {"d": {"type": "string"}}},
+			"loop1": {"$ref": "#/components/schemas/loop2"},
+			"loop2": {"$ref": "#/components/schemas/loop1"},
+			"self": {"$ref": "#/components/schemas/self"},
+			"ided": {"$id": "https://example.com/ided.json", "definitions": {"x": {"type": "null"}}}
+		},
+		"errors": {"e": {"code": 1, "message": "x"}},
+		"tags": {"t": {"name": "t"}},
+		"links": {"l": {"name": "l"}},
+		"examples": {"ex": {"name": "ex", "value": 1}},
+		"examplePairingObjects": {"p": {"name": "p", "params": [], "result": {"name": "r", "value": 1}}}
+	}
+}`
+
+func TestResolveObjects(t *testing.T) {
+	r := NewResolver(resolveTestSchema(t, resolveTestDocument))
+	c := r.state.schema.Components
+
+	tests := map[string]struct {
+		resolve func() (interface{}, error)
+		want    interface{}
+	}{
+		"contentDescriptor": {func() (interface{}, error) { return r.ResolveContentDescriptor("#/components/contentDescriptors/cd") }, c.ContentDescriptors["cd"]},
+		"escaped": {func() (interface{}, error) {
+			return r.ResolveContentDescriptor("#/components/contentDescriptors/a~1b%20c")
+		}, c.ContentDescriptors["a/b c"]},
+		"error":          {func() (interface{}, error) { return r.ResolveError("#/components/errors/e") }, c.Errors["e"]},
+		"tag":            {func() (interface{}, error) { return r.ResolveTag("#/components/tags/t") }, c.Tags["t"]},
+		"link":           {func() (interface{}, error) { return r.ResolveLink("#/components/links/l") }, c.Links["l"]},
+		"example":        {func() (interface{}, error) { return r.ResolveExample("#/components/examples/ex") }, c.Examples["ex"]},
+		"examplePairing": {func() (interface{}, error) { return r.ResolveExamplePairing("#/components/examplePairingObjects/p") }, c.ExamplePairingObjects["p"]},
+	}
+	for name, tt := range tests {
+		got, err := tt.resolve()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: resolved to %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveSchema(t *testing.T) {
+	s := resolveTestSchema(t, resolveTestDocument)
+	r := NewResolver(s)
+
+	tests := map[string]string{
+		"#/components/schemas/a":                       `{"type":"object","properties":{"p":{"type":"integer"}},"definitions":{"d":{"type":"string"}}}`,
+		"#/components/schemas/b/properties/p":          `{"type":"integer"}`,
+		"#/components/schemas/b/definitions/d":         `{"type":"string"}`,
+		"https://example.com/ided.json#/definitions/x": `{"type":"null"}`,
+	}
+	for ref, want := range tests {
+		js, err := r.ResolveSchema(ref)
+		if err != nil {
+			t.Errorf("ResolveSchema(%q): %v", ref, err)
+			continue
+		}
+		if got := mustJSONSchema(t, want); !reflect.DeepEqual(js, got) {
+			gotJSON, _ := json.Marshal(js)
+			t.Errorf("ResolveSchema(%q) = %s, want %s", ref, gotJSON, want)
+		}
+	}
+}
+
+func TestResolveSchemaRef(t *testing.T) {
+	r := NewResolver(resolveTestSchema(t, resolveTestDocument))
+
+	root := mustJSONSchema(t, `{"definitions": {"n": {"type": "number"}, "m": {"$ref": "#/definitions/n"}}}`)
+	js, err := r.ResolveSchemaRef(root, "#/definitions/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(js); string(got) != `{"type":"number"}` {
+		t.Fatalf("ResolveSchemaRef = %s", got)
+	}
+
+	if _, err := r.ResolveSchema("#/definitions/n"); err == nil {
+		t.Fatal("ResolveSchema of the definition without the root succeeded")
+	}
+}
+
+func TestResolveError(t *testing.T) {
+	r := NewResolver(resolveTestSchema(t, resolveTestDocument))
+
+	tests := map[string]struct {
+		resolve func() error
+		ref     string
+		msg     string
+	}{
+		"unknown":        {resolve: func() error { _, err := r.ResolveError("#/components/errors/none"); return err }, ref: "#/components/errors/none"},
+		"otherKind":      {resolve: func() error { _, err := r.ResolveError("#/components/tags/t"); return err }, ref: "#/components/tags/t", msg: "must refer"},
+		"inComponent":    {resolve: func() error { _, err := r.ResolveError("#/components/errors/e/code"); return err }, ref: "#/components/errors/e/code", msg: "must refer the component itself"},
+		"invalidEscape":  {resolve: func() error { _, err := r.ResolveTag("#/components/tags/%zz"); return err }, ref: "#/components/tags/%zz", msg: "invalid fragment"},
+		"external":       {resolve: func() error { _, err := r.ResolveLink("other.json#/components/links/l"); return err }, ref: "other.json#/components/links/l", msg: "external reference"},
+		"unknownSchema":  {resolve: func() error { _, err := r.ResolveSchema("#/components/schemas/none"); return err }, ref: "#/components/schemas/none", msg: "unknown schema"},
+		"circular":       {resolve: func() error { _, err := r.ResolveSchema("#/components/schemas/loop1"); return err }, ref: "#/components/schemas/loop1", msg: "circular reference"},
+		"self":           {resolve: func() error { _, err := r.ResolveSchema("#/components/schemas/self"); return err }, ref: "#/components/schemas/self", msg: "circular reference"},
+		"invalidPointer": {resolve: func() error { _, err := r.ResolveSchema("#/components/schemas/b/properties/q"); return err }, ref: "#/components/schemas/b/properties/q"},
+	}
+	for name, tt := range tests {
+		err := tt.resolve()
+		var rerr *ReferenceError
+		if !errors.As(err, &rerr) {
+			t.Errorf("%s: got %v, want *ReferenceError", name, err)
+			continue
+		}
+		if rerr.Ref != tt.ref || rerr.Pointer != "" {
+			t.Errorf("%s: Ref = %q, Pointer = %q, want %q and the empty Pointer", name, rerr.Ref, rerr.Pointer, tt.ref)
+		}
+		if !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%s: %q does not contain %q", name, err, tt.msg)
+		}
+	}
+
+	if _, err := r.ResolveLink("other.json#/components/links/l"); !errors.Is(err, errExternalReference) {
+		t.Errorf("external reference error = %v, want errExternalReference", err)
+	}
+}
+
+func TestResolveExternal(t *testing.T) {
+	s := resolveTestSchema(t, `{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"methods": [],
+		"components": {"schemas": {"local": {"type": "boolean"}}}
+	}`)
+	loader := MemoryLoader{
+		"/specs/common.json": []byte(`{
+			"components": {
+				"contentDescriptors": {"cd": {"name": "cd", "schema": {"$ref": "#/components/schemas/s"}}},
+				"schemas": {"s": {"$ref": "types.json#/T"}, "back": {"$ref": "openrpc.json#/components/schemas/local"}},
+				"errors": {"chained": {"$ref": "#/components/errors/e"}, "e": {"code": 7, "message": "m"}}
+			}
+		}`),
+		"/specs/types.json":  []byte(`{"T": {"type": "string"}}`),
+		"/specs/broken.json": []byte(`{`),
+	}
+	r := NewResolver(s, WithLoader(loader), WithBaseURI("/specs/openrpc.json"))
+
+	cd, err := r.ResolveContentDescriptor("common.json#/components/contentDescriptors/cd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cd.Name != "cd" {
+		t.Fatalf("content descriptor = %+v", cd)
+	}
+	if again, _ := r.ResolveContentDescriptor("./common.json#/components/contentDescriptors/cd"); again != cd {
+		t.Fatal("the loaded document is not cached")
+	}
+
+	// the reference in the loaded object is resolved against its document
+	scope := r.Scope(cd)
+	if got, want := scope.BaseURI(), "/specs/common.json"; got != want {
+		t.Fatalf("BaseURI() = %q, want %q", got, want)
+	}
+	js, err := scope.ResolveSchemaRef(cd.Schema, *cd.Schema.Ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(js); string(got) != `{"type":"string"}` {
+		t.Fatalf("ResolveSchemaRef = %s", got)
+	}
+
+	back, err := r.ResolveSchema("common.json#/components/schemas/back")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back != s.Components.Schemas["local"] {
+		t.Fatal("the reference back to the root document is not resolved to the root document")
+	}
+
+	e, err := r.ResolveError("common.json#/components/errors/chained")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Code != 7 {
+		t.Fatalf("error = %+v", e)
+	}
+
+	for _, ref := range []string{"missing.json#/components/errors/e", "broken.json#/components/errors/e", "common.json#/components/errors/none"} {
+		var rerr *ReferenceError
+		if _, err := r.ResolveError(ref); !errors.As(err, &rerr) || rerr.Ref != ref {
+			t.Errorf("ResolveError(%q) = %v, want *ReferenceError", ref, err)
+		}
+	}
+}
+
+func TestCheckReferences(t *testing.T) {
+	s := resolveTestSchema(t, `{
+		"openrpc": "1.2.6",
+		"info": {"title": "t", "version": "1"},
+		"methods": [{
+			"name": "m",
+			"tags": [{"$ref": "#/components/tags/none"}],
+			"params": [{"$ref": "#/components/contentDescriptors/cd"}, {"name": "p", "schema": {"items": {"$ref": "#/components/schemas/none"}}}],
+			"result": {"name": "r", "schema": {"$ref": "#/definitions/none"}},
+			"errors": [{"$ref": "#/components/errors/e"}, {"$ref": "#/components/errors/none"}],
+			"links": [{"$ref": "other.json#/components/links/l"}],
+			"examples": [{"name": "e", "params": [{"$ref": "#/components/examples/none"}], "result": {"name": "r", "value": 1}}]
+		}],
+		"components": {
+			"contentDescriptors": {"cd": {"name": "cd", "schema": {"$ref": "#/components/schemas/loop"}}},
+			"schemas": {"loop": {"$ref": "#/components/schemas/loop"}},
+			"errors": {"e": {"code": 1, "message": "x"}}
+		}
+	}`)
+
+	var verrs ValidationErrors
+	if err := s.CheckReferences(); !errors.As(err, &verrs) {
+		t.Fatalf("CheckReferences() = %v, want ValidationErrors", err)
+	}
+	got := make([]string, len(verrs))
+	for i, verr := range verrs {
+		got[i] = verr.Pointer
+		if verr.Rule != RuleUnresolvedReference {
+			t.Errorf("Rule = %q, want %q", verr.Rule, RuleUnresolvedReference)
+		}
+	}
+	want := []string{
+		"/methods/0/tags/0",
+		"/methods/0/params/1/schema/items",
+		"/methods/0/result/schema",
+		"/methods/0/errors/1",
+		"/methods/0/examples/0/params/0",
+		"/components/contentDescriptors/cd/schema",
+		"/components/schemas/loop",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CheckReferences() = %q, want %q", got, want)
+	}
+}
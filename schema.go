@@ -6,6 +6,7 @@ package openrpc
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 
 	"github.com/zchee/go-openrpc/internal/jsonschema"
@@ -37,7 +38,7 @@ type Schema struct {
 	Components *Components `json:"components,omitempty"`
 
 	// Additional external documentation.
-	ExternalDocs *ExternalDocumentation `json:"externaldocs,omitempty"`
+	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty"`
 
 	// Allows extensions to the OpenRPC Schema.
 	Extensions []*Extension `json:"-"`
@@ -146,11 +147,13 @@ type ServerVariables struct {
 
 // ParamStructure is the expected format of the parameters.
 //
-// As per the JSON-RPC 2.0 specification, params may be either an array, an object, or either. Defaults to "by-position".
+// As per the JSON-RPC 2.0 specification, params may be either an array, an object, or either. Defaults to "either".
+//
+// The zero value is unset and is distinguishable from the explicit Either, so the unset `paramStructure` is omitted when encoding.
 type ParamStructure int
 
 const (
-	ByPosition ParamStructure = iota
+	ByPosition ParamStructure = iota + 1
 	ByName
 	Either
 )
@@ -169,6 +172,39 @@ func (p ParamStructure) String() string {
 	}
 }
 
+// OrDefault returns the p, or Either if the p is unset.
+func (p ParamStructure) OrDefault() ParamStructure {
+	if p == 0 {
+		return Either
+	}
+	return p
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p ParamStructure) MarshalText() ([]byte, error) {
+	switch p {
+	case ByPosition, ByName, Either:
+		return []byte(p.String()), nil
+	default:
+		return nil, fmt.Errorf("openrpc: invalid paramStructure %d", int(p))
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *ParamStructure) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "by-position":
+		*p = ByPosition
+	case "by-name":
+		*p = ByName
+	case "either":
+		*p = Either
+	default:
+		return fmt.Errorf("openrpc: unknown paramStructure %q: must be one of \"by-position\", \"by-name\" or \"either\"", text)
+	}
+	return nil
+}
+
 // Method describes the interface for the given method name.
 //
 // The method name is used as the method field of the JSON-RPC body. It therefore MUST be unique.
@@ -179,7 +215,7 @@ type Method struct {
 	Name string `json:"name"`
 
 	// A list of tags for API documentation control. Tags can be used for logical grouping of methods by resources or any other qualifier.
-	Tags []*Tag `json:"tags,omitempty"`
+	Tags []*TagOrReference `json:"tags,omitempty"`
 
 	// A short summary of what the method does.
 	Summary string `json:"summary,omitempty"`
@@ -188,7 +224,7 @@ type Method struct {
 	Description string `json:"description,omitempty"`
 
 	// Additional external documentation for this method.
-	ExternalDocs *ExternalDocumentation `json:"externaldocs,omitempty"`
+	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty"`
 
 	// A list of parameters that are applicable for this method. The list MUST NOT include duplicated parameters and therefore require name to be unique.
 	// The list can use the Reference Object to link to parameters that are defined by the ContentDescriptor.
@@ -197,12 +233,12 @@ type Method struct {
 	// All optional params (content descriptor objects with “required”: false) MUST be positioned after all required params in the list.
 	//
 	// REQUIRED.
-	Params []*ContentDescriptor `json:"params"`
+	Params []*ContentDescriptorOrReference `json:"params"`
 
 	// The description of the result returned by the method. It MUST be a Content Descriptor.
 	//
 	// REQUIRED.
-	Result *ContentDescriptor `json:"result"`
+	Result *ContentDescriptorOrReference `json:"result"`
 
 	// Declares this method to be deprecated. Consumers SHOULD refrain from usage of the declared method. Default value is `false`.
 	Deprecated bool `json:"deprecated,omitempty"`
@@ -211,16 +247,16 @@ type Method struct {
 	Servers []*Server `json:"servers,omitempty"`
 
 	// A list of custom application defined errors that MAY be returned. The Errors MUST have unique error codes.
-	Errors []*Error `json:"errors,omitempty"`
+	Errors []*ErrorOrReference `json:"errors,omitempty"`
 
 	// A list of possible links from this method call.
-	Links []*Link `json:"links,omitempty"`
+	Links []*LinkOrReference `json:"links,omitempty"`
 
-	// The expected format of the parameters. As per the JSON-RPC 2.0 specification, params may be either an array, an object, or either. Defaults to "by-position".
+	// The expected format of the parameters. As per the JSON-RPC 2.0 specification, params may be either an array, an object, or either. Defaults to "either".
 	ParamStructure ParamStructure `json:"paramStructure,omitempty"`
 
 	// Array of Example Pairing Object where each example includes a valid params-to-result Content Descriptor pairing.
-	Examples []*ExamplePairing `json:"examples,omitempty"`
+	Examples []*ExamplePairingOrReference `json:"examples,omitempty"`
 
 	// Allows extensions to the OpenRPC Schema.
 	Extensions []*Extension `json:"-"`
@@ -259,6 +295,8 @@ type ContentDescriptor struct {
 // JSONSchema is the Schema Object allows the definition of input and output data types.
 // The JSONSchema MUST follow the specifications outline in the JSON Schema Specification 7 Alternatively, any time a JSONSchema can be used, a Reference Object can be used in its place.
 // This allows referencing definitions instead of defining them inline.
+//
+// A JSONSchema MAY also be the boolean schema which is the literal `true` or `false`.
 type JSONSchema struct {
 	*jsonschema.Schema
 
@@ -266,6 +304,13 @@ type JSONSchema struct {
 	Extensions []*Extension `json:"-"`
 }
 
+// NewBoolJSONSchema returns the boolean JSONSchema which is the literal `true` or `false`.
+//
+// The `true` schema always passes validation, and the `false` schema always fails validation.
+func NewBoolJSONSchema(v bool) *JSONSchema {
+	return &JSONSchema{Schema: jsonschema.NewBool(v)}
+}
+
 // ExamplePairing represents a pairing object consists of a set of example params and result.
 //
 // The result is what you can expect from the JSON-RPC service given the exact params.
@@ -280,10 +325,10 @@ type ExamplePairing struct {
 	Summary string `json:"summary,omitempty"`
 
 	// Example parameters.
-	Params []*Example `json:"params,omitempty"`
+	Params []*ExampleOrReference `json:"params,omitempty"`
 
 	// Example result.
-	Result *Example `json:"result,omitempty"`
+	Result *ExampleOrReference `json:"result,omitempty"`
 
 	// Allows extensions to the OpenRPC Schema.
 	Extensions []*Extension `json:"-"`
@@ -297,7 +342,7 @@ type Example struct {
 	Name string `json:"name,omitempty"`
 
 	// Short description for the example.
-	Summary string `json:"tags,omitempty"`
+	Summary string `json:"summary,omitempty"`
 
 	// A verbose explanation of the example. GitHub Flavored Markdown syntax MAY be used for rich text representation.
 	Description string `json:"description,omitempty"`
@@ -339,7 +384,7 @@ type Link struct {
 	// A map representing parameters to pass to a method as specified with `method`.
 	//
 	// The key is the parameter name to be used, whereas the value can be a constant or a RuntimeExpression to be evaluated and passed to the linked method.
-	Params map[interface{}]RuntimeExpressions `json:"params,omitempty"`
+	Params map[string]LinkParam `json:"params,omitempty"`
 
 	// A server object to be used by the target method.
 	Server *Server `json:"server,omitempty"`
@@ -353,6 +398,41 @@ type Link struct {
 // They are used when the desired value of a link or server can only be constructed at run time. This mechanism is used by Link Objects and Server Variables.
 type RuntimeExpressions string
 
+// LinkParam is a value of the Link params which is either a RuntimeExpressions or a constant.
+//
+// The JSON string which begins with `$` is decoded as the RuntimeExpressions, and any other JSON value is decoded as the constant.
+type LinkParam struct {
+	// The runtime expression to be evaluated and passed to the linked method.
+	Expression RuntimeExpressions
+
+	// The literal JSON constant passed to the linked method as is.
+	Constant json.RawMessage
+}
+
+// NewExpressionLinkParam returns the LinkParam of the runtime expression expr.
+func NewExpressionLinkParam(expr RuntimeExpressions) LinkParam {
+	return LinkParam{Expression: expr}
+}
+
+// NewConstantLinkParam returns the LinkParam of the JSON encoding of v.
+func NewConstantLinkParam(v interface{}) (LinkParam, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return LinkParam{}, fmt.Errorf("openrpc: marshal link param constant: %w", err)
+	}
+	return LinkParam{Constant: data}, nil
+}
+
+// IsExpression reports whether the p is the runtime expression.
+func (p LinkParam) IsExpression() bool {
+	return p.Expression != ""
+}
+
+// IsConstant reports whether the p is the literal JSON constant.
+func (p LinkParam) IsConstant() bool {
+	return p.Expression == "" && len(p.Constant) > 0
+}
+
 // ErrorCode is a number that indicates the error type that occurred.
 type ErrorCode int64
 
@@ -372,10 +452,17 @@ const (
 	// InternalError is the internal JSON-RPC error.
 	InternalError = ErrorCode(-32603)
 
-	// Reserved for implementation-defined server-errors.
-	_ = ErrorCode(-32099) // codeServerErrorStart
-	// Reserved for implementation-defined server-errors.
-	_ = ErrorCode(-32000) // codeServerErrorEnd
+	// ServerErrorStart is the start of the codes reserved for implementation-defined server-errors.
+	ServerErrorStart = ErrorCode(-32099)
+
+	// ServerErrorEnd is the end of the codes reserved for implementation-defined server-errors.
+	ServerErrorEnd = ErrorCode(-32000)
+
+	// ReservedErrorStart is the start of the codes reserved for pre-defined errors.
+	ReservedErrorStart = ErrorCode(-32768)
+
+	// ReservedErrorEnd is the end of the codes reserved for pre-defined errors.
+	ReservedErrorEnd = ErrorCode(-32000)
 )
 
 // Error defines an application level error.
@@ -444,7 +531,7 @@ type Tag struct {
 	Description string `json:"description,omitempty"`
 
 	// Additional external documentation for this tag.
-	ExternalDocs *ExternalDocumentation `json:"externaldocs,omitempty"`
+	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty"`
 
 	// Allows extensions to the OpenRPC Schema.
 	Extensions []*Extension `json:"-"`
@@ -481,19 +568,19 @@ type Reference struct {
 //
 // This allows you to define content descriptors more granularly, without having to rely so heavily on json schemas.
 type OneOf struct {
-	// The reference string.
+	// The list of content descriptors or references to the content descriptor, one of which the content MUST match.
 	//
 	// REQUIRED.
-	OneOf *ContentDescriptor `json:"oneOf"`
+	OneOf []*ContentDescriptorOrReference `json:"oneOf"`
 }
 
 // Extension while the OpenRPC Specification tries to accommodate most use cases, additional data can be added to extend the specification at certain points.
 //
 // The extensions properties are implemented as patterned fields that are always prefixed by `"x-"`.
 type Extension struct {
-	// Allows extensions to the OpenRPC Schema.
-	// The field name MUST begin with `x-`, for example, `x-internal-id`.
-	//
+	// The patterned field name. The field name MUST begin with `x-`, for example, `x-internal-id`.
+	Name string `json:"-"` // ^x-
+
 	// The value can be `null`, a primitive, an array or an object. Can have any valid JSON format value.
-	Pattern []interface{} `json:"-"` // ^x-
+	Value json.RawMessage `json:"-"`
 }
@@ -0,0 +1,228 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParamStructureText(t *testing.T) {
+	tests := map[string]struct {
+		p    ParamStructure
+		text string
+	}{
+		"byPosition": {p: ByPosition, text: `"by-position"`},
+		"byName":     {p: ByName, text: `"by-name"`},
+		"either":     {p: Either, text: `"either"`},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(tt.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.text {
+				t.Fatalf("Marshal = %s, want %s", data, tt.text)
+			}
+
+			var p ParamStructure
+			if err := json.Unmarshal(data, &p); err != nil {
+				t.Fatal(err)
+			}
+			if p != tt.p {
+				t.Fatalf("Unmarshal = %v, want %v", p, tt.p)
+			}
+		})
+	}
+}
+
+func TestParamStructureTextError(t *testing.T) {
+	if _, err := json.Marshal(ParamStructure(0)); err == nil {
+		t.Fatal("Marshal of the unset ParamStructure succeeded")
+	}
+
+	for _, text := range []string{`"bogus"`, `"By-Name"`, `""`} {
+		var p ParamStructure
+		if err := json.Unmarshal([]byte(text), &p); err == nil {
+			t.Fatalf("Unmarshal(%s) succeeded", text)
+		}
+	}
+}
+
+func TestParamStructureOrDefault(t *testing.T) {
+	tests := map[ParamStructure]ParamStructure{
+		0:          Either,
+		ByPosition: ByPosition,
+		ByName:     ByName,
+		Either:     Either,
+	}
+	for p, want := range tests {
+		if got := p.OrDefault(); got != want {
+			t.Errorf("%v.OrDefault() = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestMethodParamStructure(t *testing.T) {
+	tests := map[string]struct {
+		doc  string
+		want ParamStructure
+		enc  string
+	}{
+		"unset":  {doc: `{"name": "a"}`, want: 0, enc: `{"name":"a","params":null,"result":null}`},
+		"byName": {doc: `{"name": "a", "paramStructure": "by-name"}`, want: ByName, enc: `{"name":"a","params":null,"result":null,"paramStructure":"by-name"}`},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var m Method
+			if err := json.Unmarshal([]byte(tt.doc), &m); err != nil {
+				t.Fatal(err)
+			}
+			if m.ParamStructure != tt.want {
+				t.Fatalf("ParamStructure = %v, want %v", m.ParamStructure, tt.want)
+			}
+			data, err := json.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.enc {
+				t.Fatalf("Marshal = %s, want %s", data, tt.enc)
+			}
+		})
+	}
+
+	var m Method
+	if err := json.Unmarshal([]byte(`{"name": "a", "paramStructure": 1}`), &m); err == nil {
+		t.Fatal("Unmarshal of the number paramStructure succeeded")
+	}
+}
+
+func TestJSONSchemaBool(t *testing.T) {
+	tests := map[string]struct {
+		doc   string
+		valid bool
+	}{
+		"true":  {doc: `{"name":"r","schema":true}`, valid: true},
+		"false": {doc: `{"name":"r","schema":false}`},
+		"items": {doc: `{"name":"r","schema":{"items":false,"type":"array"}}`},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var cd ContentDescriptor
+			if err := json.Unmarshal([]byte(tt.doc), &cd); err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(cd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.doc {
+				t.Fatalf("Marshal = %s, want %s", data, tt.doc)
+			}
+
+			v, err := CompileSchema(cd.Schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.Validate([]interface{}{1}); (err == nil) != tt.valid {
+				t.Fatalf("Validate = %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestLinkParams(t *testing.T) {
+	data := `{"name":"l","params":{"a":"$params.a","b":"literal","c":1,"d":{"e":[true,null]},"f":null}}`
+	want := map[string]LinkParam{
+		"a": NewExpressionLinkParam("$params.a"),
+		"b": {Constant: json.RawMessage(`"literal"`)},
+		"c": {Constant: json.RawMessage(`1`)},
+		"d": {Constant: json.RawMessage(`{"e":[true,null]}`)},
+		"f": {Constant: json.RawMessage(`null`)},
+	}
+
+	decoders := map[string]func(l *Link) error{
+		"json": func(l *Link) error { return json.Unmarshal([]byte(data), l) },
+		"parse": func(l *Link) error {
+			s, err := Parse([]byte(`{"components": {"links": {"l": ` + data + `}}}`))
+			if err == nil {
+				*l = *s.Components.Links["l"]
+			}
+			return err
+		},
+		"yaml": func(l *Link) error { return yaml.Unmarshal([]byte(data), l) },
+	}
+	for name, decode := range decoders {
+		var l Link
+		if err := decode(&l); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(l.Params, want) {
+			t.Errorf("%s: Params = %v, want %v", name, l.Params, want)
+		}
+		if enc := mustMarshalJSON(t, l); enc != data {
+			t.Errorf("%s: Marshal = %s, want %s", name, enc, data)
+		}
+	}
+
+	for name, p := range want {
+		if p.IsExpression() != (name == "a") || p.IsConstant() == (name == "a") {
+			t.Errorf("%s: IsExpression() = %t, IsConstant() = %t", name, p.IsExpression(), p.IsConstant())
+		}
+	}
+}
+
+func TestLinkParamConstant(t *testing.T) {
+	p, err := NewConstantLinkParam(map[string]int{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsConstant() || string(p.Constant) != `{"a":1}` {
+		t.Fatalf("NewConstantLinkParam = %+v", p)
+	}
+	if _, err := NewConstantLinkParam(func() {}); err == nil {
+		t.Fatal("NewConstantLinkParam of the function succeeded")
+	}
+
+	tests := map[string]struct {
+		p       LinkParam
+		want    string
+		wantErr string
+	}{
+		"zero":       {p: LinkParam{}, want: `null`},
+		"compacted":  {p: LinkParam{Constant: json.RawMessage("[1, 2]")}, want: `[1,2]`},
+		"dollar":     {p: LinkParam{Constant: json.RawMessage(`"$a"`)}, wantErr: "indistinguishable from the runtime expression"},
+		"invalid":    {p: LinkParam{Constant: json.RawMessage(`{`)}, wantErr: "invalid link param constant"},
+		"expression": {p: NewExpressionLinkParam("$result"), want: `"$result"`},
+		"backslash":  {p: LinkParam{Constant: json.RawMessage(`"\\u0024a"`)}, want: `"\\u0024a"`},
+		"escaped":    {p: LinkParam{Constant: json.RawMessage(`"\u0024a"`)}, wantErr: "indistinguishable from the runtime expression"},
+	}
+	for name, tt := range tests {
+		got, err := json.Marshal(tt.p)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: Marshal = %v, want the error containing %q", name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: Marshal = %s, %v, want %s", name, got, err, tt.want)
+		}
+	}
+
+	var l Link
+	if err := json.Unmarshal([]byte(`{"params": {"a": "\u0024b"}}`), &l); err != nil || !l.Params["a"].IsExpression() {
+		t.Errorf("the escaped dollar is not decoded as the runtime expression: %+v, %v", l.Params["a"], err)
+	}
+}
@@ -0,0 +1,500 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Decoder reads and decodes the OpenRPC document encoded in JSON from the input stream.
+//
+// The Decoder reads the document member by member, and the methods element by element, and decodes each of them with
+// the same decoder as Parse. So the Decoder never holds the whole text nor the node tree of the whole document,
+// and Methods decodes each Method on demand without decoding the rest of the document.
+//
+// The Decoder does not support the YAML documents. Decode and Methods read the input stream, so only one of them can be
+// called, and only once.
+type Decoder struct {
+	r    *positionReader
+	dec  *json.Decoder
+	opts *parseOptions
+	used bool
+}
+
+// NewDecoder returns the new Decoder which reads the JSON document from r.
+//
+// WithStrict is supported, and WithFormat other than FormatAuto and FormatJSON is reported by Decode and Methods.
+func NewDecoder(r io.Reader, opts ...ParseOption) *Decoder {
+	pr := &positionReader{r: r}
+	return &Decoder{
+		r:    pr,
+		dec:  json.NewDecoder(pr),
+		opts: newParseOptions(opts),
+	}
+}
+
+// Decode decodes the whole document.
+func (dec *Decoder) Decode() (*Schema, error) {
+	if err := dec.use(); err != nil {
+		return nil, err
+	}
+
+	schema := new(Schema)
+	ok, err := dec.openObject(schema)
+	if err != nil || !ok {
+		return schema, err
+	}
+
+	v := reflect.ValueOf(schema).Elem()
+	fields := cachedFields(v.Type())
+	for dec.dec.More() {
+		key, err := dec.readKey()
+		if err != nil {
+			return nil, err
+		}
+
+		if dec.isMethods(fields, key) {
+			found, err := dec.openMethods(key)
+			if err != nil {
+				return nil, err
+			}
+			schema.Methods = nil
+			if !found {
+				continue
+			}
+			schema.Methods = []*Method{}
+
+			it := &MethodIterator{dec: dec, key: key, index: -1, started: true}
+			for it.Next() {
+				m, err := it.Method()
+				if err != nil {
+					return nil, err
+				}
+				schema.Methods = append(schema.Methods, m)
+			}
+			if err := it.Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		raw, base, err := dec.readValue(key)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseNode(raw)
+		if err != nil {
+			return nil, dec.relocate(err, base, []string{key})
+		}
+		d := &decoder{src: raw, strict: dec.opts.strict}
+		if err := d.decodeMember(v, fields, key, n, &schema.Extensions); err != nil {
+			return nil, dec.relocate(err, base, nil)
+		}
+	}
+
+	if err := dec.closeObject(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// Methods returns the iterator over the methods of the document which decodes each Method on demand.
+//
+// The members of the document other than the methods are skipped without decoding.
+func (dec *Decoder) Methods() *MethodIterator {
+	it := &MethodIterator{dec: dec, index: -1}
+	if err := dec.use(); err != nil {
+		it.stop(err)
+	}
+	return it
+}
+
+// Method decodes the method whose name is name, or returns nil if the document does not have it.
+func (dec *Decoder) Method(name string) (*Method, error) {
+	it := dec.Methods()
+	for it.Next() {
+		if it.Name() == name {
+			return it.Method()
+		}
+	}
+	return nil, it.Err()
+}
+
+// use marks the input stream as read.
+func (dec *Decoder) use() error {
+	if dec.used {
+		return errors.New("openrpc: Decoder has already read the document")
+	}
+	dec.used = true
+
+	switch dec.opts.format {
+	case FormatAuto, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("openrpc: Decoder does not support the %s format", dec.opts.format)
+	}
+}
+
+// openObject reads the beginning of the top-level object, and reports whether the document is the object.
+//
+// The top-level null is the empty document like Parse.
+func (dec *Decoder) openObject(schema *Schema) (bool, error) {
+	offset := dec.nextOffset()
+	tok, err := dec.dec.Token()
+	if err != nil {
+		return false, dec.syntaxError(err, nil)
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		return true, nil
+	case nil:
+		return false, dec.end()
+	default:
+		return false, dec.errorAt(nil, offset, "cannot decode %s into %T", tokenKind(tok), *schema)
+	}
+}
+
+// closeObject reads the end of the top-level object, and checks that the input stream has no more value.
+func (dec *Decoder) closeObject() error {
+	if _, err := dec.dec.Token(); err != nil {
+		return dec.syntaxError(err, nil)
+	}
+	return dec.end()
+}
+
+// end checks that the input stream has no more value.
+func (dec *Decoder) end() error {
+	offset := dec.nextOffset()
+	switch _, err := dec.dec.Token(); {
+	case err == io.EOF:
+		return nil
+	case err != nil:
+		return dec.syntaxError(err, nil)
+	default:
+		return dec.errorAt(nil, offset, "invalid value after top-level value")
+	}
+}
+
+// seekMethods skips the members of the top-level object until the methods,
+// and returns the object key of the methods and reports whether the methods are found.
+func (dec *Decoder) seekMethods() (string, bool, error) {
+	if ok, err := dec.openObject(new(Schema)); err != nil || !ok {
+		return "", false, err
+	}
+
+	fields := cachedFields(reflect.TypeOf(Schema{}))
+	for dec.dec.More() {
+		key, err := dec.readKey()
+		if err != nil {
+			return "", false, err
+		}
+		if dec.isMethods(fields, key) {
+			found, err := dec.openMethods(key)
+			return key, found, err
+		}
+		if err := dec.dec.Decode(new(discard)); err != nil {
+			return "", false, dec.syntaxError(err, []string{key})
+		}
+	}
+
+	return "", false, dec.closeObject()
+}
+
+// isMethods reports whether the object key of the Schema is the methods.
+func (dec *Decoder) isMethods(fields *structFields, key string) bool {
+	idx, ok := fields.lookup(key, dec.opts.strict)
+	return ok && len(idx) == 1 && idx[0] == methodsField
+}
+
+// methodsField is the index of the Schema.Methods field.
+var methodsField = func() int {
+	f, _ := reflect.TypeOf(Schema{}).FieldByName("Methods")
+	return f.Index[0]
+}()
+
+// openMethods reads the beginning of the methods array, and reports whether the methods are not null.
+func (dec *Decoder) openMethods(key string) (bool, error) {
+	offset := dec.nextOffset()
+	tok, err := dec.dec.Token()
+	if err != nil {
+		return false, dec.syntaxError(err, []string{key})
+	}
+
+	switch tok {
+	case json.Delim('['):
+		return true, nil
+	case nil:
+		return false, nil
+	default:
+		return false, dec.errorAt([]string{key}, offset, "cannot decode %s into %T", tokenKind(tok), []*Method(nil))
+	}
+}
+
+// readKey reads the object key.
+func (dec *Decoder) readKey() (string, error) {
+	tok, err := dec.dec.Token()
+	if err != nil {
+		return "", dec.syntaxError(err, nil)
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", dec.errorf(nil, "invalid object key %v", tok)
+	}
+	return key, nil
+}
+
+// readValue reads the next value at the path, and returns the JSON text and its byte offset in the input stream.
+func (dec *Decoder) readValue(path ...string) ([]byte, int64, error) {
+	var raw json.RawMessage
+	if err := dec.dec.Decode(&raw); err != nil {
+		return nil, 0, dec.syntaxError(err, path)
+	}
+	return raw, dec.dec.InputOffset() - int64(len(raw)), nil
+}
+
+// nextOffset returns the byte offset of the next token in the input stream,
+// or the current offset if the input stream does not have the next token.
+func (dec *Decoder) nextOffset() int64 {
+	dec.dec.More() // fills the buffer
+	data, _ := io.ReadAll(dec.dec.Buffered())
+	offset := dec.r.n - int64(len(data))
+	for i, c := range data {
+		switch c {
+		case ' ', '\t', '\r', '\n', ',', ':':
+		default:
+			return offset + int64(i)
+		}
+	}
+	return offset
+}
+
+// errorf returns the *DecodeError located at the current position of the input stream.
+func (dec *Decoder) errorf(path []string, format string, args ...interface{}) error {
+	return dec.errorAt(path, dec.dec.InputOffset(), format, args...)
+}
+
+// errorAt returns the *DecodeError located at the offset of the input stream.
+func (dec *Decoder) errorAt(path []string, offset int64, format string, args ...interface{}) error {
+	line, column := dec.r.position(offset)
+	return &DecodeError{
+		Pointer: formatPointer(path),
+		Line:    line,
+		Column:  column,
+		Offset:  offset,
+		Err:     fmt.Errorf(format, args...),
+	}
+}
+
+// syntaxError returns the err of reading the input stream as *DecodeError, or as is if the err is not the syntax error.
+func (dec *Decoder) syntaxError(err error, path []string) error {
+	var serr *json.SyntaxError
+	switch {
+	case errors.As(err, &serr):
+		offset := serr.Offset
+		if offset > 0 && !strings.HasPrefix(serr.Error(), "unexpected end") {
+			offset-- // the offset is after the offending byte
+		}
+		return dec.errorAt(path, offset, "%s", serr)
+	case err == io.EOF, err == io.ErrUnexpectedEOF:
+		return dec.errorAt(path, dec.r.n, "unexpected end of JSON input")
+	default:
+		return fmt.Errorf("openrpc: read document: %w", err)
+	}
+}
+
+// relocate locates the *DecodeError err of the value which begins at the base offset of the input stream,
+// and prepends the path to its JSON Pointer.
+func (dec *Decoder) relocate(err error, base int64, path []string) error {
+	var derr *DecodeError
+	if !errors.As(err, &derr) {
+		return err
+	}
+
+	derr.Pointer = formatPointer(path) + derr.Pointer
+	if derr.Offset >= 0 {
+		derr.Offset += base
+		derr.Line, derr.Column = dec.r.position(derr.Offset)
+	}
+	return err
+}
+
+// MethodIterator iterates the methods of the document without decoding the whole document.
+//
+//	it := dec.Methods()
+//	for it.Next() {
+//		m, err := it.Method()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type MethodIterator struct {
+	dec   *Decoder
+	key   string
+	index int
+
+	// raw, base and node are the JSON text, its byte offset and the node tree of the current method.
+	raw  []byte
+	base int64
+	node *node
+
+	started bool
+	done    bool
+	err     error
+}
+
+// Next advances the iterator to the next method, and reports whether there is the method.
+func (it *MethodIterator) Next() bool {
+	if it.done {
+		return false
+	}
+
+	dec := it.dec
+	if !it.started {
+		it.started = true
+		key, found, err := dec.seekMethods()
+		if err != nil || !found {
+			return it.stop(err)
+		}
+		it.key = key
+	}
+
+	if !dec.dec.More() {
+		if _, err := dec.dec.Token(); err != nil {
+			return it.stop(dec.syntaxError(err, []string{it.key}))
+		}
+		return it.stop(nil)
+	}
+
+	it.index++
+	path := []string{it.key, strconv.Itoa(it.index)}
+	raw, base, err := dec.readValue(path...)
+	if err != nil {
+		return it.stop(err)
+	}
+	n, err := parseNode(raw)
+	if err != nil {
+		return it.stop(dec.relocate(err, base, path))
+	}
+	it.raw, it.base, it.node = raw, base, n
+
+	return true
+}
+
+func (it *MethodIterator) stop(err error) bool {
+	it.done = true
+	it.err = err
+	it.raw, it.node = nil, nil
+	return false
+}
+
+// Index returns the index of the current method in the methods array.
+func (it *MethodIterator) Index() int {
+	return it.index
+}
+
+// Name returns the name of the current method without decoding the method, or empty if the method has no name.
+func (it *MethodIterator) Name() string {
+	if it.node == nil || it.node.kind != objectNode {
+		return ""
+	}
+	if name := it.node.member("name"); name != nil && name.kind == stringNode {
+		return name.value
+	}
+	return ""
+}
+
+// Raw returns the JSON text of the current method.
+func (it *MethodIterator) Raw() []byte {
+	return it.raw
+}
+
+// Method decodes the current method.
+func (it *MethodIterator) Method() (*Method, error) {
+	if it.node == nil {
+		return nil, errors.New("openrpc: no current method")
+	}
+	if it.node.kind == nullNode {
+		return nil, nil
+	}
+
+	m := new(Method)
+	d := &decoder{src: it.raw, strict: it.dec.opts.strict, path: []string{it.key, strconv.Itoa(it.index)}}
+	if err := d.decode(it.node, reflect.ValueOf(m)); err != nil {
+		return nil, it.dec.relocate(err, it.base, nil)
+	}
+	return m, nil
+}
+
+// Err returns the error occurred while iterating.
+func (it *MethodIterator) Err() error {
+	return it.err
+}
+
+// discard skips the JSON value without holding it.
+type discard struct{}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (*discard) UnmarshalJSON([]byte) error { return nil }
+
+// tokenKind returns the kind of the JSON value which begins with the token tok for the error message.
+func tokenKind(tok json.Token) string {
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			return "array"
+		}
+		return "object"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	default:
+		return "null"
+	}
+}
+
+// positionReader records the byte offsets of the newlines read from r to locate the byte offset by the line and column.
+type positionReader struct {
+	r io.Reader
+
+	// n is the number of bytes read.
+	n int64
+
+	// newlines holds the byte offsets of the newlines in ascending order.
+	newlines []int64
+}
+
+// Read implements io.Reader.
+func (r *positionReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for i, c := range p[:n] {
+		if c == '\n' {
+			r.newlines = append(r.newlines, r.n+int64(i))
+		}
+	}
+	r.n += int64(n)
+
+	return n, err
+}
+
+// position returns the 1-based line and column of the byte offset, like lineColumn.
+func (r *positionReader) position(offset int64) (line, column int) {
+	i := sort.Search(len(r.newlines), func(i int) bool { return r.newlines[i] >= offset })
+	if i == 0 {
+		return 1, int(offset) + 1
+	}
+	return i + 1, int(offset - r.newlines[i-1])
+}
@@ -0,0 +1,350 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const streamTestDocument = `{
+  "openrpc": "1.2.6",
+  "x-origin": {"team": "core"},
+  "info": {"title": "Petstore", "version": "1.0.0", "x-internal": true},
+  "servers": [{"name": "main", "url": "http://localhost:8080"}],
+  "methods": [
+    {
+      "name": "list_pets",
+      "tags": [{"name": "pets"}, {"$ref": "#/components/tags/read"}],
+      "paramStructure": "by-name",
+      "params": [
+        {"name": "limit", "required": true, "schema": {"type": "integer", "minimum": 1, "maximum": 100}},
+        {"$ref": "#/components/contentDescriptors/Cursor"}
+      ],
+      "result": {
+        "name": "pets",
+        "schema": {
+          "type": "array",
+          "items": {"$ref": "#/components/schemas/Pet"},
+          "additionalItems": false
+        }
+      },
+      "errors": [{"code": 100, "message": "too many"}],
+      "examples": [{"name": "one", "params": [{"name": "limit", "value": 1}], "result": {"name": "pets", "value": []}}],
+      "x-rate-limit": 10
+    },
+    {
+      "name": "get_pet",
+      "params": [{"name": "id", "schema": {"type": ["string", "null"], "pattern": "^[a-z]+$"}}],
+      "result": {"oneOf": [{"name": "pet", "schema": {"$ref": "#/components/schemas/Pet"}}, {"name": "none", "schema": {"type": "null"}}]}
+    },
+    null
+  ],
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {"name": {"type": "string"}, "tag": {"type": "string", "default": null}},
+        "additionalProperties": false
+      },
+      "Any": true
+    },
+    "contentDescriptors": {"Cursor": {"name": "cursor", "schema": {"type": "string"}}},
+    "tags": {"read": {"name": "read"}}
+  },
+  "externalDocs": {"url": "https://example.com"}
+}`
+
+func TestDecoderDecode(t *testing.T) {
+	tests := map[string]struct {
+		doc  string
+		opts []ParseOption
+	}{
+		"document":    {doc: streamTestDocument},
+		"strict":      {doc: streamTestDocument, opts: []ParseOption{WithStrict(true)}},
+		"empty":       {doc: `{}`},
+		"null":        {doc: `null`},
+		"nullMethods": {doc: `{"openrpc": "1.2.6", "methods": null}`},
+		"noMethods":   {doc: `{"openrpc": "1.2.6", "methods": []}`},
+		"foldedKeys":  {doc: `{"OpenRPC": "1.2.6", "Methods": [{"Name": "a", "Params": []}], "INFO": {"title": "t"}}`},
+		"unknownKeys": {doc: `{"unknown": {"a": [1, 2]}, "methods": [{"name": "a", "unknown": 1}]}`},
+		"duplicateMethods": {
+			doc: `{"methods": [{"name": "a"}], "methods": [{"name": "b"}]}`,
+		},
+		"large": {doc: string(largeDocument(b2i(testing.Short(), 20, 200)))},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			want, err := Parse([]byte(tt.doc), tt.opts...)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := NewDecoder(strings.NewReader(tt.doc), tt.opts...).Decode()
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				g, _ := json.Marshal(got)
+				w, _ := json.Marshal(want)
+				t.Fatalf("Decode differs from Parse:\ngot:  %s\nwant: %s", g, w)
+			}
+		})
+	}
+}
+
+func TestDecoderError(t *testing.T) {
+	tests := map[string]struct {
+		doc  string
+		opts []ParseOption
+	}{
+		"methodField":     {doc: "{\n  \"methods\": [\n    {\"name\": 1}\n  ]\n}"},
+		"memberField":     {doc: "{\n  \"info\": {\"title\": [true]}\n}"},
+		"methodsType":     {doc: `{"methods": {}}`},
+		"methodsString":   {doc: `{"methods": "a"}`},
+		"topLevelType":    {doc: "\n[]"},
+		"unknownStrict":   {doc: "{\"methods\": [{\"name\": \"a\",\n \"bogus\": 1}]}", opts: []ParseOption{WithStrict(true)}},
+		"unknownTopLevel": {doc: "{\"openrpc\": \"1.2.6\",\n \"bogus\": 1}", opts: []ParseOption{WithStrict(true)}},
+		"paramStructure":  {doc: `{"methods": [{"name": "a", "paramStructure": "bogus"}]}`},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			_, perr := Parse([]byte(tt.doc), tt.opts...)
+			_, derr := NewDecoder(strings.NewReader(tt.doc), tt.opts...).Decode()
+
+			var want, got *DecodeError
+			if !errors.As(perr, &want) {
+				t.Fatalf("Parse: got %v, want *DecodeError", perr)
+			}
+			if !errors.As(derr, &got) {
+				t.Fatalf("Decode: got %v, want *DecodeError", derr)
+			}
+			if got.Pointer != want.Pointer || got.Line != want.Line || got.Column != want.Column || got.Offset != want.Offset {
+				t.Fatalf("Decode error is located at %s %d:%d (%d), want %s %d:%d (%d)\ngot:  %v\nwant: %v",
+					got.Pointer, got.Line, got.Column, got.Offset, want.Pointer, want.Line, want.Column, want.Offset, derr, perr)
+			}
+		})
+	}
+}
+
+func TestDecoderSyntaxError(t *testing.T) {
+	tests := map[string]struct {
+		doc          string
+		line, column int
+	}{
+		"badValue":   {doc: "{\n  \"methods\": [}", line: 2, column: 15},
+		"trailing":   {doc: "{}\n {}", line: 2, column: 2},
+		"truncated":  {doc: `{"openrpc": "1.2.6"`, line: 1, column: 20},
+		"badKey":     {doc: `{1: 2}`, line: 1, column: 2},
+		"badElement": {doc: "{\"methods\": [{\"name\": \"a\"} {}]}", line: 1, column: 28},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			_, err := NewDecoder(strings.NewReader(tt.doc)).Decode()
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Fatalf("got %v, want *DecodeError", err)
+			}
+			if derr.Line != tt.line || derr.Column != tt.column {
+				t.Fatalf("got %d:%d, want %d:%d: %v", derr.Line, derr.Column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestDecoderMethods(t *testing.T) {
+	want, err := Parse([]byte(streamTestDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := NewDecoder(strings.NewReader(streamTestDocument)).Methods()
+	var names []string
+	for it.Next() {
+		if it.Index() != len(names) {
+			t.Fatalf("Index() = %d, want %d", it.Index(), len(names))
+		}
+		names = append(names, it.Name())
+
+		m, err := it.Method()
+		if err != nil {
+			t.Fatalf("Method(%d): %v", it.Index(), err)
+		}
+		if !reflect.DeepEqual(m, want.Methods[it.Index()]) {
+			t.Fatalf("Method(%d) = %+v, want %+v", it.Index(), m, want.Methods[it.Index()])
+		}
+		var raw interface{}
+		if err := json.Unmarshal(it.Raw(), &raw); err != nil {
+			t.Fatalf("Raw(%d) is not JSON: %v", it.Index(), err)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names, []string{"list_pets", "get_pet", ""}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("names = %q, want %q", got, want)
+	}
+}
+
+func TestDecoderMethod(t *testing.T) {
+	m, err := NewDecoder(strings.NewReader(streamTestDocument)).Method("get_pet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil || m.Name != "get_pet" || m.Result == nil || m.Result.OneOf == nil {
+		t.Fatalf("Method(get_pet) = %+v", m)
+	}
+
+	m, err = NewDecoder(strings.NewReader(streamTestDocument)).Method("missing")
+	if err != nil || m != nil {
+		t.Fatalf("Method(missing) = %v, %v, want nil, nil", m, err)
+	}
+}
+
+func TestDecoderMethodsError(t *testing.T) {
+	it := NewDecoder(strings.NewReader("{\"info\": {},\n\"methods\": [{\"name\": \"a\"}, {\"name\": 1}]}")).Methods()
+	for it.Next() {
+		if _, err := it.Method(); err != nil {
+			var derr *DecodeError
+			if !errors.As(err, &derr) || derr.Pointer != "/methods/1/name" || derr.Line != 2 || derr.Column != 37 {
+				t.Fatalf("got %v, want the error at /methods/1/name 2:37", err)
+			}
+			return
+		}
+	}
+	t.Fatalf("no error: %v", it.Err())
+}
+
+func TestDecoderReadOnce(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{}`))
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.Decode(); err == nil {
+		t.Fatal("second Decode succeeded")
+	}
+	it := dec.Methods()
+	if it.Next() || it.Err() == nil {
+		t.Fatal("Methods after Decode succeeded")
+	}
+}
+
+func TestDecoderYAML(t *testing.T) {
+	if _, err := NewDecoder(strings.NewReader(`{}`), WithFormat(FormatYAML)).Decode(); err == nil {
+		t.Fatal("Decode of YAML succeeded")
+	}
+}
+
+// b2i returns a if cond, otherwise b.
+func b2i(cond bool, a, b int) int {
+	if cond {
+		return a
+	}
+	return b
+}
+
+// largeDocument returns the JSON document which has n methods with the deeply inlined schemas.
+func largeDocument(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"openrpc": "1.2.6", "info": {"title": "large", "version": "1.0.0"}, "methods": [`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `
+  {
+    "name": "namespace_method%d",
+    "summary": "Method %d.",
+    "paramStructure": "by-position",
+    "params": [
+      {"name": "id", "required": true, "schema": {"type": "string", "pattern": "^0x[0-9a-f]+$"}},
+      {"name": "filter", "schema": {
+        "type": "object",
+        "properties": {
+          "from": {"type": "integer", "minimum": 0},
+          "to": {"type": "integer", "minimum": 0},
+          "topics": {"type": "array", "items": {"anyOf": [{"type": "string"}, {"type": "array", "items": {"type": "string"}}]}}
+        },
+        "additionalProperties": false
+      }}
+    ],
+    "result": {"name": "result", "schema": {
+      "type": "object",
+      "required": ["hash", "logs"],
+      "properties": {
+        "hash": {"type": "string"},
+        "logs": {"type": "array", "items": {"$ref": "#/components/schemas/Log"}},
+        "status": {"enum": ["ok", "failed"]}
+      }
+    }},
+    "errors": [{"code": %d, "message": "failed %d"}],
+    "x-index": %d
+  }`, i, i, i+1, i, i)
+	}
+	buf.WriteString(`],
+  "components": {"schemas": {"Log": {"type": "object", "properties": {"data": {"type": "string"}}}}}
+}`)
+	return buf.Bytes()
+}
+
+const benchmarkMethods = 1000
+
+func BenchmarkDecoder(b *testing.B) {
+	data := largeDocument(benchmarkMethods)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewDecoder(bytes.NewReader(data)).Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecoderMethod(b *testing.B) {
+	data := largeDocument(benchmarkMethods)
+	name := fmt.Sprintf("namespace_method%d", benchmarkMethods/2)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewDecoder(bytes.NewReader(data)).Method(name); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	data := largeDocument(benchmarkMethods)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSONUnmarshal(b *testing.B) {
+	data := largeDocument(benchmarkMethods)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var s Schema
+		if err := json.Unmarshal(data, &s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ContentDescriptorOrReference represents a ContentDescriptor, a Reference to the ContentDescriptor or a OneOf Object.
+//
+// The OneOf Object is only allowed in place of the Method params.
+type ContentDescriptorOrReference struct {
+	ContentDescriptor *ContentDescriptor
+	Reference         *Reference
+	OneOf             *OneOf
+}
+
+// IsContentDescriptor reports whether the c holds the inline ContentDescriptor.
+func (c *ContentDescriptorOrReference) IsContentDescriptor() bool {
+	return c != nil && c.ContentDescriptor != nil
+}
+
+// IsReference reports whether the c holds the Reference.
+func (c *ContentDescriptorOrReference) IsReference() bool {
+	return c != nil && c.Reference != nil
+}
+
+// IsOneOf reports whether the c holds the OneOf Object.
+func (c *ContentDescriptorOrReference) IsOneOf() bool {
+	return c != nil && c.OneOf != nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c ContentDescriptorOrReference) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Reference != nil:
+		return json.Marshal(c.Reference)
+	case c.OneOf != nil:
+		return json.Marshal(c.OneOf)
+	case c.ContentDescriptor != nil:
+		return json.Marshal(c.ContentDescriptor)
+	default:
+		return nil, errors.New("openrpc: empty ContentDescriptorOrReference")
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *ContentDescriptorOrReference) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, c)
+}
+
+func (c *ContentDescriptorOrReference) variant(isRef, isOneOf bool) interface{} {
+	*c = ContentDescriptorOrReference{}
+	switch {
+	case isRef:
+		c.Reference = new(Reference)
+		return c.Reference
+	case isOneOf:
+		c.OneOf = new(OneOf)
+		return c.OneOf
+	default:
+		c.ContentDescriptor = new(ContentDescriptor)
+		return c.ContentDescriptor
+	}
+}
+
+// ErrorOrReference represents an Error or a Reference to the Error.
+type ErrorOrReference struct {
+	Error     *Error
+	Reference *Reference
+}
+
+// IsError reports whether the e holds the inline Error.
+func (e *ErrorOrReference) IsError() bool {
+	return e != nil && e.Error != nil
+}
+
+// IsReference reports whether the e holds the Reference.
+func (e *ErrorOrReference) IsReference() bool {
+	return e != nil && e.Reference != nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e ErrorOrReference) MarshalJSON() ([]byte, error) {
+	switch {
+	case e.Reference != nil:
+		return json.Marshal(e.Reference)
+	case e.Error != nil:
+		return json.Marshal(e.Error)
+	default:
+		return nil, errors.New("openrpc: empty ErrorOrReference")
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *ErrorOrReference) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, e)
+}
+
+func (e *ErrorOrReference) variant(isRef, _ bool) interface{} {
+	*e = ErrorOrReference{}
+	if isRef {
+		e.Reference = new(Reference)
+		return e.Reference
+	}
+	e.Error = new(Error)
+	return e.Error
+}
+
+// TagOrReference represents a Tag or a Reference to the Tag.
+type TagOrReference struct {
+	Tag       *Tag
+	Reference *Reference
+}
+
+// IsTag reports whether the t holds the inline Tag.
+func (t *TagOrReference) IsTag() bool {
+	return t != nil && t.Tag != nil
+}
+
+// IsReference reports whether the t holds the Reference.
+func (t *TagOrReference) IsReference() bool {
+	return t != nil && t.Reference != nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t TagOrReference) MarshalJSON() ([]byte, error) {
+	switch {
+	case t.Reference != nil:
+		return json.Marshal(t.Reference)
+	case t.Tag != nil:
+		return json.Marshal(t.Tag)
+	default:
+		return nil, errors.New("openrpc: empty TagOrReference")
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *TagOrReference) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, t)
+}
+
+func (t *TagOrReference) variant(isRef, _ bool) interface{} {
+	*t = TagOrReference{}
+	if isRef {
+		t.Reference = new(Reference)
+		return t.Reference
+	}
+	t.Tag = new(Tag)
+	return t.Tag
+}
+
+// LinkOrReference represents a Link or a Reference to the Link.
+type LinkOrReference struct {
+	Link      *Link
+	Reference *Reference
+}
+
+// IsLink reports whether the l holds the inline Link.
+func (l *LinkOrReference) IsLink() bool {
+	return l != nil && l.Link != nil
+}
+
+// IsReference reports whether the l holds the Reference.
+func (l *LinkOrReference) IsReference() bool {
+	return l != nil && l.Reference != nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l LinkOrReference) MarshalJSON() ([]byte, error) {
+	switch {
+	case l.Reference != nil:
+		return json.Marshal(l.Reference)
+	case l.Link != nil:
+		return json.Marshal(l.Link)
+	default:
+		return nil, errors.New("openrpc: empty LinkOrReference")
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *LinkOrReference) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, l)
+}
+
+func (l *LinkOrReference) variant(isRef, _ bool) interface{} {
+	*l = LinkOrReference{}
+	if isRef {
+		l.Reference = new(Reference)
+		return l.Reference
+	}
+	l.Link = new(Link)
+	return l.Link
+}
+
+// ExamplePairingOrReference represents an ExamplePairing or a Reference to the ExamplePairing.
+type ExamplePairingOrReference struct {
+	ExamplePairing *ExamplePairing
+	Reference      *Reference
+}
+
+// IsExamplePairing reports whether the e holds the inline ExamplePairing.
+func (e *ExamplePairingOrReference) IsExamplePairing() bool {
+	return e != nil && e.ExamplePairing != nil
+}
+
+// IsReference reports whether the e holds the Reference.
+func (e *ExamplePairingOrReference) IsReference() bool {
+	return e != nil && e.Reference != nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e ExamplePairingOrReference) MarshalJSON() ([]byte, error) {
+	switch {
+	case e.Reference != nil:
+		return json.Marshal(e.Reference)
+	case e.ExamplePairing != nil:
+		return json.Marshal(e.ExamplePairing)
+	default:
+		return nil, errors.New("openrpc: empty ExamplePairingOrReference")
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *ExamplePairingOrReference) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, e)
+}
+
+func (e *ExamplePairingOrReference) variant(isRef, _ bool) interface{} {
+	*e = ExamplePairingOrReference{}
+	if isRef {
+		e.Reference = new(Reference)
+		return e.Reference
+	}
+	e.ExamplePairing = new(ExamplePairing)
+	return e.ExamplePairing
+}
+
+// ExampleOrReference represents an Example or a Reference to the Example.
+type ExampleOrReference struct {
+	Example   *Example
+	Reference *Reference
+}
+
+// IsExample reports whether the e holds the inline Example.
+func (e *ExampleOrReference) IsExample() bool {
+	return e != nil && e.Example != nil
+}
+
+// IsReference reports whether the e holds the Reference.
+func (e *ExampleOrReference) IsReference() bool {
+	return e != nil && e.Reference != nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e ExampleOrReference) MarshalJSON() ([]byte, error) {
+	switch {
+	case e.Reference != nil:
+		return json.Marshal(e.Reference)
+	case e.Example != nil:
+		return json.Marshal(e.Example)
+	default:
+		return nil, errors.New("openrpc: empty ExampleOrReference")
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *ExampleOrReference) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, e)
+}
+
+func (e *ExampleOrReference) variant(isRef, _ bool) interface{} {
+	*e = ExampleOrReference{}
+	if isRef {
+		e.Reference = new(Reference)
+		return e.Reference
+	}
+	e.Example = new(Example)
+	return e.Example
+}
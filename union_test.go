@@ -0,0 +1,132 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestContentDescriptorOrReference(t *testing.T) {
+	tests := map[string]struct {
+		data                       string
+		isContentDescriptor, isRef bool
+		isOneOf                    bool
+	}{
+		"contentDescriptor": {data: `{"name":"a","schema":{}}`, isContentDescriptor: true},
+		"reference":         {data: `{"$ref":"#/components/contentDescriptors/a"}`, isRef: true},
+		"oneOf":             {data: `{"oneOf":[{"name":"a","schema":{}},{"$ref":"#/components/contentDescriptors/b"}]}`, isOneOf: true},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			// the previous variant is reset by the decoding
+			c := &ContentDescriptorOrReference{ContentDescriptor: new(ContentDescriptor), Reference: new(Reference)}
+			if err := json.Unmarshal([]byte(tt.data), c); err != nil {
+				t.Fatal(err)
+			}
+			if c.IsContentDescriptor() != tt.isContentDescriptor || c.IsReference() != tt.isRef || c.IsOneOf() != tt.isOneOf {
+				t.Fatalf("IsContentDescriptor, IsReference, IsOneOf = %t, %t, %t", c.IsContentDescriptor(), c.IsReference(), c.IsOneOf())
+			}
+			data, err := json.Marshal(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.data {
+				t.Fatalf("Marshal = %s, want %s", data, tt.data)
+			}
+		})
+	}
+
+	if (*ContentDescriptorOrReference)(nil).IsContentDescriptor() {
+		t.Error("the nil union holds the content descriptor")
+	}
+	c := new(ContentDescriptorOrReference)
+	if err := json.Unmarshal([]byte(`{"oneOf":[{"$ref":"#/a"}]}`), c); err != nil || !c.OneOf.OneOf[0].IsReference() {
+		t.Errorf("the nested reference of the oneOf is not decoded: %v", err)
+	}
+}
+
+func TestMethodUnions(t *testing.T) {
+	data := `{
+		"name": "m",
+		"tags": [{"name": "t"}, {"$ref": "#/components/tags/t"}],
+		"params": [{"$ref": "#/components/contentDescriptors/p"}, {"name": "q", "schema": {}}],
+		"result": {"$ref": "#/components/contentDescriptors/r"},
+		"errors": [{"code": 1, "message": "m"}, {"$ref": "#/components/errors/e"}],
+		"links": [{"name": "l"}, {"$ref": "#/components/links/l"}],
+		"examples": [{"name": "e", "params": [{"name": "a", "value": 1}, {"$ref": "#/components/examples/a"}], "result": {"name": "r", "value": 1}}, {"$ref": "#/components/examplePairingObjects/e"}]
+	}`
+	var m Method
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := map[string]bool{
+		"tags/0":            m.Tags[0].IsTag() && !m.Tags[0].IsReference(),
+		"tags/1":            m.Tags[1].IsReference() && m.Tags[1].Reference.Ref == "#/components/tags/t",
+		"params/0":          m.Params[0].IsReference(),
+		"params/1":          m.Params[1].IsContentDescriptor(),
+		"result":            m.Result.IsReference(),
+		"errors/0":          m.Errors[0].IsError() && m.Errors[0].Error.Code == 1,
+		"errors/1":          m.Errors[1].IsReference(),
+		"links/0":           m.Links[0].IsLink(),
+		"links/1":           m.Links[1].IsReference(),
+		"examples/0":        m.Examples[0].IsExamplePairing(),
+		"examples/1":        m.Examples[1].IsReference(),
+		"examples/0/params": m.Examples[0].ExamplePairing.Params[0].IsExample() && m.Examples[0].ExamplePairing.Params[1].IsReference(),
+	}
+	for name, ok := range checks {
+		if !ok {
+			t.Errorf("%s is decoded as the wrong variant", name)
+		}
+	}
+
+	enc, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again Method
+	if err := json.Unmarshal(enc, &again); err != nil {
+		t.Fatal(err)
+	}
+	if enc2, _ := json.Marshal(again); string(enc2) != string(enc) {
+		t.Fatalf("the round trip changed the method:\n%s\n%s", enc, enc2)
+	}
+}
+
+func TestUnionMarshalEmpty(t *testing.T) {
+	tests := map[string]interface{}{
+		"ContentDescriptorOrReference": ContentDescriptorOrReference{},
+		"ErrorOrReference":             ErrorOrReference{},
+		"TagOrReference":               TagOrReference{},
+		"LinkOrReference":              LinkOrReference{},
+		"ExamplePairingOrReference":    ExamplePairingOrReference{},
+		"ExampleOrReference":           ExampleOrReference{},
+	}
+	for name, v := range tests {
+		if _, err := json.Marshal(v); err == nil || !strings.Contains(err.Error(), "empty "+name) {
+			t.Errorf("Marshal of the empty %s = %v", name, err)
+		}
+	}
+}
+
+func TestUnionUnmarshalError(t *testing.T) {
+	tests := map[string]struct {
+		v    interface{}
+		data string
+	}{
+		"array":       {v: new(ErrorOrReference), data: `[]`},
+		"refType":     {v: new(TagOrReference), data: `{"$ref": 1}`},
+		"inlineField": {v: new(ErrorOrReference), data: `{"code": "a", "message": "m"}`},
+		"oneOfType":   {v: new(ContentDescriptorOrReference), data: `{"oneOf": {}}`},
+	}
+	for name, tt := range tests {
+		if err := json.Unmarshal([]byte(tt.data), tt.v); err == nil {
+			t.Errorf("%s: Unmarshal(%s) succeeded", name, tt.data)
+		}
+	}
+}
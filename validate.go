@@ -0,0 +1,240 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/zchee/go-openrpc/internal/jsonschema"
+)
+
+// ValidationError is a violation of the document.
+type ValidationError struct {
+	// Pointer is the JSON Pointer to the offending value in the JSON encoding of the document.
+	Pointer string
+
+	// Rule is the failing rule, such as the JSON Schema keyword "required" of the meta-schema.
+	Rule string
+
+	// SchemaPath is the JSON Pointer to the failing keyword in the JSON Schema, which goes through the "$ref"s,
+	// or empty if the Rule is not the JSON Schema keyword.
+	SchemaPath string
+
+	// Message describes the violation.
+	Message string
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("openrpc: %s: %s", pointer, e.Message)
+}
+
+// ValidationErrors is the list of the violations of the document.
+type ValidationErrors []*ValidationError
+
+// Error implements error.
+func (e ValidationErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "openrpc: no validation errors"
+	case 1:
+		return e[0].Error()
+	default:
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "openrpc: %d validation errors:", len(e))
+		for _, err := range e {
+			sb.WriteString("\n\t")
+			sb.WriteString(strings.TrimPrefix(err.Error(), "openrpc: "))
+		}
+		return sb.String()
+	}
+}
+
+// metaSchemaFS is the embedded meta-schemas. See metaschema/README.md for the upstream of each file.
+//
+//go:embed metaschema/*.json
+var metaSchemaFS embed.FS
+
+// metaSchemaFiles maps the "major.minor" specification version to the embedded OpenRPC meta-schema.
+var metaSchemaFiles = map[string]string{
+	"1.0": "metaschema/openrpc-1.0.json",
+	"1.2": "metaschema/openrpc-1.2.json",
+	"1.3": "metaschema/openrpc-1.3.json",
+}
+
+// draft07URI is the "$id" of the JSON Schema Draft 7 meta-schema which the OpenRPC meta-schema refers.
+const draft07URI = "http://json-schema.org/draft-07/schema#"
+
+// metaValidators caches the compiled meta-schemas keyed by the "major.minor" version.
+var metaValidators sync.Map // map[string]*jsonschema.Validator
+
+// metaValidator returns the compiled OpenRPC meta-schema of the supported specification version v.
+func metaValidator(v Version) (*jsonschema.Validator, error) {
+	version := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	if val, ok := metaValidators.Load(version); ok {
+		return val.(*jsonschema.Validator), nil
+	}
+
+	name, ok := metaSchemaFiles[version]
+	if !ok {
+		return nil, fmt.Errorf("openrpc: no meta-schema for the version %s", version)
+	}
+	draft07, err := loadMetaSchema("metaschema/draft-07.json")
+	if err != nil {
+		return nil, err
+	}
+	meta, err := loadMetaSchema(name)
+	if err != nil {
+		return nil, err
+	}
+	val, err := jsonschema.Compile(meta, jsonschema.WithResource(draft07URI, draft07))
+	if err != nil {
+		return nil, fmt.Errorf("openrpc: compile meta-schema %s: %w", version, err)
+	}
+
+	actual, _ := metaValidators.LoadOrStore(version, val)
+	return actual.(*jsonschema.Validator), nil
+}
+
+func loadMetaSchema(name string) (*jsonschema.Schema, error) {
+	data, err := metaSchemaFS.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("openrpc: read meta-schema: %w", err)
+	}
+	s := new(jsonschema.Schema)
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("openrpc: parse meta-schema %s: %w", name, err)
+	}
+	return s, nil
+}
+
+// Validate validates the document against the OpenRPC meta-schema of the specification version in s.OpenRPC,
+// and returns every violation as ValidationErrors, or nil if the document is valid.
+//
+// The meta-schemas are embedded in the package, so Validate works offline. The specification version which is not the
+// valid semantic version or whose major version is unknown is reported as RuleOpenRPCVersion, and the rest of the
+// document is validated against the meta-schema of LatestVersion. The unsupported minor version of the known major
+// version is validated against the nearest supported version, such as 1.1.0 against 1.0 and 1.4.0 against 1.3.
+//
+// The empty strings and the nil values of the fields are the absence of the fields,
+// so the empty REQUIRED fields such as Info.Title, Server.URL and ExternalDocumentation.URL are reported as missing.
+func (s *Schema) Validate() error {
+	var verrs ValidationErrors
+	latest, _ := ParseVersion(LatestVersion)
+	meta := latest
+	if s.OpenRPC != "" {
+		v, err := s.Version()
+		switch {
+		case err != nil:
+			verrs = append(verrs, &ValidationError{
+				Pointer: "/openrpc",
+				Rule:    RuleOpenRPCVersion,
+				Message: fmt.Sprintf("%q is not the semantic version number", s.OpenRPC),
+			})
+		case !v.IsKnownMajor():
+			verrs = append(verrs, &ValidationError{
+				Pointer: "/openrpc",
+				Rule:    RuleOpenRPCVersion,
+				Message: fmt.Sprintf("unsupported major version %d, must be one of %q", v.Major, SupportedVersions()),
+			})
+		default:
+			meta, _ = nearestSupported(v)
+		}
+	}
+
+	validator, err := metaValidator(meta)
+	if err != nil {
+		return err
+	}
+
+	instance, err := toInstance(s)
+	if err != nil {
+		return err
+	}
+	instance = pruneAbsent(instance)
+	if obj, ok := instance.(map[string]interface{}); ok && obj["openrpc"] != nil {
+		// the version itself is checked above
+		obj["openrpc"] = fmt.Sprintf("%d.%d.0", meta.Major, meta.Minor)
+	}
+
+	verrs = append(verrs, toValidationErrors(validator.Validate(instance))...)
+	if len(verrs) == 0 {
+		return nil
+	}
+
+	return verrs
+}
+
+// toValidationErrors converts the violations reported by the JSON Schema validator into ValidationErrors.
+func toValidationErrors(errs []*jsonschema.ValidationError) ValidationErrors {
+	verrs := make(ValidationErrors, len(errs))
+	for i, err := range errs {
+		verrs[i] = &ValidationError{
+			Pointer:    err.InstancePath,
+			Rule:       err.Keyword,
+			SchemaPath: err.SchemaPath,
+			Message:    err.Message,
+		}
+	}
+	return verrs
+}
+
+// toInstance returns the JSON encoding of v decoded as the JSON Schema instance, which keeps the numbers as json.Number.
+func toInstance(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var instance interface{}
+	if err := dec.Decode(&instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// opaqueFields are the fields whose values are not the OpenRPC objects but the arbitrary JSON values or the JSON Schemas.
+var opaqueFields = map[string]bool{
+	"schema":  true,
+	"schemas": true,
+	"value":   true,
+	"data":    true,
+}
+
+// pruneAbsent removes the object members of the OpenRPC objects whose values are the empty string or null,
+// which are the Go zero values of the absent fields.
+func pruneAbsent(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			switch {
+			case isExtension(key):
+			case value == nil:
+				delete(v, key)
+			case opaqueFields[key]:
+			case value == "":
+				delete(v, key)
+			default:
+				v[key] = pruneAbsent(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = pruneAbsent(value)
+		}
+	}
+	return v
+}
@@ -0,0 +1,161 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type violation struct {
+		pointer, rule string
+	}
+	const (
+		info   = `"info": {"title": "t", "version": "1"}`
+		method = `{"name": "a", "params": [], "result": {"name": "r", "schema": {}}}`
+	)
+	tests := map[string]struct {
+		data string
+		want []violation
+	}{
+		"valid": {
+			data: `{"openrpc": "1.2.6", ` + info + `, "methods": [` + method + `]}`,
+		},
+		"validExtension": {
+			data: `{"openrpc": "1.0.0", ` + info + `, "methods": [{"name": "a", "params": [], "result": {"name": "r", "schema": {}}, "x-a": 1}]}`,
+		},
+		"missingVersion": {
+			data: `{` + info + `, "methods": []}`,
+			want: []violation{{"", "required"}},
+		},
+		"missingTitle": {
+			data: `{"openrpc": "1.2.6", "info": {"version": "1"}, "methods": []}`,
+			want: []violation{{"/info", "required"}},
+		},
+		"emptyTitle": {
+			data: `{"openrpc": "1.2.6", "info": {"title": "", "version": "1"}, "methods": []}`,
+			want: []violation{{"/info", "required"}},
+		},
+		"missingServerURL": {
+			data: `{"openrpc": "1.2.6", ` + info + `, "servers": [{"name": "s"}], "methods": []}`,
+			want: []violation{{"/servers/0", "required"}},
+		},
+		"invalidSchema": {
+			data: `{"openrpc": "1.2.6", ` + info + `, "methods": [{"name": "a", "params": [], "result": {"name": "r", "schema": {"type": "nope"}}}]}`,
+			want: []violation{{"/methods/0/result/schema/type", "anyOf"}},
+		},
+		"invalidVersion": {
+			data: `{"openrpc": "x", "info": {"version": "1"}, "methods": []}`,
+			want: []violation{{"/openrpc", RuleOpenRPCVersion}, {"/info", "required"}},
+		},
+		"unknownMajor": {
+			data: `{"openrpc": "2.0.0", ` + info + `, "methods": []}`,
+			want: []violation{{"/openrpc", RuleOpenRPCVersion}},
+		},
+		// the result is REQUIRED by 1.0 and optional since 1.3
+		"result1.0": {
+			data: `{"openrpc": "1.0.0", ` + info + `, "methods": [{"name": "a", "params": []}]}`,
+			want: []violation{{"/methods/0", "required"}},
+		},
+		"result1.3": {
+			data: `{"openrpc": "1.3.0", ` + info + `, "methods": [{"name": "a", "params": []}]}`,
+		},
+		"nearestOlder": {
+			data: `{"openrpc": "1.1.0", ` + info + `, "methods": [{"name": "a", "params": []}]}`,
+			want: []violation{{"/methods/0", "required"}},
+		},
+		"nearestNewer": {
+			data: `{"openrpc": "1.4.0", ` + info + `, "methods": [{"name": "a", "params": []}]}`,
+		},
+		"paramStructure1.0": {
+			data: `{"openrpc": "1.0.0", ` + info + `, "methods": [{"name": "a", "params": [], "result": {"name": "r", "schema": {}}, "paramStructure": "by-name"}]}`,
+			want: []violation{{"/methods/0/paramStructure", "additionalProperties"}},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			s := new(Schema)
+			if err := json.Unmarshal([]byte(tt.data), s); err != nil {
+				t.Fatal(err)
+			}
+			err := s.Validate()
+			var got []violation
+			if err != nil {
+				var verrs ValidationErrors
+				if !errors.As(err, &verrs) {
+					t.Fatalf("Validate = %v, want ValidationErrors", err)
+				}
+				for _, verr := range verrs {
+					got = append(got, violation{verr.Pointer, verr.Rule})
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Validate = %v, want %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaPath(t *testing.T) {
+	s := resolveTestSchema(t, `{"openrpc": "1.2.6", "info": {"version": "1"}, "methods": []}`)
+	var verrs ValidationErrors
+	if !errors.As(s.Validate(), &verrs) || len(verrs) != 1 {
+		t.Fatalf("Validate = %v", verrs)
+	}
+	if verr := verrs[0]; verr.SchemaPath != "/properties/info/$ref/required" || !strings.Contains(verr.Message, `"title"`) {
+		t.Fatalf("ValidationError = %+v", verr)
+	}
+}
+
+func TestValidationErrorsString(t *testing.T) {
+	tests := map[string]struct {
+		errs ValidationErrors
+		want string
+	}{
+		"none": {want: "openrpc: no validation errors"},
+		"one": {
+			errs: ValidationErrors{{Pointer: "/info", Message: "missing"}},
+			want: "openrpc: /info: missing",
+		},
+		"root": {
+			errs: ValidationErrors{{Message: "missing"}},
+			want: "openrpc: /: missing",
+		},
+		"many": {
+			errs: ValidationErrors{{Pointer: "/a", Message: "x"}, {Pointer: "/b", Message: "y"}},
+			want: "openrpc: 2 validation errors:\n\t/a: x\n\t/b: y",
+		},
+	}
+	for name, tt := range tests {
+		if got := tt.errs.Error(); got != tt.want {
+			t.Errorf("%s: Error() = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestMetaValidator(t *testing.T) {
+	for _, version := range SupportedVersions() {
+		v, err := ParseVersion(version + ".0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		val, err := metaValidator(v)
+		if err != nil {
+			t.Fatalf("metaValidator(%s) = %v", version, err)
+		}
+		// the compiled meta-schema is cached
+		if again, _ := metaValidator(v); again != val {
+			t.Errorf("metaValidator(%s) is not cached", version)
+		}
+	}
+	if _, err := metaValidator(Version{Major: 1, Minor: 1}); err == nil {
+		t.Error("metaValidator(1.1) succeeded")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/zchee/go-openrpc/internal/jsonschema"
+)
+
+// SchemaValidator is the compiled JSONSchema which validates the JSON values against the JSON Schema Draft 7.
+//
+// The SchemaValidator is safe for concurrent use.
+type SchemaValidator struct {
+	v *jsonschema.Validator
+}
+
+// SchemaOption configures CompileSchema.
+type SchemaOption func(*schemaOptions)
+
+type schemaOptions struct {
+	components *Components
+	formats    bool
+	custom     []jsonschema.CompileOption
+}
+
+// WithComponents resolves the "#/components/schemas/..." references against the Components c.
+func WithComponents(c *Components) SchemaOption {
+	return func(o *schemaOptions) {
+		o.components = c
+	}
+}
+
+// WithFormatAssertion sets whether the "format" keyword is asserted. Defaults to true.
+//
+// The formats defined by the JSON Schema Draft 7 are asserted, and the unknown formats are ignored.
+func WithFormatAssertion(assert bool) SchemaOption {
+	return func(o *schemaOptions) {
+		o.formats = assert
+	}
+}
+
+// WithFormatChecker asserts the "format" keyword of the name with the check, which reports whether the string is valid in
+// the format. The check takes precedence over the checker of the format defined by the JSON Schema Draft 7.
+//
+// The format is not asserted if the assertion is disabled by WithFormatAssertion.
+func WithFormatChecker(name string, check func(s string) bool) SchemaOption {
+	return func(o *schemaOptions) {
+		o.custom = append(o.custom, jsonschema.WithFormat(name, check))
+	}
+}
+
+// CompileSchema compiles the JSONSchema s into the SchemaValidator.
+//
+// The "$ref"s to the "definitions" of s, and to the Components given by WithComponents, are resolved at compile time,
+// so the unresolvable reference is reported by CompileSchema.
+//
+// The regular expressions are compiled by the RE2 syntax of the regexp package instead of ECMA-262. The patterns which
+// RE2 cannot compile are not asserted, and are listed by UnsupportedPatterns.
+func CompileSchema(s *JSONSchema, opts ...SchemaOption) (*SchemaValidator, error) {
+	o := &schemaOptions{formats: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var root *jsonschema.Schema
+	if s != nil {
+		root = s.Schema
+	}
+
+	var copts []jsonschema.CompileOption
+	if o.formats {
+		copts = append(copts, o.custom...)
+		copts = append(copts, jsonschema.WithFormatAssertion())
+	}
+	if o.components != nil {
+		copts = append(copts, jsonschema.WithRefResolver(componentSchemaResolver(o.components)))
+	}
+
+	v, err := jsonschema.Compile(root, copts...)
+	if err != nil {
+		return nil, fmt.Errorf("openrpc: compile schema: %w", err)
+	}
+
+	return &SchemaValidator{v: v}, nil
+}
+
+// componentSchemaResolver returns the jsonschema.RefResolver which resolves the "#/components/schemas/<name>" references.
+func componentSchemaResolver(c *Components) jsonschema.RefResolver {
+	const prefix = "/components/schemas/"
+
+	return func(ref string) (*jsonschema.Schema, string, error) {
+		i := strings.IndexByte(ref, '#')
+		if i < 0 {
+			return nil, "", nil
+		}
+		base := ref[:i]
+		fragment, err := url.PathUnescape(ref[i+1:])
+		if err != nil || !strings.HasPrefix(fragment, prefix) {
+			return nil, "", nil
+		}
+
+		name, rest := fragment[len(prefix):], ""
+		if j := strings.IndexByte(name, '/'); j >= 0 {
+			name, rest = name[:j], name[j:]
+		}
+		name = unescapePointerToken(name)
+
+		js, ok := c.Schemas[name]
+		if !ok || js == nil {
+			return nil, "", fmt.Errorf("unknown component schema %q", name)
+		}
+		s := js.Schema
+		if s == nil {
+			s = new(jsonschema.Schema)
+		}
+		return jsonschema.Lookup(s, base, rest)
+	}
+}
+
+// UnsupportedPatterns returns the regular expressions of the "pattern" and the "patternProperties" keywords which
+// the RE2 syntax cannot compile, such as the ECMA-262 lookaround and backreference. They are not asserted by Validate.
+func (v *SchemaValidator) UnsupportedPatterns() []string {
+	return v.v.UnsupportedPatterns()
+}
+
+// Validate validates the value, and returns every violation as ValidationErrors, or nil if the value is valid.
+//
+// The value is either the value decoded by encoding/json, which is nil, bool, float64, json.Number, string,
+// []interface{} or map[string]interface{}, or any other Go value which is validated as its JSON encoding.
+// The json.RawMessage is validated as the JSON text.
+func (v *SchemaValidator) Validate(value interface{}) error {
+	switch value := value.(type) {
+	case nil, bool, float64, json.Number, string, []interface{}, map[string]interface{}:
+		// the decoded JSON value
+	case json.RawMessage:
+		return v.ValidateJSON(value)
+	default:
+		instance, err := toInstance(value)
+		if err != nil {
+			return fmt.Errorf("openrpc: marshal value: %w", err)
+		}
+		return v.validate(instance)
+	}
+
+	return v.validate(value)
+}
+
+// ValidateJSON validates the JSON text data, and returns every violation as ValidationErrors, or nil if the data is valid.
+func (v *SchemaValidator) ValidateJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var instance interface{}
+	if err := dec.Decode(&instance); err != nil {
+		return fmt.Errorf("openrpc: decode value: %w", err)
+	}
+	if dec.More() {
+		return fmt.Errorf("openrpc: decode value: invalid data after top-level value")
+	}
+
+	return v.validate(instance)
+}
+
+func (v *SchemaValidator) validate(instance interface{}) error {
+	errs := v.v.Validate(instance)
+	if len(errs) == 0 {
+		return nil
+	}
+	return toValidationErrors(errs)
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mustJSONSchema returns the JSONSchema of the JSON text data.
+func mustJSONSchema(t *testing.T, data string) *JSONSchema {
+	t.Helper()
+
+	s := new(JSONSchema)
+	if err := json.Unmarshal([]byte(data), s); err != nil {
+		t.Fatalf("unmarshal schema %s: %v", data, err)
+	}
+	return s
+}
+
+func TestSchemaValidator(t *testing.T) {
+	type violation struct {
+		pointer, rule string
+	}
+	tests := map[string]struct {
+		schema string
+		opts   []SchemaOption
+		value  interface{}
+		want   []violation
+	}{
+		"decoded":         {schema: `{"type": "object", "required": ["a"]}`, value: map[string]interface{}{}, want: []violation{{"", "required"}}},
+		"rawMessage":      {schema: `{"items": {"type": "integer"}}`, value: json.RawMessage(`[1, 1.5]`), want: []violation{{"/1", "type"}}},
+		"goValue":         {schema: `{"properties": {"name": {"minLength": 2}}}`, value: &Tag{Name: "a"}, want: []violation{{"/name", "minLength"}}},
+		"nilSchema":       {schema: `null`, value: 1},
+		"format":          {schema: `{"format": "ipv4"}`, value: "x", want: []violation{{"", "format"}}},
+		"formatDisabled":  {schema: `{"format": "ipv4"}`, opts: []SchemaOption{WithFormatAssertion(false)}, value: "x"},
+		"formatChecker":   {schema: `{"format": "upper"}`, opts: []SchemaOption{WithFormatChecker("upper", isUpper)}, value: "x", want: []violation{{"", "format"}}},
+		"formatOverride":  {schema: `{"format": "ipv4"}`, opts: []SchemaOption{WithFormatChecker("ipv4", isUpper)}, value: "X"},
+		"checkerDisabled": {schema: `{"format": "upper"}`, opts: []SchemaOption{WithFormatChecker("upper", isUpper), WithFormatAssertion(false)}, value: "x"},
+		"ecmaPattern":     {schema: `{"pattern": "^(?!x)"}`, value: "x"},
+		"component": {
+			schema: `{"$ref": "#/components/schemas/a"}`,
+			opts:   []SchemaOption{WithComponents(&Components{Schemas: map[string]*JSONSchema{"a": mustJSONSchema(t, `{"type": "string"}`)}})},
+			value:  1,
+			want:   []violation{{"", "type"}},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			v, err := CompileSchema(mustJSONSchema(t, tt.schema), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = v.Validate(tt.value)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate(%v) = %v", tt.value, err)
+				}
+				return
+			}
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("Validate(%v) = %v, want ValidationErrors", tt.value, err)
+			}
+			got := make([]violation, len(verrs))
+			for i, verr := range verrs {
+				got[i] = violation{verr.Pointer, verr.Rule}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Validate(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func isUpper(s string) bool {
+	return s == strings.ToUpper(s)
+}
+
+func TestCompileSchemaError(t *testing.T) {
+	tests := map[string]struct {
+		schema string
+		opts   []SchemaOption
+	}{
+		"unknownDefinition": {schema: `{"$ref": "#/definitions/none"}`},
+		"noComponents":      {schema: `{"$ref": "#/components/schemas/a"}`},
+		"unknownComponent":  {schema: `{"$ref": "#/components/schemas/none"}`, opts: []SchemaOption{WithComponents(&Components{})}},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if _, err := CompileSchema(mustJSONSchema(t, tt.schema), tt.opts...); err == nil {
+				t.Fatalf("CompileSchema(%s) succeeded", tt.schema)
+			}
+		})
+	}
+}
+
+func TestSchemaValidatorUnsupportedPatterns(t *testing.T) {
+	v, err := CompileSchema(mustJSONSchema(t, `{"properties": {"a": {"pattern": "^[a-z]+$"}, "b": {"pattern": "(?<!x)y"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := v.UnsupportedPatterns(), []string{"(?<!x)y"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("UnsupportedPatterns() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateJSONError(t *testing.T) {
+	v, err := CompileSchema(mustJSONSchema(t, `{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.ValidateJSON([]byte(`{`)); err == nil || errors.As(err, new(ValidationErrors)) {
+		t.Fatalf("ValidateJSON = %v, want the decode error", err)
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RuleOpenRPCVersion is the rule that the Schema.OpenRPC MUST be the semantic version number of the supported major version.
+const RuleOpenRPCVersion = "openrpc-version"
+
+// LatestVersion is the latest OpenRPC Specification version which the package supports.
+const LatestVersion = "1.3.2"
+
+// supportedVersions lists the "major.minor" OpenRPC Specification versions which the package supports, in ascending order.
+var supportedVersions = []Version{
+	{Major: 1, Minor: 0},
+	{Major: 1, Minor: 2},
+	{Major: 1, Minor: 3},
+}
+
+// Version is the semantic version number of the OpenRPC Specification.
+//
+// See https://semver.org/spec/v2.0.0.html.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+
+	// Prerelease is the dot-separated pre-release identifiers following the hyphen, such as "rc.1", or empty.
+	Prerelease string
+
+	// Build is the dot-separated build metadata following the plus sign, or empty.
+	Build string
+}
+
+// ParseVersion parses the semantic version number s, such as "1.2.6".
+func ParseVersion(s string) (Version, error) {
+	var v Version
+
+	rest := s
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		rest, v.Build = rest[:i], rest[i+1:]
+		if !validIdentifiers(v.Build, false) {
+			return Version{}, fmt.Errorf("openrpc: invalid version %q: invalid build metadata", s)
+		}
+	}
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		rest, v.Prerelease = rest[:i], rest[i+1:]
+		if !validIdentifiers(v.Prerelease, true) {
+			return Version{}, fmt.Errorf("openrpc: invalid version %q: invalid pre-release", s)
+		}
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("openrpc: invalid version %q: must be the form of MAJOR.MINOR.PATCH", s)
+	}
+	nums := [3]*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		if !isNumericIdentifier(part) {
+			return Version{}, fmt.Errorf("openrpc: invalid version %q: invalid number %q", s, part)
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return Version{}, fmt.Errorf("openrpc: invalid version %q: %w", s, err)
+		}
+		*nums[i] = n
+	}
+
+	return v, nil
+}
+
+// validIdentifiers reports whether s is the non-empty dot-separated identifiers of [0-9A-Za-z-].
+// The numeric identifiers of the pre-release MUST NOT include the leading zeros.
+func validIdentifiers(s string, prerelease bool) bool {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+		numeric := true
+		for i := 0; i < len(id); i++ {
+			c := id[i]
+			switch {
+			case '0' <= c && c <= '9':
+			case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return false
+			}
+		}
+		if prerelease && numeric && !isNumericIdentifier(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumericIdentifier reports whether s is the decimal number without the leading zeros.
+func isNumericIdentifier(s string) bool {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || '9' < s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the semantic version number of v.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or +1 depending on whether v precedes, equals or follows w in the semantic version precedence.
+// The build metadata is ignored.
+func (v Version) Compare(w Version) int {
+	if c := compareInt(v.Major, w.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, w.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, w.Patch); c != 0 {
+		return c
+	}
+
+	switch {
+	case v.Prerelease == w.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case w.Prerelease == "":
+		return -1
+	}
+
+	vs, ws := strings.Split(v.Prerelease, "."), strings.Split(w.Prerelease, ".")
+	for i := 0; i < len(vs) && i < len(ws); i++ {
+		if c := compareIdentifier(vs[i], ws[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(vs), len(ws))
+}
+
+// compareIdentifier compares the pre-release identifiers. The numeric identifiers have lower precedence than the alphanumeric ones.
+func compareIdentifier(a, b string) int {
+	an, bn := isNumericIdentifier(a), isNumericIdentifier(b)
+	switch {
+	case an && bn:
+		if c := compareInt(len(a), len(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case an:
+		return -1
+	case bn:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsSupported reports whether the package supports the "major.minor" specification version of v.
+func (v Version) IsSupported() bool {
+	for _, sv := range supportedVersions {
+		if sv.Major == v.Major && sv.Minor == v.Minor {
+			return true
+		}
+	}
+	return false
+}
+
+// IsKnownMajor reports whether the package supports any specification version of the major version of v.
+func (v Version) IsKnownMajor() bool {
+	for _, sv := range supportedVersions {
+		if sv.Major == v.Major {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportedVersions returns the "major.minor" specification versions which the package supports, in ascending order.
+func SupportedVersions() []string {
+	versions := make([]string, len(supportedVersions))
+	for i, v := range supportedVersions {
+		versions[i] = fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	}
+	return versions
+}
+
+// nearestSupported returns the "major.minor" supported version whose rules apply to v, which is the newest supported
+// version not newer than v, or the oldest supported version of the major version. It reports false if the major version is unknown.
+func nearestSupported(v Version) (Version, bool) {
+	var nearest Version
+	found := false
+	for _, sv := range supportedVersions {
+		if sv.Major != v.Major {
+			continue
+		}
+		if !found || sv.Minor <= v.Minor {
+			nearest, found = sv, true
+		}
+	}
+	return nearest, found
+}
+
+// Version parses the specification version of the document in s.OpenRPC.
+func (s *Schema) Version() (Version, error) {
+	return ParseVersion(s.OpenRPC)
+}
+
+// SetVersion sets s.OpenRPC to the specification version, such as LatestVersion, after checking that the document
+// uses no fields which the version does not define.
+//
+// SetVersion does not migrate any field. The supported revisions of the major version only add the fields and relax
+// the constraints, so the document is valid in the newer revision as is. The document which uses the fields of the
+// newer revision cannot be set to the older one: Method.ParamStructure is not defined by 1.0, and Method.Result is
+// required before 1.3. Such fields are reported as ValidationErrors with RuleOpenRPCVersion, and s is left unchanged.
+//
+// SetVersion returns the error if either the version or s.OpenRPC is not the valid version, the version is not supported,
+// or their major versions differ.
+func (s *Schema) SetVersion(version string) error {
+	to, err := ParseVersion(version)
+	if err != nil {
+		return err
+	}
+	if !to.IsSupported() {
+		return fmt.Errorf("openrpc: unsupported version %s", to)
+	}
+	from, err := s.Version()
+	if err != nil {
+		return err
+	}
+	if from.Major != to.Major {
+		return fmt.Errorf("openrpc: cannot change the major version %d to %d", from.Major, to.Major)
+	}
+
+	c := &checker{schema: s}
+	for i, m := range s.Methods {
+		if m == nil {
+			continue
+		}
+		path := []string{"methods", strconv.Itoa(i)}
+		if m.ParamStructure != 0 && to.Minor < 2 {
+			c.report(RuleOpenRPCVersion, appendPath(path, "paramStructure"), "paramStructure of the method %q is not defined by the version %s", m.Name, to)
+		}
+		if m.Result == nil && to.Minor < 3 {
+			c.report(RuleOpenRPCVersion, path, "method %q must have the result in the version %s", m.Name, to)
+		}
+	}
+	if len(c.errs) > 0 {
+		return c.errs
+	}
+
+	s.OpenRPC = to.String()
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]struct {
+		want    Version
+		wantErr bool
+	}{
+		"1.2.6":                    {want: Version{Major: 1, Minor: 2, Patch: 6}},
+		"0.0.0":                    {want: Version{}},
+		"1.3.0-rc.1":               {want: Version{Major: 1, Minor: 3, Prerelease: "rc.1"}},
+		"1.3.0-rc.1+build.5":       {want: Version{Major: 1, Minor: 3, Prerelease: "rc.1", Build: "build.5"}},
+		"1.0.0+0001":               {want: Version{Major: 1, Build: "0001"}},
+		"1.0.0-x-y.0a":             {want: Version{Major: 1, Prerelease: "x-y.0a"}},
+		"1.2":                      {wantErr: true},
+		"1.2.3.4":                  {wantErr: true},
+		"01.2.3":                   {wantErr: true},
+		"1.2.x":                    {wantErr: true},
+		"1.2.-3":                   {wantErr: true},
+		"1.2.3-":                   {wantErr: true},
+		"1.2.3-01":                 {wantErr: true},
+		"1.2.3-a..b":               {wantErr: true},
+		"1.2.3+":                   {wantErr: true},
+		"1.2.3+a_b":                {wantErr: true},
+		"":                         {wantErr: true},
+		"99999999999999999999.0.0": {wantErr: true},
+	}
+	for s, tt := range tests {
+		got, err := ParseVersion(s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVersion(%q) error = %v, wantErr %t", s, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", s, got, tt.want)
+		}
+		if err == nil && got.String() != s {
+			t.Errorf("ParseVersion(%q).String() = %q", s, got.String())
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// in ascending order of the precedence, as per the example of the semantic versioning specification
+	versions := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.2.0",
+		"1.10.0",
+		"2.0.0",
+	}
+	for i, a := range versions {
+		for j, b := range versions {
+			va, _ := ParseVersion(a)
+			vb, _ := ParseVersion(b)
+			want := compareInt(i, j)
+			if got := va.Compare(vb); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+
+	va, _ := ParseVersion("1.0.0+a")
+	vb, _ := ParseVersion("1.0.0+b")
+	if va.Compare(vb) != 0 {
+		t.Error("Compare does not ignore the build metadata")
+	}
+}
+
+func TestVersionSupport(t *testing.T) {
+	tests := map[string]struct {
+		supported, knownMajor bool
+		nearest               string
+	}{
+		"1.0.0": {supported: true, knownMajor: true, nearest: "1.0.0"},
+		"1.1.0": {knownMajor: true, nearest: "1.0.0"},
+		"1.2.6": {supported: true, knownMajor: true, nearest: "1.2.0"},
+		"1.3.2": {supported: true, knownMajor: true, nearest: "1.3.0"},
+		"1.9.0": {knownMajor: true, nearest: "1.3.0"},
+		"2.0.0": {},
+	}
+	for s, tt := range tests {
+		v, err := ParseVersion(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.IsSupported(); got != tt.supported {
+			t.Errorf("%s.IsSupported() = %t", s, got)
+		}
+		if got := v.IsKnownMajor(); got != tt.knownMajor {
+			t.Errorf("%s.IsKnownMajor() = %t", s, got)
+		}
+		nearest, ok := nearestSupported(v)
+		if ok != (tt.nearest != "") || ok && nearest.String() != tt.nearest {
+			t.Errorf("nearestSupported(%s) = %s, %t, want %q", s, nearest, ok, tt.nearest)
+		}
+	}
+
+	if got, want := SupportedVersions(), []string{"1.0", "1.2", "1.3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SupportedVersions() = %q, want %q", got, want)
+	}
+	if v, err := ParseVersion(LatestVersion); err != nil || !v.IsSupported() {
+		t.Errorf("LatestVersion %s is not supported: %v", LatestVersion, err)
+	}
+}
+
+func TestSetVersion(t *testing.T) {
+	tests := map[string]struct {
+		from, to string
+		methods  string
+		want     []string // the pointers of the violations
+		wantErr  bool
+	}{
+		"upgrade":         {from: "1.0.0", to: LatestVersion, methods: `[{"name": "a", "params": [], "result": {"name": "r", "schema": {}}}]`},
+		"upgradeFeatures": {from: "1.2.6", to: "1.3.0", methods: `[{"name": "a", "params": [], "result": {"name": "r", "schema": {}}, "paramStructure": "by-name"}]`},
+		"downgrade":       {from: "1.3.2", to: "1.2.6", methods: `[{"name": "a", "params": [], "result": {"name": "r", "schema": {}}, "paramStructure": "by-name"}]`},
+		"downgradeTo1.0":  {from: "1.3.2", to: "1.0.0", methods: `[{"name": "a", "params": [], "paramStructure": "by-name"}, {"name": "b", "params": [], "result": {"name": "r", "schema": {}}}]`, want: []string{"/methods/0/paramStructure", "/methods/0"}},
+		"notification":    {from: "1.3.2", to: "1.2.6", methods: `[{"name": "a", "params": []}]`, want: []string{"/methods/0"}},
+		"invalidTarget":   {from: "1.2.6", to: "1.2", methods: `[]`, wantErr: true},
+		"unsupported":     {from: "1.2.6", to: "1.1.0", methods: `[]`, wantErr: true},
+		"invalidDocument": {from: "1.2", to: "1.2.6", methods: `[]`, wantErr: true},
+		"otherMajor":      {from: "0.9.0", to: "1.2.6", methods: `[]`, wantErr: true},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			s := new(Schema)
+			doc := `{"openrpc": "` + tt.from + `", "info": {"title": "t", "version": "1"}, "methods": ` + tt.methods + `}`
+			if err := json.Unmarshal([]byte(doc), s); err != nil {
+				t.Fatal(err)
+			}
+
+			err := s.SetVersion(tt.to)
+			switch {
+			case tt.wantErr:
+				if err == nil || errors.As(err, new(ValidationErrors)) {
+					t.Fatalf("SetVersion(%q) = %v, want the error", tt.to, err)
+				}
+			case len(tt.want) > 0:
+				var verrs ValidationErrors
+				if !errors.As(err, &verrs) {
+					t.Fatalf("SetVersion(%q) = %v, want ValidationErrors", tt.to, err)
+				}
+				got := make([]string, len(verrs))
+				for i, verr := range verrs {
+					got[i] = verr.Pointer
+					if verr.Rule != RuleOpenRPCVersion {
+						t.Errorf("Rule = %q, want %q", verr.Rule, RuleOpenRPCVersion)
+					}
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("SetVersion(%q) = %q, want %q", tt.to, got, tt.want)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("SetVersion(%q) = %v", tt.to, err)
+				}
+				if s.OpenRPC != tt.to {
+					t.Fatalf("OpenRPC = %q, want %q", s.OpenRPC, tt.to)
+				}
+				return
+			}
+			if s.OpenRPC != tt.from {
+				t.Fatalf("OpenRPC = %q, want the unchanged %q", s.OpenRPC, tt.from)
+			}
+		})
+	}
+}
@@ -0,0 +1,454 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYAML implements yaml.Marshaler.
+//
+// The YAML document has the same field names, specification extensions and references as the JSON document.
+// The nested objects such as Method and ContentDescriptor are also encoded to YAML in the same way.
+func (s Schema) MarshalYAML() (interface{}, error) { return marshalYAML(s) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *Schema) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, s) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (i Info) MarshalYAML() (interface{}, error) { return marshalYAML(i) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (i *Info) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, i) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (c Contact) MarshalYAML() (interface{}, error) { return marshalYAML(c) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *Contact) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, c) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (l License) MarshalYAML() (interface{}, error) { return marshalYAML(l) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *License) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, l) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (s Server) MarshalYAML() (interface{}, error) { return marshalYAML(s) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *Server) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, s) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (s ServerVariables) MarshalYAML() (interface{}, error) { return marshalYAML(s) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *ServerVariables) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, s) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (m Method) MarshalYAML() (interface{}, error) { return marshalYAML(m) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (m *Method) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, m) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (c ContentDescriptor) MarshalYAML() (interface{}, error) { return marshalYAML(c) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *ContentDescriptor) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, c) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (j JSONSchema) MarshalYAML() (interface{}, error) { return marshalYAML(j) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (j *JSONSchema) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, j) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (e ExamplePairing) MarshalYAML() (interface{}, error) { return marshalYAML(e) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *ExamplePairing) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, e) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (e Example) MarshalYAML() (interface{}, error) { return marshalYAML(e) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *Example) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, e) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (l Link) MarshalYAML() (interface{}, error) { return marshalYAML(l) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *Link) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, l) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (p LinkParam) MarshalYAML() (interface{}, error) { return marshalYAML(p) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *LinkParam) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, p) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (e Error) MarshalYAML() (interface{}, error) { return marshalYAML(e) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *Error) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, e) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (c Components) MarshalYAML() (interface{}, error) { return marshalYAML(c) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *Components) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, c) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (t Tag) MarshalYAML() (interface{}, error) { return marshalYAML(t) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *Tag) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, t) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (e ExternalDocumentation) MarshalYAML() (interface{}, error) { return marshalYAML(e) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *ExternalDocumentation) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, e) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (r Reference) MarshalYAML() (interface{}, error) { return marshalYAML(r) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (r *Reference) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, r) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (o OneOf) MarshalYAML() (interface{}, error) { return marshalYAML(o) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (o *OneOf) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, o) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (c ContentDescriptorOrReference) MarshalYAML() (interface{}, error) { return marshalYAML(c) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *ContentDescriptorOrReference) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, c)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (e ErrorOrReference) MarshalYAML() (interface{}, error) { return marshalYAML(e) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *ErrorOrReference) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, e) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (t TagOrReference) MarshalYAML() (interface{}, error) { return marshalYAML(t) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *TagOrReference) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, t) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (l LinkOrReference) MarshalYAML() (interface{}, error) { return marshalYAML(l) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *LinkOrReference) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, l) }
+
+// MarshalYAML implements yaml.Marshaler.
+func (e ExamplePairingOrReference) MarshalYAML() (interface{}, error) { return marshalYAML(e) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *ExamplePairingOrReference) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, e)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (e ExampleOrReference) MarshalYAML() (interface{}, error) { return marshalYAML(e) }
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *ExampleOrReference) UnmarshalYAML(value *yaml.Node) error { return unmarshalYAML(value, e) }
+
+// marshalYAML returns the YAML node of the JSON encoding of v, so v is encoded by its JSON codec.
+func marshalYAML(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := parseNode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeToYAML(n), nil
+}
+
+// unmarshalYAML decodes the YAML node value into the pointer v with the same decoder as the JSON document.
+func unmarshalYAML(value *yaml.Node, v interface{}) error {
+	n, err := yamlToNode(value)
+	if err != nil {
+		return err
+	}
+
+	d := new(decoder)
+	return d.decode(n, reflect.ValueOf(v))
+}
+
+// parseYAML parses the YAML text data into the node tree and the YAML document node.
+func parseYAML(data []byte) (*node, *yaml.Node, error) {
+	doc := new(yaml.Node)
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, nil, &DecodeError{Offset: -1, Err: err}
+	}
+	if doc.Kind == 0 {
+		return nil, nil, &DecodeError{Offset: -1, Err: errors.New("empty YAML document")}
+	}
+
+	n, err := yamlToNode(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return n, doc, nil
+}
+
+// yamlToNode converts the YAML node into the node tree.
+//
+// The aliases are resolved to the anchored nodes and the merge keys are expanded, so the node tree is equivalent to the JSON value.
+func yamlToNode(y *yaml.Node) (*node, error) {
+	c := &yamlConverter{}
+	return c.convert(y)
+}
+
+// maxYAMLDepth is the maximum nesting depth of the YAML document, which is the same as encoding/json.
+const maxYAMLDepth = 10000
+
+// yamlConverter converts the YAML node into the node tree.
+type yamlConverter struct {
+	depth int
+}
+
+func (c *yamlConverter) errorf(y *yaml.Node, format string, args ...interface{}) error {
+	return &DecodeError{
+		Line:   y.Line,
+		Column: y.Column,
+		Offset: -1,
+		Err:    fmt.Errorf(format, args...),
+	}
+}
+
+func (c *yamlConverter) convert(y *yaml.Node) (*node, error) {
+	c.depth++
+	defer func() { c.depth-- }()
+	if c.depth > maxYAMLDepth {
+		return nil, c.errorf(y, "exceeded max depth")
+	}
+
+	switch y.Kind {
+	case yaml.DocumentNode:
+		if len(y.Content) == 0 {
+			return &node{kind: nullNode, offset: -1, line: y.Line, column: y.Column}, nil
+		}
+		return c.convert(y.Content[0])
+
+	case yaml.AliasNode:
+		return c.convert(y.Alias)
+
+	case yaml.ScalarNode:
+		return c.convertScalar(y)
+
+	case yaml.SequenceNode:
+		n := &node{kind: arrayNode, offset: -1, line: y.Line, column: y.Column}
+		for _, item := range y.Content {
+			child, err := c.convert(item)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+		}
+		return n, nil
+
+	case yaml.MappingNode:
+		n := &node{kind: objectNode, offset: -1, line: y.Line, column: y.Column}
+		if err := c.convertMapping(n, y); err != nil {
+			return nil, err
+		}
+		return n, nil
+
+	default:
+		return nil, c.errorf(y, "unsupported YAML node kind %d", y.Kind)
+	}
+}
+
+// convertMapping adds the members of the YAML mapping y into the object n.
+//
+// The explicit members take precedence over the members merged by the "<<" merge key.
+func (c *yamlConverter) convertMapping(n *node, y *yaml.Node) error {
+	var merges []*yaml.Node
+	for i := 0; i+1 < len(y.Content); i += 2 {
+		key, value := y.Content[i], y.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			merges = append(merges, value)
+			continue
+		}
+		if key.Kind == yaml.AliasNode {
+			key = key.Alias
+		}
+		if key.Kind != yaml.ScalarNode {
+			return c.errorf(key, "mapping key must be a scalar")
+		}
+		if n.member(key.Value) != nil {
+			return c.errorf(key, "duplicate mapping key %q", key.Value)
+		}
+
+		child, err := c.convert(value)
+		if err != nil {
+			return err
+		}
+		n.keys = append(n.keys, key.Value)
+		n.children = append(n.children, child)
+	}
+
+	for _, merge := range merges {
+		if merge.Kind == yaml.AliasNode {
+			merge = merge.Alias
+		}
+
+		sources := []*yaml.Node{merge}
+		if merge.Kind == yaml.SequenceNode {
+			sources = sources[:0]
+			for _, item := range merge.Content {
+				if item.Kind == yaml.AliasNode {
+					item = item.Alias
+				}
+				sources = append(sources, item)
+			}
+		}
+
+		for _, src := range sources {
+			if src.Kind != yaml.MappingNode {
+				return c.errorf(src, "merge value must be a mapping")
+			}
+			m := &node{kind: objectNode}
+			if err := c.convertMapping(m, src); err != nil {
+				return err
+			}
+			for i, key := range m.keys {
+				if n.member(key) == nil {
+					n.keys = append(n.keys, key)
+					n.children = append(n.children, m.children[i])
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *yamlConverter) convertScalar(y *yaml.Node) (*node, error) {
+	n := &node{offset: -1, line: y.Line, column: y.Column}
+
+	switch tag := y.ShortTag(); tag {
+	case "!!null":
+		n.kind = nullNode
+
+	case "!!bool":
+		var b bool
+		if err := y.Decode(&b); err != nil {
+			return nil, c.errorf(y, "invalid boolean %q", y.Value)
+		}
+		n.kind = boolNode
+		n.value = strconv.FormatBool(b)
+
+	case "!!int", "!!float":
+		n.kind = numberNode
+		if isJSONNumber(y.Value) {
+			n.value = y.Value
+			break
+		}
+
+		var v interface{}
+		if err := y.Decode(&v); err != nil {
+			return nil, c.errorf(y, "invalid number %q", y.Value)
+		}
+		switch v := v.(type) {
+		case int:
+			n.value = strconv.FormatInt(int64(v), 10)
+		case int64:
+			n.value = strconv.FormatInt(v, 10)
+		case uint64:
+			n.value = strconv.FormatUint(v, 10)
+		case float64:
+			if math.IsInf(v, 0) || math.IsNaN(v) {
+				return nil, c.errorf(y, "number %q cannot be represented in JSON", y.Value)
+			}
+			n.value = strconv.FormatFloat(v, 'g', -1, 64)
+		default:
+			return nil, c.errorf(y, "invalid number %q", y.Value)
+		}
+
+	case "!!str", "!!timestamp", "!!binary":
+		n.kind = stringNode
+		n.value = y.Value
+
+	default:
+		return nil, c.errorf(y, "unsupported YAML tag %s", tag)
+	}
+
+	return n, nil
+}
+
+// isJSONNumber reports whether the s is the JSON number literal.
+func isJSONNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	return (s[0] == '-' || ('0' <= s[0] && s[0] <= '9')) && json.Valid([]byte(s))
+}
+
+// nodeToYAML converts the node tree into the YAML node.
+func nodeToYAML(n *node) *yaml.Node {
+	switch n.kind {
+	case nullNode:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+
+	case boolNode:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: n.value}
+
+	case numberNode:
+		tag := "!!int"
+		if strings.ContainsAny(n.value, ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: n.value}
+
+	case stringNode:
+		y := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: n.value}
+		if strings.Contains(n.value, "\n") {
+			y.Style = yaml.LiteralStyle
+		}
+		return y
+
+	case arrayNode:
+		y := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, child := range n.children {
+			y.Content = append(y.Content, nodeToYAML(child))
+		}
+		return y
+
+	default:
+		y := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for i, child := range n.children {
+			y.Content = append(y.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: n.keys[i]},
+				nodeToYAML(child),
+			)
+		}
+		return y
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2019 The go-openrpc Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const yamlTestDocument = `# the comment is ignored
+openrpc: 1.2.6
+info:
+  title: t
+  version: "1"
+  x-info: &info
+    owner: team
+    count: 3
+methods:
+  - name: a
+    params:
+      - $ref: '#/components/contentDescriptors/p'
+      - name: q
+        schema:
+          type: number
+          maximum: 1.5
+          minimum: -10
+          default: 1e3
+    result:
+      name: r
+      schema: true
+    x-copy: *info
+components:
+  contentDescriptors:
+    p: &p
+      name: p
+      schema:
+        type: string
+        description: |
+          multi
+          line
+    q:
+      <<: *p
+      name: q
+`
+
+// yamlTestJSON is the JSON document equivalent to yamlTestDocument.
+const yamlTestJSON = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "t", "version": "1", "x-info": {"owner": "team", "count": 3}},
+	"methods": [{
+		"name": "a",
+		"params": [
+			{"$ref": "#/components/contentDescriptors/p"},
+			{"name": "q", "schema": {"type": "number", "maximum": 1.5, "minimum": -10, "default": 1e3}}
+		],
+		"result": {"name": "r", "schema": true},
+		"x-copy": {"owner": "team", "count": 3}
+	}],
+	"components": {
+		"contentDescriptors": {
+			"p": {"name": "p", "schema": {"type": "string", "description": "multi\nline\n"}},
+			"q": {"name": "q", "schema": {"type": "string", "description": "multi\nline\n"}}
+		}
+	}
+}`
+
+// mustMarshalJSON returns the JSON encoding of v.
+func mustMarshalJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseYAML(t *testing.T) {
+	got, err := Parse([]byte(yamlTestDocument), WithStrict(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Parse([]byte(yamlTestJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := mustMarshalJSON(t, got), mustMarshalJSON(t, want); g != w {
+		t.Fatalf("the YAML document is decoded as\n%s\nwant\n%s", g, w)
+	}
+	if !got.Methods[0].Params[0].IsReference() {
+		t.Fatal("the $ref is not decoded as the reference")
+	}
+
+	// yaml.Unmarshal uses the same decoder
+	s := new(Schema)
+	if err := yaml.Unmarshal([]byte(yamlTestDocument), s); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := mustMarshalJSON(t, s), mustMarshalJSON(t, want); g != w {
+		t.Fatalf("yaml.Unmarshal decoded\n%s\nwant\n%s", g, w)
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	s, err := Parse([]byte(yamlTestJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"openrpc: 1.2.6\n",
+		`version: "1"`,
+		"x-info:\n",
+		"- $ref: '#/components/contentDescriptors/p'\n",
+		"maximum: 1.5\n",
+		"minimum: -10\n",
+		"schema: true\n",
+		"description: |\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("the YAML encoding does not contain %q:\n%s", want, data)
+		}
+	}
+
+	again, err := Parse(data, WithFormat(FormatYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := mustMarshalJSON(t, again), mustMarshalJSON(t, s); g != w {
+		t.Fatalf("the YAML round trip changed the document:\n%s\nwant\n%s", g, w)
+	}
+}
+
+func TestYAMLScalars(t *testing.T) {
+	tests := map[string]struct {
+		yaml, json string
+	}{
+		"int":       {yaml: `a: 10`, json: `{"a":10}`},
+		"hex":       {yaml: `a: 0x1F`, json: `{"a":31}`},
+		"octal":     {yaml: `a: 0o17`, json: `{"a":15}`},
+		"float":     {yaml: `a: 1.50`, json: `{"a":1.50}`},
+		"exponent":  {yaml: `a: 1e-3`, json: `{"a":1e-3}`},
+		"bigInt":    {yaml: `a: 12345678901234567890`, json: `{"a":12345678901234567890}`},
+		"bool":      {yaml: `a: true`, json: `{"a":true}`},
+		"null":      {yaml: `a: ~`, json: `{"a":null}`},
+		"string":    {yaml: `a: "1"`, json: `{"a":"1"}`},
+		"timestamp": {yaml: `a: 2001-12-14`, json: `{"a":"2001-12-14"}`},
+		"sequence":  {yaml: `a: [1, b]`, json: `{"a":[1,"b"]}`},
+		"mergeList": {yaml: "x: &x {a: 1}\ny: &y {b: 2}\nz: {<<: [*x, *y], a: 3}", json: `{"x":{"a":1},"y":{"b":2},"z":{"a":3,"b":2}}`},
+	}
+	for name, tt := range tests {
+		n, _, err := parseYAML([]byte(tt.yaml))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got := string(n.appendJSON(nil)); got != tt.json {
+			t.Errorf("%s: %s is converted to %s, want %s", name, tt.yaml, got, tt.json)
+		}
+	}
+}
+
+func TestYAMLError(t *testing.T) {
+	tests := map[string]struct {
+		yaml         string
+		line, column int
+		msg          string
+	}{
+		"syntax":       {yaml: "a: [1,\n", msg: "yaml"},
+		"empty":        {yaml: "", msg: "empty YAML document"},
+		"duplicate":    {yaml: "a: 1\na: 2", line: 2, column: 1, msg: `duplicate mapping key "a"`},
+		"complexKey":   {yaml: "? [a]\n: 1", line: 1, column: 3, msg: "mapping key must be a scalar"},
+		"infinity":     {yaml: "a: .inf", line: 1, column: 4, msg: "cannot be represented in JSON"},
+		"mergeScalar":  {yaml: "a: {<<: 1}", line: 1, column: 9, msg: "merge value must be a mapping"},
+		"unknownTag":   {yaml: "a: !custom x", line: 1, column: 4, msg: "unsupported YAML tag !custom"},
+		"invalidValue": {yaml: "openrpc: [1]", line: 1, column: 10, msg: "/openrpc"},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml), WithFormat(FormatYAML))
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Fatalf("Parse = %v, want *DecodeError", err)
+			}
+			if derr.Line != tt.line || derr.Column != tt.column {
+				t.Fatalf("DecodeError at line %d column %d, want line %d column %d (%v)", derr.Line, derr.Column, tt.line, tt.column, err)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("Error() = %q does not contain %q", err, tt.msg)
+			}
+		})
+	}
+}